@@ -0,0 +1,93 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// maxAdvance NextIdAfter 最多把生成器推进到当前时间之后多久
+const maxAdvance = time.Minute
+
+var ErrTooFarAhead = errors.New("snowflake id is too far in the future")
+
+// NextIdAfter 生成严格大于prev的id，用于选主时的投票编号等需要递增的场景。
+// 正常生成的id已经大于prev时直接返回；否则把生成器的时间戳推进到prev所在的毫秒，
+// 并让序列越过prev。系统时间追上之前，NextId 等调用沿用推进后的时间戳，不视为时钟回退。
+// prev的生成时间不能晚于当前时间 maxAdvance 以上，否则返回 ErrTooFarAhead。
+func (s *Snowflake) NextIdAfter(prev int64) (int64, error) {
+	if s.shutdown.Load() {
+		return 0, ErrShutdown
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.timeGen()
+	id, err := s.nextId(now)
+	if err != nil || id > prev {
+		return id, err
+	}
+	timestamp := (prev>>s.timestampShift)*s.layout.unit() + s.epoch
+	if prev>>s.timestampShift >= s.timestampMax || timestamp-now > maxAdvance.Milliseconds() {
+		return 0, fmt.Errorf("%w: %d", ErrTooFarAhead, prev)
+	}
+	s.advancePast(prev)
+	return s.nextId(now)
+}
+
+// advancePast 调整时间戳和序列，使下一次生成的id大于prev，调用方需持有锁
+func (s *Snowflake) advancePast(prev int64) {
+	timestamp := (prev>>s.timestampShift)*s.layout.unit() + s.epoch
+	base := s.compose(timestamp, 0)
+	switch {
+	case base+s.maxSequence <= prev: // 这一毫秒内的序列都不够大，使用下一毫秒
+		s.lastTimestamp = timestamp + s.layout.unit()
+		s.sequence = -1
+	case base > prev:
+		s.lastTimestamp = timestamp
+		s.sequence = -1
+	default:
+		s.lastTimestamp = timestamp
+		s.sequence = prev - base
+	}
+	s.advancedUntil = s.lastTimestamp
+	s.borrowing = false
+}
+
+// maxLead 推进后的时间戳最多领先系统时间多少毫秒
+func (s *Snowflake) maxLead() int64 {
+	if s.borrowLead > 0 && s.borrowLead < maxAdvance.Milliseconds() {
+		return s.borrowLead
+	}
+	return maxAdvance.Milliseconds()
+}
+
+// advanced 系统时间还没有追上 NextIdAfter 推进到的时间戳时，返回推进后的时间戳，调用方需持有锁
+func (s *Snowflake) advanced(timestamp int64) int64 {
+	if timestamp < s.lastTimestamp && s.lastTimestamp <= s.advancedUntil {
+		return s.lastTimestamp
+	}
+	return timestamp
+}
+
+// nextMillis 当前毫秒的序列用尽时取得下一个时间戳。
+// 处于推进后的时间戳时直接推进一毫秒，不等待系统时间，但最多领先系统时间 maxAdvance（使用 WithBorrowFuture 时
+// 不超过其上限），达到后与其它情况一样等待；使用 WithBorrowFuture 时借用下一毫秒；否则阻塞到下一毫秒，
+// NextIdContext 的ctx结束时返回错误，下一个时间戳超出表示范围时返回 ErrTimestampOverflow。调用方需持有锁
+func (s *Snowflake) nextMillis() (int64, error) {
+	s.sequenceWaits++
+	s.recordExhaustion(s.lastTimestamp)
+	s.logDebug("sequence exhausted", slog.Int64("timestamp", s.lastTimestamp))
+	if err := s.checkOverflow(s.nextTick(s.lastTimestamp)); err != nil {
+		return 0, err
+	}
+	if s.lastTimestamp <= s.advancedUntil && !s.borrowing && s.advancedUntil-s.timeGen() < s.maxLead() {
+		s.advancedUntil = s.lastTimestamp + s.layout.unit()
+		return s.advancedUntil, nil
+	}
+	if s.borrowLead > 0 {
+		return s.borrow()
+	}
+	return s.tilNextMillis(s.lastTimestamp)
+}
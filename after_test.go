@@ -0,0 +1,181 @@
+package snowflake
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func composeAt(ts, dc, worker, seq int64) int64 {
+	return ts<<timestampLeftShift | dc<<datacenterIdShift | worker<<workerIdShift | seq
+}
+
+func TestNextIdAfter(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, err := NewUnregistered(3, 4, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prevs := []int64{
+		-1,
+		0,
+		composeAt(999, 31, 31, sequenceMask),  // 过去
+		composeAt(1000, 4, 3, 7),              // 同一毫秒、同一节点
+		composeAt(1000, 4, 3, 100),            // 序列跳跃
+		composeAt(1000, 5, 0, 0),              // 同一毫秒、节点更大
+		composeAt(1000, 2, 0, 0),              // 同一毫秒、节点更小
+		composeAt(5000, 4, 3, sequenceMask),   // 未来、序列用尽
+		composeAt(6000, 4, 3, 10),             // 未来
+		composeAt(7000, 31, 31, sequenceMask), // 未来、节点更大
+	}
+	for _, prev := range prevs {
+		id, err := sf.NextIdAfter(prev)
+		if err != nil {
+			t.Fatalf("NextIdAfter(%d): %v", prev, err)
+		}
+		if id <= prev {
+			t.Fatalf("NextIdAfter(%d) = %d, want greater", prev, id)
+		}
+		p := ID(id).Parse()
+		if p.WorkerId() != 3 || p.DatacenterId() != 4 {
+			t.Fatalf("NextIdAfter(%d) node = %d %d", prev, p.WorkerId(), p.DatacenterId())
+		}
+	}
+
+	// 紧跟在prev之后
+	prev := composeAt(8000, 4, 3, 10)
+	if id, _ := sf.NextIdAfter(prev); id != prev+1 {
+		t.Errorf("NextIdAfter(%d) = %d, want %d", prev, id, prev+1)
+	}
+
+	// 连续调用保持递增，推进后的毫秒序列用尽时不会等待系统时间
+	last := int64(0)
+	for i := 0; i < 5000; i++ {
+		id, err := sf.NextIdAfter(last)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id <= last {
+			t.Fatalf("NextIdAfter(%d) = %d", last, id)
+		}
+		last = id
+	}
+}
+
+func TestNextIdAfter_TooFarAhead(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, err := NewUnregistered(3, 4, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, prev := range []int64{
+		math.MaxInt64 - 5,
+		composeAt(maxTimestamp, 0, 0, 0),
+		composeAt(1000+time.Hour.Milliseconds(), 0, 0, 0),
+	} {
+		if id, err := sf.NextIdAfter(prev); !errors.Is(err, ErrTooFarAhead) {
+			t.Errorf("NextIdAfter(%d) = %d, %v, want ErrTooFarAhead", prev, id, err)
+		}
+	}
+	// 生成器不受影响
+	id, err := sf.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts := ID(id).Parse().Timestamp(); ts != twepoch+1000 {
+		t.Errorf("NextId timestamp = %d, want %d", ts, twepoch+1000)
+	}
+}
+
+func TestNextIdAfter_MixedWithNextId(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	var drift lockedBuffer
+	sf, err := NewUnregistered(3, 4, WithClock(clock),
+		WithCircuitBreaker(3, time.Minute), WithDriftMonitor(&drift, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+
+	prev := composeAt(1000+30000, 0, 0, 0) // 30秒之后
+	last, err := sf.NextIdAfter(prev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last <= prev {
+		t.Fatalf("NextIdAfter(%d) = %d", prev, last)
+	}
+
+	// 系统时间追上之前，NextId 沿用推进后的时间戳，不触发熔断和时钟回退事件
+	for i := 0; i < 10000; i++ {
+		var id int64
+		if i%3 == 0 {
+			id, err = sf.NextIdAfter(last)
+		} else {
+			id, err = sf.NextId()
+		}
+		if err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+		if id <= last {
+			t.Fatalf("call %d: id %d is not greater than %d", i, id, last)
+		}
+		last = id
+		if i%1000 == 0 {
+			clock.Add(time.Millisecond)
+		}
+	}
+
+	// 系统时间追上之后按系统时间生成
+	clock.Add(time.Minute)
+	id, err := sf.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id <= last || ID(id).Parse().Timestamp() != clock.Now().UnixMilli() {
+		t.Errorf("NextId = %d, want timestamp %d", id, clock.Now().UnixMilli())
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if drift.String() != "" {
+		t.Errorf("unexpected drift events: %s", drift.String())
+	}
+}
+
+// 推进后持续用尽序列，时间戳领先系统时间不超过 maxAdvance
+func TestNextIdAfter_AdvanceCapped(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, err := NewUnregistered(3, 4, WithClock(clock), WithMaxSequence(3),
+		WithSleeper(func(d time.Duration) {
+			if d < time.Millisecond {
+				d = time.Millisecond
+			}
+			clock.Add(d)
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sf.NextIdAfter(composeAt(1000, 4, 3, 3)); err != nil {
+		t.Fatal(err)
+	}
+
+	// 不限制时 4*maxAdvance 个id会领先系统时间 maxAdvance 以上
+	maxLead := int64(0)
+	for i := int64(0); i < 5*maxAdvance.Milliseconds(); i++ {
+		id, err := sf.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lead := ID(id).Time().UnixMilli() - clock.Now().UnixMilli(); lead > maxLead {
+			maxLead = lead
+		}
+	}
+	if maxLead > maxAdvance.Milliseconds() {
+		t.Errorf("timestamp got %dms ahead of the clock, want at most %v", maxLead, maxAdvance)
+	}
+	if maxLead < maxAdvance.Milliseconds()-1 {
+		t.Errorf("timestamp got only %dms ahead of the clock, want about %v", maxLead, maxAdvance)
+	}
+}
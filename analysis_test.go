@@ -0,0 +1,63 @@
+package snowflake
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// idAt 构造指定时间生成的id
+func idAt(epoch, t time.Time, seq int64) int64 {
+	return t.Sub(epoch).Milliseconds()<<timestampLeftShift | seq
+}
+
+func TestGroupByWindow(t *testing.T) {
+	epoch := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	hour := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	a := idAt(epoch, hour, 0)                                 // 窗口起点
+	b := idAt(epoch, hour.Add(time.Hour-time.Millisecond), 1) // 窗口终点前1毫秒
+	c := idAt(epoch, hour.Add(time.Hour), 2)                  // 下一个窗口起点
+	d := idAt(epoch, hour.Add(-time.Millisecond), 3)          // 上一个窗口
+	e := idAt(epoch, hour.Add(30*time.Minute), 4)
+
+	groups := GroupByWindow([]int64{c, a, e, d, b}, time.Hour, epoch)
+	want := map[time.Time][]int64{
+		hour.Add(-time.Hour): {d},
+		hour:                 {a, e, b},
+		hour.Add(time.Hour):  {c},
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("GroupByWindow = %v, want %v", groups, want)
+	}
+
+	if got := GroupByWindow(nil, time.Hour, epoch); len(got) != 0 {
+		t.Errorf("GroupByWindow(nil) = %v, want empty", got)
+	}
+}
+
+func TestDetectSequenceGaps(t *testing.T) {
+	epoch := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	t0 := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	t1 := t0.Add(time.Millisecond)
+	worker2 := int64(2) << workerIdShift
+
+	ids := []int64{
+		idAt(epoch, t0, 5), idAt(epoch, t0, 3), idAt(epoch, t0, 4), idAt(epoch, t0, 9), // 缺少6-8
+		idAt(epoch, t0, 9),                                         // 重复不算缺口
+		idAt(epoch, t0, 1) | worker2, idAt(epoch, t0, 3) | worker2, // 另一个节点缺少2
+		idAt(epoch, t1, 7), idAt(epoch, t1, 8), // 下一毫秒从7开始，不算缺口
+	}
+	gaps := DetectSequenceGaps(ids, epoch)
+	want := []SequenceGap{
+		{WorkerID: 0, DatacenterID: 0, TimestampMs: t0.UnixMilli(), ExpectedSeq: 6, ActualSeq: 9},
+		{WorkerID: 2, DatacenterID: 0, TimestampMs: t0.UnixMilli(), ExpectedSeq: 2, ActualSeq: 3},
+	}
+	if !reflect.DeepEqual(gaps, want) {
+		t.Errorf("gaps = %+v, want %+v", gaps, want)
+	}
+
+	if gaps := DetectSequenceGaps(nil, epoch); len(gaps) != 0 {
+		t.Errorf("gaps of nil = %v", gaps)
+	}
+}
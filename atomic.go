@@ -0,0 +1,66 @@
+package snowflake
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	atomicSequenceShift = 44                               // 状态中毫秒内序列左移位数，低44位存放时间戳
+	atomicTimestampMask = -1 ^ (-1 << atomicSequenceShift) // 状态中时间戳的掩码
+)
+
+// AtomicSnowflake 不使用互斥锁的生成器，适用于WASM等单核、线程支持有限的环境，
+// 也适用于多核机器上大量goroutine同时生成id、互斥锁成为瓶颈的场景。
+// 上一次的时间戳和毫秒内序列打包在一个int64中，即 (sequence << 44) | timestamp，
+// 通过CAS同时完成序列自增和毫秒切换。只支持默认的起始时间和系统时钟，
+// 也不登记到进程内的节点注册表，调用方需要自己保证节点不重复。
+type AtomicSnowflake struct {
+	state        atomic.Int64
+	workerId     int64
+	datacenterId int64
+}
+
+// NewAtomic 创建不使用互斥锁的生成器
+func NewAtomic(workerID, datacenterID int64) (*AtomicSnowflake, error) {
+	if workerID < 0 || workerID > maxWorkerId {
+		return nil, fmt.Errorf("%w %d: can't be greater than %d or less than 0", ErrInvalidWorkerID, workerID, maxWorkerId)
+	}
+	if datacenterID < 0 || datacenterID > maxDatacenterId {
+		return nil, fmt.Errorf("%w %d: can't be greater than %d or less than 0", ErrInvalidDatacenterID, datacenterID, maxDatacenterId)
+	}
+	return &AtomicSnowflake{workerId: workerID, datacenterId: datacenterID}, nil
+}
+
+// NextId 生成id，序列用尽时自旋等待下一毫秒
+func (s *AtomicSnowflake) NextId() (int64, error) {
+	for {
+		old := s.state.Load()
+		lastTimestamp := old & atomicTimestampMask
+		sequence := old >> atomicSequenceShift
+
+		timestamp := timeGen() - twepoch
+		if timestamp < lastTimestamp {
+			return 0, &ErrClockMovedBackwards{Duration: time.Duration(lastTimestamp-timestamp) * time.Millisecond}
+		}
+		if timestamp > maxTimestamp {
+			return 0, fmt.Errorf("timestamp exceeds %d bits", timestampBits)
+		}
+		if timestamp == lastTimestamp {
+			sequence++
+			if sequence > sequenceMask { // 序列用尽，等待下一毫秒
+				continue
+			}
+		} else {
+			sequence = 0
+		}
+
+		if s.state.CompareAndSwap(old, sequence<<atomicSequenceShift|timestamp) {
+			return (timestamp << timestampLeftShift) |
+				(s.datacenterId << datacenterIdShift) |
+				(s.workerId << workerIdShift) |
+				sequence, nil
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package snowflake
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestAtomicSnowflake(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(1))
+
+	sf, err := NewAtomic(3, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const goroutines, perGoroutine = 8, 5000
+	ids := make(chan int64, goroutines*perGoroutine)
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perGoroutine; j++ {
+				id, err := sf.NextId()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				ids <- id
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[int64]bool, goroutines*perGoroutine)
+	for id := range ids {
+		if seen[id] {
+			t.Fatalf("duplicate id %d", id)
+		}
+		seen[id] = true
+		if p := ID(id).Parse(); p.WorkerId() != 3 || p.DatacenterId() != 4 {
+			t.Fatalf("id %d parsed as worker %d datacenter %d", id, p.WorkerId(), p.DatacenterId())
+		}
+	}
+	if len(seen) != goroutines*perGoroutine {
+		t.Errorf("got %d ids, want %d", len(seen), goroutines*perGoroutine)
+	}
+
+	for _, c := range [][2]int64{{-1, 0}, {32, 0}, {0, -1}, {0, 32}} {
+		if _, err := NewAtomic(c[0], c[1]); err == nil {
+			t.Errorf("NewAtomic(%d, %d) expected error", c[0], c[1])
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/pangush/snowflake"
+)
+
+func TestAvroSchema(t *testing.T) {
+	sf, err := snowflake.NewUnregistered(1, 1, snowflake.WithVersionBits(2, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(AvroSchema(sf)), &m); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"type":           "long",
+		"logicalType":    "snowflake-id",
+		"epoch":          float64(1577808000000),
+		"timestampBits":  float64(41),
+		"datacenterBits": float64(5),
+		"workerBits":     float64(5),
+		"sequenceBits":   float64(10),
+		"versionBits":    float64(2),
+	}
+	for k, v := range want {
+		if m[k] != v {
+			t.Errorf("schema %s = %v, want %v", k, m[k], v)
+		}
+	}
+}
+
+func TestEncodeAvro(t *testing.T) {
+	// Avro long 1 编码为 0x02，-1 为 0x01，64 为 0x80 0x01
+	for id, want := range map[int64][]byte{1: {0x02}, 64: {0x80, 0x01}} {
+		b, err := EncodeAvro(7, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header := []byte{0, 0, 0, 0, 7}; !bytes.Equal(b[:5], header) || !bytes.Equal(b[5:], want) {
+			t.Errorf("EncodeAvro(%d) = %x", id, b)
+		}
+	}
+
+	id := int64(1234567890123456789)
+	b, err := EncodeAvro(0x01020304, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schemaID, got, err := DecodeAvro(b)
+	if err != nil || schemaID != 0x01020304 || got != id {
+		t.Errorf("DecodeAvro = %#x, %d, %v", schemaID, got, err)
+	}
+
+	if _, err := EncodeAvro(1, -1); err == nil {
+		t.Error("EncodeAvro(-1) expected error")
+	}
+	for _, bad := range [][]byte{nil, {1, 0, 0, 0, 1, 2}, {0, 0, 0, 0, 1}, {0, 0, 0, 0, 1, 0x80}, append(b, 0)} {
+		if _, _, err := DecodeAvro(bad); !errors.Is(err, ErrInvalidMessage) {
+			t.Errorf("DecodeAvro(%x) = %v, want ErrInvalidMessage", bad, err)
+		}
+	}
+}
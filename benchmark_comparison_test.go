@@ -0,0 +1,95 @@
+//go:build bench
+
+package snowflake
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// 与UUID v4、rand.Int63以及不使用互斥锁的 AtomicSnowflake 比较生成速度，运行方式：
+//
+//	go test -tags bench -run '^$' -bench Comparison
+
+func BenchmarkComparison_NextId(b *testing.B) {
+	sf, err := NewUnregistered(0, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := sf.NextId(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkComparison_UUIDv4(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = uuid.New()
+	}
+}
+
+func BenchmarkComparison_RandInt63(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = rand.Int63()
+	}
+}
+
+func BenchmarkComparison_NextId_Parallel64(b *testing.B) {
+	sf, err := NewUnregistered(0, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.SetParallelism(64)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := sf.NextId(); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkComparison_AtomicNextId_Parallel64(b *testing.B) {
+	sf, err := NewAtomic(0, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.SetParallelism(64)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := sf.NextId(); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkComparison_UUIDv4_Parallel64(b *testing.B) {
+	b.ReportAllocs()
+	b.SetParallelism(64)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = uuid.New()
+		}
+	})
+}
+
+func BenchmarkComparison_RandInt63_Parallel64(b *testing.B) {
+	b.ReportAllocs()
+	b.SetParallelism(64)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = rand.Int63()
+		}
+	})
+}
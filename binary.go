@@ -0,0 +1,69 @@
+package snowflake
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// littleEndianMagic 小端序编码的前缀字节，大端序编码没有前缀
+const littleEndianMagic = 0x4c // 'L'
+
+var ErrByteOrderMismatch = errors.New("snowflake id binary encoding has a different byte order")
+
+// MarshalBinary 实现 encoding.BinaryMarshaler，编码为8字节大端序（网络字节序）
+func (id ID) MarshalBinary() ([]byte, error) {
+	return binary.BigEndian.AppendUint64(make([]byte, 0, 8), uint64(id)), nil
+}
+
+// UnmarshalBinary 实现 encoding.BinaryUnmarshaler，只接受 MarshalBinary 的大端序编码，
+// 小端序编码返回 ErrByteOrderMismatch
+func (id *ID) UnmarshalBinary(data []byte) error {
+	if len(data) == 9 && data[0] == littleEndianMagic {
+		return ErrByteOrderMismatch
+	}
+	if len(data) != 8 {
+		return fmt.Errorf("invalid snowflake id binary length %d", len(data))
+	}
+	*id = ID(binary.BigEndian.Uint64(data))
+	return nil
+}
+
+// WithLittleEndianEncoding 让生成器的 MarshalID 和 UnmarshalID 使用小端序，
+// 在x86机器上写入内存时可以省去字节交换。
+//
+// 注意：小端序编码与默认的大端序编码不兼容。小端序编码为1字节前缀加8字节id，
+// 用不同字节序的配置解码时会返回 ErrByteOrderMismatch，而不是得到错误的id。
+// 只应在读写双方都使用小端序的场景下开启。
+func WithLittleEndianEncoding() Option {
+	return func(s *Snowflake) error {
+		s.littleEndian = true
+		return nil
+	}
+}
+
+// MarshalID 按生成器配置的字节序编码id，默认与 ID.MarshalBinary 相同
+func (s *Snowflake) MarshalID(id int64) ([]byte, error) {
+	if !s.littleEndian {
+		return ID(id).MarshalBinary()
+	}
+	buf := make([]byte, 1, 9)
+	buf[0] = littleEndianMagic
+	return binary.LittleEndian.AppendUint64(buf, uint64(id)), nil
+}
+
+// UnmarshalID 按生成器配置的字节序解码 MarshalID 的结果，字节序不一致时返回 ErrByteOrderMismatch
+func (s *Snowflake) UnmarshalID(data []byte) (int64, error) {
+	if !s.littleEndian {
+		var id ID
+		err := id.UnmarshalBinary(data)
+		return int64(id), err
+	}
+	if len(data) == 8 {
+		return 0, ErrByteOrderMismatch
+	}
+	if len(data) != 9 || data[0] != littleEndianMagic {
+		return 0, fmt.Errorf("invalid snowflake id binary encoding")
+	}
+	return int64(binary.LittleEndian.Uint64(data[1:])), nil
+}
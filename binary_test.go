@@ -0,0 +1,71 @@
+package snowflake
+
+import (
+	"encoding"
+	"errors"
+	"testing"
+)
+
+func TestID_MarshalBinary(t *testing.T) {
+	var _ encoding.BinaryMarshaler = ID(0)
+	var _ encoding.BinaryUnmarshaler = (*ID)(nil)
+
+	id := ID(0x0102030405060708)
+	b, err := id.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "\x01\x02\x03\x04\x05\x06\x07\x08" {
+		t.Errorf("MarshalBinary = %x", b)
+	}
+	var got ID
+	if err := got.UnmarshalBinary(b); err != nil || got != id {
+		t.Errorf("UnmarshalBinary = %d, %v", got, err)
+	}
+	if err := got.UnmarshalBinary(b[:7]); err == nil {
+		t.Error("UnmarshalBinary(7 bytes) expected error")
+	}
+}
+
+func TestWithLittleEndianEncoding(t *testing.T) {
+	le, err := NewUnregistered(1, 1, WithLittleEndianEncoding())
+	if err != nil {
+		t.Fatal(err)
+	}
+	be, err := NewUnregistered(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const id = int64(0x0102030405060708)
+	lb, err := le.MarshalID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(lb) != "L\x08\x07\x06\x05\x04\x03\x02\x01" {
+		t.Errorf("little endian MarshalID = %x", lb)
+	}
+	if got, err := le.UnmarshalID(lb); err != nil || got != id {
+		t.Errorf("little endian UnmarshalID = %d, %v", got, err)
+	}
+
+	bb, err := be.MarshalID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := be.UnmarshalID(bb); err != nil || got != id {
+		t.Errorf("big endian UnmarshalID = %d, %v", got, err)
+	}
+
+	// 字节序不一致
+	if _, err := le.UnmarshalID(bb); !errors.Is(err, ErrByteOrderMismatch) {
+		t.Errorf("little endian UnmarshalID(big endian) = %v", err)
+	}
+	if _, err := be.UnmarshalID(lb); !errors.Is(err, ErrByteOrderMismatch) {
+		t.Errorf("big endian UnmarshalID(little endian) = %v", err)
+	}
+	var x ID
+	if err := x.UnmarshalBinary(lb); !errors.Is(err, ErrByteOrderMismatch) {
+		t.Errorf("ID.UnmarshalBinary(little endian) = %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package snowflake
+
+import "math/bits"
+
+// SequenceBitset 记录同一毫秒、同一节点内出现过的毫秒内序列(0-4095)，共512字节。
+// 配合 ParsedID.Sequence 可以O(1)判断某毫秒内的id是否重复。零值可以直接使用。
+type SequenceBitset struct {
+	words [(sequenceMask + 1) / 64]uint64
+}
+
+// Set 标记sequence，超出范围的值被忽略
+func (b *SequenceBitset) Set(sequence int64) {
+	if sequence < 0 || sequence > sequenceMask {
+		return
+	}
+	b.words[sequence/64] |= 1 << (sequence % 64)
+}
+
+// IsSet sequence是否已被标记
+func (b *SequenceBitset) IsSet(sequence int64) bool {
+	if sequence < 0 || sequence > sequenceMask {
+		return false
+	}
+	return b.words[sequence/64]&(1<<(sequence%64)) != 0
+}
+
+// Clear 清除所有标记
+func (b *SequenceBitset) Clear() {
+	b.words = [len(b.words)]uint64{}
+}
+
+// Count 已标记的数量
+func (b *SequenceBitset) Count() int {
+	n := 0
+	for _, w := range b.words {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
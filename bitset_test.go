@@ -0,0 +1,64 @@
+package snowflake
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestSequenceBitset(t *testing.T) {
+	var b SequenceBitset
+	if size := unsafe.Sizeof(b); size != 512 {
+		t.Errorf("size = %d bytes, want 512", size)
+	}
+
+	for _, seq := range []int64{0, 63, 64, 4095, 63} {
+		b.Set(seq)
+	}
+	b.Set(-1)
+	b.Set(4096)
+	if b.Count() != 4 {
+		t.Errorf("Count() = %d, want 4", b.Count())
+	}
+	for seq, want := range map[int64]bool{0: true, 1: false, 63: true, 64: true, 65: false, 4095: true, -1: false, 4096: false} {
+		if b.IsSet(seq) != want {
+			t.Errorf("IsSet(%d) = %v, want %v", seq, !want, want)
+		}
+	}
+
+	b.Clear()
+	if b.Count() != 0 || b.IsSet(0) || b.IsSet(4095) {
+		t.Error("Clear() did not clear all sequences")
+	}
+
+	for seq := int64(0); seq <= sequenceMask; seq++ {
+		b.Set(seq)
+	}
+	if b.Count() != 4096 {
+		t.Errorf("Count() = %d, want 4096", b.Count())
+	}
+}
+
+func TestSequenceBitset_DetectDuplicates(t *testing.T) {
+	sf, err := New(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+	var b SequenceBitset
+	var ts int64
+	for i := 0; i < 5000; i++ {
+		id, err := sf.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := ID(id).Parse()
+		if p.Timestamp() != ts {
+			ts = p.Timestamp()
+			b.Clear()
+		}
+		if b.IsSet(p.Sequence()) {
+			t.Fatalf("duplicate sequence %d at %d", p.Sequence(), ts)
+		}
+		b.Set(p.Sequence())
+	}
+}
@@ -0,0 +1,69 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrBlockTooLarge = errors.New("snowflake id block doesn't fit in one millisecond")
+
+// GenerateBlock 在同一次加锁内分配size个连续的id，全部位于同一毫秒，只有毫秒内序列不同，
+// 调用方可以用 first, first+1, ..., last 作为批量写入的主键。
+// 当前毫秒剩余的序列不足size个时等待下一毫秒，不会跨毫秒分配；
+// size超过每毫秒的id个数上限时返回 ErrBlockTooLarge。
+func (s *Snowflake) GenerateBlock(size int64) (first int64, last int64, err error) {
+	if size <= 0 {
+		return 0, 0, fmt.Errorf("block size must be positive")
+	}
+	if s.seqStride != 0 {
+		return 0, 0, ErrForked
+	}
+	if size > s.maxSequence+1 {
+		return 0, 0, fmt.Errorf("%w: %d ids, at most %d per millisecond", ErrBlockTooLarge, size, s.maxSequence+1)
+	}
+	if s.shutdown.Load() {
+		return 0, 0, ErrShutdown
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	timestamp := s.timeGen()
+	if !s.rateAllowN(timestamp, size) {
+		return 0, 0, ErrRateLimited
+	}
+	first, err = s.nextIdWith(timestamp, func(timestamp int64) (int64, error) {
+		return s.generateBlock(timestamp, size)
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	for i := int64(1); i < size; i++ {
+		s.rateRecord(s.lastTimestamp)
+		s.sample(first + i)
+	}
+	s.lastId = first + size - 1
+	return first, first + size - 1, nil
+}
+
+// generateBlock 与 generate 相同，但为size个id预留序列，返回第一个id
+func (s *Snowflake) generateBlock(timestamp int64, size int64) (int64, error) {
+	timestamp, err := s.checkRollback(timestamp)
+	if err != nil {
+		return 0, err
+	}
+
+	sequence := int64(0)
+	if timestamp == s.lastTimestamp {
+		sequence = s.sequence + 1
+		if sequence+size-1 > s.maxSequence { // 剩余的序列不够
+			sequence = 0
+			if timestamp, err = s.nextMillis(); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	s.lastTimestamp = timestamp
+	s.sequence = sequence + size - 1
+	return s.compose(timestamp, sequence), nil
+}
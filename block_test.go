@@ -0,0 +1,72 @@
+package snowflake
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGenerateBlock(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, err := NewUnregistered(5, 6, WithClock(clock), WithMaxSequence(99))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sf.NextId(); err != nil {
+		t.Fatal(err)
+	}
+	first, last, err := sf.GenerateBlock(60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pf, pl := ID(first).Parse(), ID(last).Parse()
+	if last-first != 59 || pf.Timestamp() != pl.Timestamp() || pf.Sequence() != 1 || pl.Sequence() != 60 {
+		t.Fatalf("block = %+v .. %+v", pf, pl)
+	}
+
+	// 剩余39个序列不够，等待下一毫秒
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		clock.Add(time.Millisecond)
+	}()
+	first, last, err = sf.GenerateBlock(40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := ID(first).Parse(); p.Timestamp() != twepoch+1001 || p.Sequence() != 0 || last-first != 39 {
+		t.Errorf("block after wait = %+v, size %d", p, last-first+1)
+	}
+	if id, _ := sf.NextId(); id != last+1 {
+		t.Errorf("NextId = %d, want %d", id, last+1)
+	}
+
+	if _, _, err := sf.GenerateBlock(101); !errors.Is(err, ErrBlockTooLarge) {
+		t.Errorf("GenerateBlock(101) = %v, want ErrBlockTooLarge", err)
+	}
+	if _, _, err := sf.GenerateBlock(0); err == nil {
+		t.Error("GenerateBlock(0) expected error")
+	}
+}
+
+func TestGenerateBlock_RateLimit(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, err := NewUnregistered(5, 6, WithClock(clock), WithSlidingWindowLimit(10, time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := sf.GenerateBlock(8); err != nil {
+		t.Fatal(err)
+	}
+	// 窗口内只剩2个，整块拒绝
+	if _, _, err := sf.GenerateBlock(3); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("GenerateBlock(3) = %v, want ErrRateLimited", err)
+	}
+	if _, _, err := sf.GenerateBlock(2); err != nil {
+		t.Fatal(err)
+	}
+	clock.Add(time.Second)
+	if _, _, err := sf.GenerateBlock(10); err != nil {
+		t.Errorf("GenerateBlock(10) after window passed: %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package snowflake
+
+import (
+	"fmt"
+	"time"
+)
+
+// WithBorrowFuture 毫秒内序列用尽时不等待下一毫秒，直接借用下一毫秒的时间戳继续生成，
+// 突发流量下的延迟保持平稳。借用后id中的时间戳会领先系统时间，最多领先maxLead，
+// 达到上限后才等待系统时间追上；流量回落后系统时间自然追上。
+// 领先期间系统时间小于上一次的时间戳不视为时钟回退。Stats 中的 Borrowed、Lead 记录借用的次数和当前领先的时长。
+func WithBorrowFuture(maxLead time.Duration) Option {
+	return func(s *Snowflake) error {
+		if maxLead < time.Millisecond {
+			return fmt.Errorf("borrow lead must be at least 1ms")
+		}
+		s.borrowLead = maxLead.Milliseconds()
+		return nil
+	}
+}
+
+// borrow 序列用尽时借用下一个时间戳，已经领先 borrowLead 时先等待系统时间追上，调用方需持有锁
+func (s *Snowflake) borrow() (int64, error) {
+	next := s.nextTick(s.lastTimestamp)
+	now := s.timeGen()
+	if next-now > s.borrowLead {
+		var err error
+		if now, err = s.tilNextMillis(next - s.borrowLead - 1); err != nil {
+			return 0, err
+		}
+	}
+	if now >= next {
+		s.borrowing = false
+		return now, nil
+	}
+	s.borrowing = true
+	s.borrowed++
+	s.advancedUntil = next
+	return next, nil
+}
+
+// lead 上一次生成id的时间戳领先系统时间多久，调用方需持有锁
+func (s *Snowflake) lead() time.Duration {
+	if d := s.lastTimestamp - s.timeGen(); d > 0 {
+		return time.Duration(d) * time.Millisecond
+	}
+	return 0
+}
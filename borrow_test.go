@@ -0,0 +1,67 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithBorrowFuture(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sleeps := 0
+	sleeper := func(d time.Duration) {
+		sleeps++
+		clock.Add(d + spinThreshold)
+	}
+	sf, err := NewUnregistered(1, 2, WithClock(clock), WithSleeper(sleeper), WithMaxSequence(1), WithBorrowFuture(3*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := int64(0)
+	next := func() int64 {
+		t.Helper()
+		id, err := sf.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id <= prev {
+			t.Fatalf("id %d isn't greater than %d", id, prev)
+		}
+		prev = id
+		return id
+	}
+
+	// 借用之后的3毫秒，不等待
+	for i := 0; i < 8; i++ {
+		next()
+	}
+	if sleeps != 0 {
+		t.Errorf("slept %d times while borrowing", sleeps)
+	}
+	if ts := ID(prev).Parse().Timestamp(); ts != twepoch+1003 {
+		t.Errorf("timestamp = %d, want %d", ts, twepoch+1003)
+	}
+	st := sf.Stats()
+	if st.Borrowed != 3 || st.Lead != 3*time.Millisecond {
+		t.Errorf("Borrowed = %d, Lead = %v, want 3, 3ms", st.Borrowed, st.Lead)
+	}
+
+	// 达到领先上限后等待系统时间追上一毫秒
+	next()
+	if sleeps == 0 {
+		t.Error("didn't wait after reaching the borrow lead")
+	}
+	if ts := ID(prev).Parse().Timestamp(); ts != twepoch+1004 {
+		t.Errorf("timestamp = %d, want %d", ts, twepoch+1004)
+	}
+
+	// 领先期间系统时间小于上一次的时间戳不视为时钟回退
+	clock.Add(10 * time.Millisecond)
+	next()
+	if st := sf.Stats(); st.ClockRollbacks != 0 || st.Lead != 0 {
+		t.Errorf("ClockRollbacks = %d, Lead = %v after catching up", st.ClockRollbacks, st.Lead)
+	}
+
+	if _, err := NewUnregistered(1, 2, WithBorrowFuture(time.Microsecond)); err == nil {
+		t.Error("borrow lead below 1ms should fail")
+	}
+}
@@ -0,0 +1,44 @@
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// WithCircuitBreaker 连续failThreshold次生成失败（如时钟反复回退）后熔断，
+// 熔断期间 NextId 直接返回 ErrCircuitOpen，cooldown之后恢复。
+func WithCircuitBreaker(failThreshold int, cooldown time.Duration) Option {
+	return func(s *Snowflake) error {
+		if failThreshold <= 0 {
+			return fmt.Errorf("circuit breaker threshold must be positive")
+		}
+		s.breakerThreshold = failThreshold
+		s.breakerCooldown = cooldown.Milliseconds()
+		return nil
+	}
+}
+
+// breakerOpen 是否处于熔断中，调用方需持有锁
+func (s *Snowflake) breakerOpen(timestamp int64) bool {
+	return s.breakerThreshold > 0 && timestamp < s.openUntil
+}
+
+// breakerRecord 记录一次生成结果，NextIdContext 的ctx结束不算作生成失败，调用方需持有锁
+func (s *Snowflake) breakerRecord(timestamp int64, err error) {
+	if s.breakerThreshold == 0 || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return
+	}
+	if err == nil {
+		s.failures = 0
+		return
+	}
+	s.failures++
+	if s.failures >= s.breakerThreshold {
+		s.failures = 0
+		s.openUntil = timestamp + s.breakerCooldown
+	}
+}
@@ -0,0 +1,63 @@
+package snowflake
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithCircuitBreaker(t *testing.T) {
+	clock := newFakeClock(time.Now())
+	sf, err := NewUnregistered(0, 0, WithClock(clock), WithCircuitBreaker(3, time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+	if _, err := sf.NextId(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 时钟回退，连续失败3次后熔断
+	clock.Add(-10 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		if _, err := sf.NextId(); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("call %d: err = %v, want clock moved backwards", i, err)
+		}
+	}
+	clock.Add(20 * time.Millisecond) // 时钟已经恢复，但仍在熔断期内
+	if _, err := sf.NextId(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("err = %v, want ErrCircuitOpen", err)
+	}
+
+	clock.Add(time.Second)
+	if _, err := sf.NextId(); err != nil {
+		t.Fatalf("after cooldown err = %v, want nil", err)
+	}
+}
+
+func TestWithCircuitBreaker_ResetOnSuccess(t *testing.T) {
+	clock := newFakeClock(time.Now())
+	sf, err := NewUnregistered(0, 0, WithClock(clock), WithCircuitBreaker(2, time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+	if _, err := sf.NextId(); err != nil {
+		t.Fatal(err)
+	}
+	// 失败与成功交替，不会熔断
+	for i := 0; i < 5; i++ {
+		clock.Add(-time.Millisecond)
+		if _, err := sf.NextId(); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("err = %v, want clock moved backwards", err)
+		}
+		clock.Add(2 * time.Millisecond)
+		if _, err := sf.NextId(); err != nil {
+			t.Fatalf("err = %v, want nil", err)
+		}
+	}
+
+	if _, err := New(0, 0, WithCircuitBreaker(0, time.Second)); err == nil {
+		t.Error("WithCircuitBreaker(0) expected error")
+	}
+}
@@ -0,0 +1,86 @@
+package snowflake
+
+import (
+	"fmt"
+	"sync"
+)
+
+// IdCache 从生成器一次租用一段同一毫秒内连续的id，之后在本地逐个发放，用完才再次加锁租用下一段。
+// IdCache 不是并发安全的，应该由一个goroutine独占使用；多个goroutine共用时使用 CachedGenerator。
+// 发放的id的时间戳是租用时的时间，不同 IdCache 之间的id交错，只按毫秒大致有序。
+// 没有用完就丢弃的 IdCache 中剩余的id不会被归还，留下空缺。
+type IdCache struct {
+	s     *Snowflake
+	block int
+	next  int64 // 下一个发放的id
+	end   int64 // 本段最后一个id之后的id，next == end 时需要重新租用
+}
+
+// NewIdCache 创建每次租用block个id的 IdCache，当前毫秒剩余的序列不足时租用到的id会少于block个。
+// 本地发放的id不会经过 WithDecorator 添加的装饰器，带装饰器的生成器返回 ErrDecorated
+func (s *Snowflake) NewIdCache(block int) (*IdCache, error) {
+	if block <= 0 {
+		return nil, fmt.Errorf("cache block size must be positive")
+	}
+	if s.seqStride != 0 {
+		return nil, ErrForked
+	}
+	if len(s.decorators) > 0 {
+		return nil, ErrDecorated
+	}
+	return &IdCache{s: s, block: block}, nil
+}
+
+// NextId 发放本地缓存中的下一个id，用完时向生成器租用新的一段，租用失败时返回生成器的错误。
+// 生成器 Shutdown 之后返回 ErrShutdown，缓存中剩余的id不再发放
+func (c *IdCache) NextId() (int64, error) {
+	if c.s.shutdown.Load() {
+		return 0, ErrShutdown
+	}
+	if c.next == c.end {
+		first, count, err := c.s.NextIdN(c.block)
+		if err != nil {
+			return 0, err
+		}
+		c.next, c.end = first, first+int64(count)
+	}
+	id := c.next
+	c.next++
+	return id, nil
+}
+
+// Remaining 本地缓存中剩余的id个数
+func (c *IdCache) Remaining() int {
+	return int(c.end - c.next)
+}
+
+// CachedGenerator 在多个goroutine之间使用 IdCache：每次 NextId 通过 sync.Pool 取得一个 IdCache，
+// sync.Pool 按P缓存对象，大多数调用不需要加锁，吞吐随CPU数接近线性增长。
+// 被GC回收的 IdCache 中剩余的id会丢失，id的时间戳可能早于调用时间，见 IdCache。
+type CachedGenerator struct {
+	s     *Snowflake
+	block int
+	pool  sync.Pool
+}
+
+var _ Generator = (*CachedGenerator)(nil)
+
+// NewCachedGenerator 创建每个 IdCache 每次租用block个id的 CachedGenerator
+func (s *Snowflake) NewCachedGenerator(block int) (*CachedGenerator, error) {
+	if _, err := s.NewIdCache(block); err != nil {
+		return nil, err
+	}
+	g := &CachedGenerator{s: s, block: block}
+	g.pool.New = func() any {
+		return &IdCache{s: s, block: block}
+	}
+	return g, nil
+}
+
+// NextId 由当前P上缓存的 IdCache 发放id
+func (g *CachedGenerator) NextId() (int64, error) {
+	c := g.pool.Get().(*IdCache)
+	id, err := c.NextId()
+	g.pool.Put(c)
+	return id, err
+}
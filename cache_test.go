@@ -0,0 +1,149 @@
+package snowflake
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestIdCache(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, err := NewUnregistered(5, 6, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := sf.NewIdCache(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := c.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Remaining() != 9 {
+		t.Fatalf("Remaining = %d, want 9", c.Remaining())
+	}
+	// 本地发放的id是连续的，生成器同时生成的id排在整段之后
+	for i := int64(1); i < 10; i++ {
+		id, err := c.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != first+i {
+			t.Fatalf("id %d = %d, want %d", i, id, first+i)
+		}
+	}
+	direct, err := sf.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if direct != first+10 {
+		t.Fatalf("NextId = %d, want %d", direct, first+10)
+	}
+
+	// 用完后重新租用
+	clock.Add(time.Millisecond)
+	id, err := c.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := ID(id).Parse(); p.Sequence() != 0 || p.Timestamp() != ID(first).Parse().Timestamp()+1 {
+		t.Fatalf("refilled id parsed as %+v", p)
+	}
+
+	sf.Shutdown()
+	if _, err := c.NextId(); !errors.Is(err, ErrShutdown) {
+		t.Fatalf("NextId after Shutdown error = %v, want ErrShutdown", err)
+	}
+}
+
+func TestIdCache_Invalid(t *testing.T) {
+	sf, err := NewUnregistered(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sf.NewIdCache(0); err == nil {
+		t.Error("NewIdCache(0) expected error")
+	}
+	forks, err := sf.ForkSequence(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := forks[0].NewIdCache(10); !errors.Is(err, ErrForked) {
+		t.Errorf("NewIdCache on fork error = %v, want ErrForked", err)
+	}
+	if _, err := forks[0].NewCachedGenerator(10); !errors.Is(err, ErrForked) {
+		t.Errorf("NewCachedGenerator on fork error = %v, want ErrForked", err)
+	}
+
+	decorated, err := NewUnregistered(1, 1, WithDecorator(&recordDecorator{calls: new([]string)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := decorated.NewIdCache(10); !errors.Is(err, ErrDecorated) {
+		t.Errorf("NewIdCache with decorator error = %v, want ErrDecorated", err)
+	}
+	if _, err := decorated.NewCachedGenerator(10); !errors.Is(err, ErrDecorated) {
+		t.Errorf("NewCachedGenerator with decorator error = %v, want ErrDecorated", err)
+	}
+}
+
+func TestCachedGenerator(t *testing.T) {
+	sf, err := NewUnregistered(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := sf.NewCachedGenerator(64)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const goroutines, perGoroutine = 8, 10000
+	ids := make([][]int64, goroutines)
+	var wg sync.WaitGroup
+	for i := range ids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < perGoroutine; j++ {
+				id, err := g.NextId()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				ids[i] = append(ids[i], id)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[int64]bool, goroutines*perGoroutine)
+	for _, list := range ids {
+		for _, id := range list {
+			if seen[id] {
+				t.Fatalf("duplicate id %d", id)
+			}
+			seen[id] = true
+		}
+	}
+}
+
+func BenchmarkCachedGenerator(b *testing.B) {
+	sf, err := NewUnregistered(1, 1)
+	if err != nil {
+		b.Fatal(err)
+	}
+	g, err := sf.NewCachedGenerator(256)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := g.NextId(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
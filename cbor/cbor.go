@@ -0,0 +1,29 @@
+// Package cbor 为雪花id提供 fxamacker/cbor 编解码，id编码为CBOR的无符号整数(major type 0)
+package cbor
+
+import (
+	fcbor "github.com/fxamacker/cbor/v2"
+
+	"github.com/pangush/snowflake"
+)
+
+// ID 实现 cbor.Marshaler 和 cbor.Unmarshaler 的雪花id
+type ID snowflake.ID
+
+var (
+	_ fcbor.Marshaler   = ID(0)
+	_ fcbor.Unmarshaler = (*ID)(nil)
+)
+
+func (id ID) MarshalCBOR() ([]byte, error) {
+	return fcbor.Marshal(uint64(id))
+}
+
+func (id *ID) UnmarshalCBOR(data []byte) error {
+	var n uint64
+	if err := fcbor.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*id = ID(n)
+	return nil
+}
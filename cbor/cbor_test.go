@@ -0,0 +1,53 @@
+package cbor
+
+import (
+	"testing"
+
+	fcbor "github.com/fxamacker/cbor/v2"
+)
+
+func TestID_RoundTrip(t *testing.T) {
+	id := ID(1234567890123456789)
+	b, err := fcbor.Marshal(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// major type 0，附加信息27表示后跟8字节的整数
+	if len(b) != 9 || b[0] != 0x1b {
+		t.Errorf("encoding = %x, want unsigned integer (0x1b)", b)
+	}
+	var got ID
+	if err := fcbor.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != id {
+		t.Errorf("round trip = %d, want %d", got, id)
+	}
+
+	// 作为结构体字段
+	type order struct {
+		ID   ID     `cbor:"id"`
+		Name string `cbor:"name"`
+	}
+	b, err = fcbor.Marshal(order{ID: id, Name: "book"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var o order
+	if err := fcbor.Unmarshal(b, &o); err != nil {
+		t.Fatal(err)
+	}
+	if o.ID != id || o.Name != "book" {
+		t.Errorf("round trip = %+v", o)
+	}
+
+	// 小的id使用最短编码
+	if b, _ := fcbor.Marshal(ID(7)); len(b) != 1 || b[0] != 0x07 {
+		t.Errorf("encoding of 7 = %x, want 07", b)
+	}
+	// 负整数(major type 1)不是合法的id
+	b, _ = fcbor.Marshal(-1)
+	if err := fcbor.Unmarshal(b, &got); err == nil {
+		t.Error("decoding negative integer expected error")
+	}
+}
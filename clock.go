@@ -0,0 +1,60 @@
+package snowflake
+
+import "time"
+
+// Clock 生成器使用的时间源
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock 系统时钟
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// WithClock 使用自定义的时间源，主要用于测试
+func WithClock(c Clock) Option {
+	return func(s *Snowflake) error {
+		s.clock = c
+		return nil
+	}
+}
+
+// frozenClock 时间固定不变的时钟
+type frozenClock struct {
+	t time.Time
+}
+
+func (c frozenClock) Now() time.Time {
+	return c.t
+}
+
+// WithFrozenClock 使用固定在t的时钟，用于属性测试和golden测试，
+// 生成的id序列与真实时间无关。序列号仍正常递增，但同一毫秒内序列耗尽后
+// tilNextMillis 会永远阻塞，测试中生成的id数量不能超过毫秒内序列的上限。
+func WithFrozenClock(t time.Time) Option {
+	return WithClock(frozenClock{t: t})
+}
+
+// monotonicClock 基于单调时钟的时间源，创建时记录一次系统时间，之后只按单调时钟流逝的时间前进
+type monotonicClock struct {
+	start time.Time // 带有单调时钟读数的创建时间
+}
+
+// NewMonotonicClock 创建不受系统时间调整影响的时钟：返回创建时的系统时间加上单调时钟流逝的时间，
+// NTP校时、手工修改系统时间都不会让它回退或跳变。
+// 代价是与系统时间的偏差会随运行时间累积，不会被校时纠正，长时间运行的进程生成的id中的时间戳可能与实际时间略有出入。
+func NewMonotonicClock() Clock {
+	return monotonicClock{start: time.Now()}
+}
+
+func (c monotonicClock) Now() time.Time {
+	return c.start.Add(time.Since(c.start))
+}
+
+// WithMonotonicClock 使用 NewMonotonicClock 创建的时钟
+func WithMonotonicClock() Option {
+	return WithClock(NewMonotonicClock())
+}
@@ -0,0 +1,91 @@
+package snowflake
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock 可以手动调整的时钟
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func newFakeClock(t time.Time) *fakeClock {
+	return &fakeClock{t: t}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+func TestWithFrozenClock(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	gen := func() []int64 {
+		sf, err := NewUnregistered(1, 2, WithFrozenClock(at))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := make([]int64, 100)
+		for i := range ids {
+			if ids[i], err = sf.NextId(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return ids
+	}
+
+	a := gen()
+	for i, id := range a {
+		p := ID(id).Parse()
+		if !p.Time().Equal(at) {
+			t.Fatalf("id %d time = %v, want %v", i, p.Time(), at)
+		}
+		if p.Sequence() != int64(i) {
+			t.Fatalf("id %d sequence = %d, want %d", i, p.Sequence(), i)
+		}
+	}
+	time.Sleep(2 * time.Millisecond)
+	b := gen()
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("id %d = %d, want %d", i, b[i], a[i])
+		}
+	}
+}
+
+func TestMonotonicClock(t *testing.T) {
+	c := NewMonotonicClock()
+	prev := c.Now()
+	for i := 0; i < 100; i++ {
+		now := c.Now()
+		if now.Before(prev) {
+			t.Fatalf("monotonic clock went back from %v to %v", prev, now)
+		}
+		prev = now
+	}
+	if d := time.Since(prev); d < 0 || d > time.Second {
+		t.Errorf("monotonic clock is %v away from the system time", d)
+	}
+
+	sf, err := NewUnregistered(1, 2, WithMonotonicClock())
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := sf.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(ID(id).Time()); d < 0 || d > time.Second {
+		t.Errorf("id time is %v away from now", d)
+	}
+}
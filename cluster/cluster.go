@@ -0,0 +1,99 @@
+// Package cluster 汇总登记在 Redis 集合中的雪花算法生成器，查看集群中的节点分配情况
+package cluster
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pangush/snowflake"
+)
+
+// RedisClient 读取节点集合用到的 Redis 命令
+type RedisClient interface {
+	SMembers(key string) ([]string, error)
+}
+
+// NodeInfo 一个登记的生成器
+type NodeInfo struct {
+	DatacenterID int64
+	WorkerID     int64
+	Hostname     string
+	RegisteredAt time.Time // 登记时间，成员中没有记录时为零值
+}
+
+// ClusterState 集群中所有登记的生成器
+type ClusterState struct {
+	Nodes          []NodeInfo // 按数据id、机器id排序
+	DuplicateNodes []NodeInfo // 数据id和机器id与其它节点相同的节点，这些节点可能生成重复的id
+}
+
+// FetchClusterState 读取key集合中登记的所有生成器。集合成员的格式为
+// "<datacenter>:<worker>:<hostname>"，可以在后面追加 ":<登记时的毫秒时间戳>"，
+// 例如生成器启动时执行 SADD snowflake:nodes "1:3:pod-a:1700000000000"。
+func FetchClusterState(redisClient RedisClient, key string) (ClusterState, error) {
+	members, err := redisClient.SMembers(key)
+	if err != nil {
+		return ClusterState{}, fmt.Errorf("smembers %s: %w", key, err)
+	}
+
+	var state ClusterState
+	for _, m := range members {
+		n, err := parseMember(m)
+		if err != nil {
+			return ClusterState{}, err
+		}
+		state.Nodes = append(state.Nodes, n)
+	}
+	sort.Slice(state.Nodes, func(i, j int) bool {
+		a, b := state.Nodes[i], state.Nodes[j]
+		if a.DatacenterID != b.DatacenterID {
+			return a.DatacenterID < b.DatacenterID
+		}
+		if a.WorkerID != b.WorkerID {
+			return a.WorkerID < b.WorkerID
+		}
+		return a.Hostname < b.Hostname
+	})
+
+	// 排序后节点相同的成员相邻
+	for i, n := range state.Nodes {
+		prevSame := i > 0 && sameNode(state.Nodes[i-1], n)
+		nextSame := i+1 < len(state.Nodes) && sameNode(state.Nodes[i+1], n)
+		if prevSame || nextSame {
+			state.DuplicateNodes = append(state.DuplicateNodes, n)
+		}
+	}
+	return state, nil
+}
+
+func sameNode(a, b NodeInfo) bool {
+	return a.DatacenterID == b.DatacenterID && a.WorkerID == b.WorkerID
+}
+
+// parseMember 解析集合成员
+func parseMember(m string) (NodeInfo, error) {
+	parts := strings.Split(m, ":")
+	if len(parts) != 3 && len(parts) != 4 {
+		return NodeInfo{}, fmt.Errorf("invalid node %q: want <datacenter>:<worker>:<hostname>", m)
+	}
+	datacenterID, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || datacenterID < 0 || datacenterID > snowflake.MaxDatacenterID {
+		return NodeInfo{}, fmt.Errorf("invalid node %q: bad datacenter id", m)
+	}
+	workerID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || workerID < 0 || workerID > snowflake.MaxWorkerID {
+		return NodeInfo{}, fmt.Errorf("invalid node %q: bad worker id", m)
+	}
+	n := NodeInfo{DatacenterID: datacenterID, WorkerID: workerID, Hostname: parts[2]}
+	if len(parts) == 4 {
+		ms, err := strconv.ParseInt(parts[3], 10, 64)
+		if err != nil {
+			return NodeInfo{}, fmt.Errorf("invalid node %q: bad registration time", m)
+		}
+		n.RegisteredAt = time.UnixMilli(ms)
+	}
+	return n, nil
+}
@@ -0,0 +1,58 @@
+package cluster
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeRedis struct {
+	sets map[string][]string
+	err  error
+}
+
+func (r *fakeRedis) SMembers(key string) ([]string, error) {
+	return r.sets[key], r.err
+}
+
+func TestFetchClusterState(t *testing.T) {
+	r := &fakeRedis{sets: map[string][]string{"snowflake:nodes": {
+		"1:3:pod-b",
+		"0:7:pod-a:1700000000000",
+		"1:3:pod-c",
+		"1:4:pod-d",
+	}}}
+	state, err := FetchClusterState(r, "snowflake:nodes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []NodeInfo{
+		{DatacenterID: 0, WorkerID: 7, Hostname: "pod-a", RegisteredAt: time.UnixMilli(1700000000000)},
+		{DatacenterID: 1, WorkerID: 3, Hostname: "pod-b"},
+		{DatacenterID: 1, WorkerID: 3, Hostname: "pod-c"},
+		{DatacenterID: 1, WorkerID: 4, Hostname: "pod-d"},
+	}
+	if len(state.Nodes) != len(want) {
+		t.Fatalf("nodes = %+v", state.Nodes)
+	}
+	for i := range want {
+		if state.Nodes[i] != want[i] {
+			t.Errorf("nodes[%d] = %+v, want %+v", i, state.Nodes[i], want[i])
+		}
+	}
+	if len(state.DuplicateNodes) != 2 || state.DuplicateNodes[0].Hostname != "pod-b" || state.DuplicateNodes[1].Hostname != "pod-c" {
+		t.Errorf("duplicates = %+v, want pod-b and pod-c", state.DuplicateNodes)
+	}
+
+	for _, m := range []string{"1:3", "x:3:pod", "1:32:pod", "1:3:pod:yesterday"} {
+		r.sets["bad"] = []string{m}
+		if _, err := FetchClusterState(r, "bad"); err == nil {
+			t.Errorf("member %q expected error", m)
+		}
+	}
+
+	r.err = errors.New("connection refused")
+	if _, err := FetchClusterState(r, "snowflake:nodes"); !errors.Is(err, r.err) {
+		t.Errorf("err = %v, want %v", err, r.err)
+	}
+}
@@ -0,0 +1,244 @@
+// Command snowflake 在命令行中生成、解析和转换雪花id，用于脚本、调试和排查问题：
+//
+//	snowflake gen -n 100 -worker 3 -dc 1   生成100个id，每行一个
+//	snowflake decode 1234567890123456789   解析id的生成时间、数据id、机器id和序列
+//	snowflake encode -base62 1234567890    把十进制id转换为Base62等编码
+//
+// -base62、-base58、-base32、-hex 指定 gen、encode 输出以及 decode 输入的编码，默认为十进制；
+// -epoch 指定起始时间，格式为unix时间戳(毫秒)或RFC3339，默认使用本包的起始时间。
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pangush/snowflake"
+)
+
+const usage = `usage:
+  snowflake gen [-n count] [-worker id] [-dc id] [-epoch epoch] [encoding]
+  snowflake decode [-epoch epoch] [-json] [encoding] <id>...
+  snowflake encode [encoding] <id>...
+
+encoding: -base62, -base58, -base32 or -hex, decimal by default
+`
+
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprint(os.Stderr, usage)
+		}
+		fmt.Fprintln(os.Stderr, "snowflake:", err)
+		os.Exit(2)
+	}
+}
+
+// run 执行args中的子命令，结果写入out
+func run(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	switch args[0] {
+	case "gen":
+		return gen(args[1:], out)
+	case "decode":
+		return decode(args[1:], out)
+	case "encode":
+		return encode(args[1:], out)
+	}
+	return fmt.Errorf("%w: unknown command %q", errUsage, args[0])
+}
+
+// encoding id的一种字符串编码
+type encoding struct {
+	encode func(int64) string
+	decode func(string) (int64, error)
+}
+
+var decimal = encoding{
+	encode: func(id int64) string { return strconv.FormatInt(id, 10) },
+	decode: func(s string) (int64, error) { return strconv.ParseInt(s, 10, 64) },
+}
+
+var encodings = map[string]encoding{
+	"base62": {snowflake.EncodeBase62, snowflake.DecodeBase62},
+	"base58": {snowflake.EncodeBase58, snowflake.DecodeBase58},
+	"base32": {snowflake.EncodeBase32, snowflake.DecodeBase32},
+	"hex":    {snowflake.EncodeHex, snowflake.DecodeHex},
+}
+
+// encodingFlags 在fs上注册 -base62 等编码参数，返回的函数在解析参数后取得选择的编码
+func encodingFlags(fs *flag.FlagSet) func() (encoding, error) {
+	selected := make(map[string]*bool, len(encodings))
+	for name := range encodings {
+		selected[name] = fs.Bool(name, false, name+" encoding")
+	}
+	return func() (encoding, error) {
+		enc, chosen := decimal, ""
+		for name, on := range selected {
+			if !*on {
+				continue
+			}
+			if chosen != "" {
+				return encoding{}, fmt.Errorf("%w: -%s and -%s can't be used together", errUsage, chosen, name)
+			}
+			enc, chosen = encodings[name], name
+		}
+		return enc, nil
+	}
+}
+
+// epochFlag 注册 -epoch 参数
+func epochFlag(fs *flag.FlagSet) func() (time.Time, error) {
+	v := fs.String("epoch", "", "epoch as unix milliseconds or RFC3339, package default if empty")
+	return func() (time.Time, error) {
+		if *v == "" {
+			return snowflake.Parse(0).Time(), nil // id 0 的生成时间即默认的起始时间
+		}
+		if ms, err := strconv.ParseInt(*v, 10, 64); err == nil {
+			return time.UnixMilli(ms), nil
+		}
+		t, err := time.Parse(time.RFC3339, *v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid epoch %q", *v)
+		}
+		return t, nil
+	}
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// parseFlags 解析参数，参数错误时返回 errUsage
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	return nil
+}
+
+func gen(args []string, out io.Writer) error {
+	fs := newFlagSet("gen")
+	n := fs.Int("n", 1, "number of ids")
+	worker := fs.Int64("worker", 0, "worker id")
+	datacenter := fs.Int64("dc", 0, "datacenter id")
+	epoch := epochFlag(fs)
+	enc := encodingFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *n <= 0 || fs.NArg() != 0 {
+		return fmt.Errorf("%w: gen takes a positive -n and no arguments", errUsage)
+	}
+	e, err := enc()
+	if err != nil {
+		return err
+	}
+	start, err := epoch()
+	if err != nil {
+		return err
+	}
+
+	s, err := snowflake.New(*worker, *datacenter, snowflake.WithEpoch(start),
+		snowflake.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	ids, err := s.NextIds(*n)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		fmt.Fprintln(out, e.encode(id))
+	}
+	return nil
+}
+
+// decoded decode 输出的一个id
+type decoded struct {
+	ID           string `json:"id"`
+	Time         string `json:"time"`
+	DatacenterID int64  `json:"datacenter_id"`
+	WorkerID     int64  `json:"worker_id"`
+	Sequence     int64  `json:"sequence"`
+}
+
+func decode(args []string, out io.Writer) error {
+	fs := newFlagSet("decode")
+	asJSON := fs.Bool("json", false, "print one JSON object per id")
+	epoch := epochFlag(fs)
+	enc := encodingFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("%w: decode needs at least one id", errUsage)
+	}
+	e, err := enc()
+	if err != nil {
+		return err
+	}
+	start, err := epoch()
+	if err != nil {
+		return err
+	}
+
+	for _, arg := range fs.Args() {
+		id, err := e.decode(arg)
+		if err != nil {
+			return fmt.Errorf("invalid id %q: %w", arg, err)
+		}
+		p := snowflake.ParseWithEpoch(id, start)
+		d := decoded{
+			ID:           strconv.FormatInt(id, 10),
+			Time:         p.Time().UTC().Format("2006-01-02T15:04:05.000Z07:00"),
+			DatacenterID: p.DatacenterId(),
+			WorkerID:     p.WorkerId(),
+			Sequence:     p.Sequence(),
+		}
+		if *asJSON {
+			b, _ := json.Marshal(d)
+			fmt.Fprintln(out, string(b))
+			continue
+		}
+		fmt.Fprintf(out, "id=%s time=%s datacenter=%d worker=%d sequence=%d\n",
+			d.ID, d.Time, d.DatacenterID, d.WorkerID, d.Sequence)
+	}
+	return nil
+}
+
+func encode(args []string, out io.Writer) error {
+	fs := newFlagSet("encode")
+	enc := encodingFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("%w: encode needs at least one id", errUsage)
+	}
+	e, err := enc()
+	if err != nil {
+		return err
+	}
+	for _, arg := range fs.Args() {
+		id, err := decimal.decode(arg)
+		if err != nil || id < 0 {
+			return fmt.Errorf("invalid id %q: want a non-negative decimal id", arg)
+		}
+		fmt.Fprintln(out, e.encode(id))
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pangush/snowflake"
+)
+
+func runCmd(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	err := run(args, &out)
+	return out.String(), err
+}
+
+func TestGen(t *testing.T) {
+	out, err := runCmd(t, "gen", "-n", "5", "-worker", "3", "-dc", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Fields(out)
+	if len(lines) != 5 {
+		t.Fatalf("gen printed %d ids, want 5: %q", len(lines), out)
+	}
+	prev := int64(0)
+	for _, l := range lines {
+		id, err := strconv.ParseInt(l, 10, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p := snowflake.Parse(id); p.WorkerId() != 3 || p.DatacenterId() != 1 || id <= prev {
+			t.Errorf("id %d: worker %d datacenter %d", id, p.WorkerId(), p.DatacenterId())
+		}
+		prev = id
+	}
+
+	out, err = runCmd(t, "gen", "-base62", "-worker", "4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := snowflake.DecodeBase62(strings.TrimSpace(out))
+	if err != nil || snowflake.Parse(id).WorkerId() != 4 {
+		t.Errorf("base62 gen = %q, %v", out, err)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	sf, _ := snowflake.NewUnregistered(7, 2)
+	id, _ := sf.NextId()
+	p := snowflake.Parse(id)
+
+	out, err := runCmd(t, "decode", strconv.FormatInt(id, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"datacenter=2", "worker=7", "time=" + p.Time().UTC().Format("2006-01-02T15:04:05.000Z")} {
+		if !strings.Contains(out, want) {
+			t.Errorf("decode = %q, want %q", out, want)
+		}
+	}
+
+	out, err = runCmd(t, "decode", "-json", "-hex", snowflake.EncodeHex(id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var d decoded
+	if err := json.Unmarshal([]byte(out), &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.ID != strconv.FormatInt(id, 10) || d.WorkerID != 7 || d.Sequence != p.Sequence() {
+		t.Errorf("decode -json = %+v", d)
+	}
+
+	// 指定起始时间
+	out, err = runCmd(t, "decode", "-epoch", "1288834974657", "1")
+	if err != nil || !strings.Contains(out, "time=2010-11-04T01:42:54.657Z") {
+		t.Errorf("decode -epoch = %q, %v", out, err)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	out, err := runCmd(t, "encode", "-base62", "1234567890", "61")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := snowflake.EncodeBase62(1234567890) + "\n" + snowflake.EncodeBase62(61) + "\n"
+	if out != want {
+		t.Errorf("encode = %q, want %q", out, want)
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	for _, args := range [][]string{
+		nil,
+		{"bogus"},
+		{"gen", "-n", "0"},
+		{"gen", "-flag"},
+		{"decode"},
+		{"encode", "-base62", "-hex", "1"},
+	} {
+		if _, err := runCmd(t, args...); !errors.Is(err, errUsage) {
+			t.Errorf("run(%q) = %v, want usage error", args, err)
+		}
+	}
+	if _, err := runCmd(t, "encode", "--", "-1"); err == nil || errors.Is(err, errUsage) {
+		t.Errorf("encode -- -1 = %v, want invalid id error", err)
+	}
+	if _, err := runCmd(t, "decode", "xyz"); err == nil {
+		t.Error("decode xyz should fail")
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/pangush/snowflake"
+)
+
+// maxCount /ids 一次最多生成的id个数
+const maxCount = 10000
+
+// newHandler 提供 /id、/ids 和 /healthz 接口
+func newHandler(s *snowflake.Snowflake) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		h := s.Check()
+		res := map[string]interface{}{
+			"clock_skew_ms":   h.ClockSkew.Milliseconds(),
+			"lifetime_hours":  int64(h.Lifetime.Hours()),
+			"exhaustion_rate": h.ExhaustionRate,
+		}
+		status := http.StatusOK
+		if err := snowflake.DefaultHealthPolicy.Evaluate(h); err != nil {
+			status = http.StatusServiceUnavailable
+			res["error"] = err.Error()
+		}
+		writeJSON(w, status, res)
+	})
+	mux.HandleFunc("/id", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		id, err := s.NextId()
+		if err != nil {
+			writeError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]snowflake.ID{"id": snowflake.ID(id)})
+	})
+	mux.HandleFunc("/ids", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		count, err := strconv.Atoi(r.URL.Query().Get("count"))
+		if err != nil || count <= 0 || count > maxCount {
+			writeError(w, http.StatusBadRequest, "count must be between 1 and "+strconv.Itoa(maxCount))
+			return
+		}
+		ids, err := s.NextIds(count)
+		if err != nil {
+			writeError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		res := make([]snowflake.ID, len(ids))
+		for i, id := range ids {
+			res[i] = snowflake.ID(id)
+		}
+		writeJSON(w, http.StatusOK, map[string][]snowflake.ID{"ids": res})
+	})
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
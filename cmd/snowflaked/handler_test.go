@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pangush/snowflake"
+)
+
+func TestHandler(t *testing.T) {
+	s, err := snowflake.NewUnregistered(3, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(newHandler(s))
+	defer srv.Close()
+
+	get := func(path string, v interface{}) int {
+		t.Helper()
+		res, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		if v != nil {
+			if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return res.StatusCode
+	}
+
+	var one struct{ ID snowflake.ID }
+	if code := get("/id", &one); code != http.StatusOK {
+		t.Fatalf("/id status = %d", code)
+	}
+	if p := one.ID.Parse(); p.WorkerId() != 3 || p.DatacenterId() != 4 {
+		t.Errorf("/id returned %d parsed as %+v", one.ID, p)
+	}
+
+	var many struct{ IDs []snowflake.ID }
+	if code := get("/ids?count=5", &many); code != http.StatusOK {
+		t.Fatalf("/ids status = %d", code)
+	}
+	if len(many.IDs) != 5 {
+		t.Fatalf("/ids returned %d ids, want 5", len(many.IDs))
+	}
+	for i, id := range many.IDs {
+		if id <= one.ID || (i > 0 && id <= many.IDs[i-1]) {
+			t.Errorf("ids aren't increasing: %v", many.IDs)
+		}
+	}
+
+	for _, path := range []string{"/ids", "/ids?count=0", "/ids?count=10001", "/ids?count=x"} {
+		if code := get(path, nil); code != http.StatusBadRequest {
+			t.Errorf("%s status = %d, want 400", path, code)
+		}
+	}
+	res, err := http.Post(srv.URL+"/id", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("POST /id status = %d, want 405", res.StatusCode)
+	}
+
+	var health struct {
+		Lifetime int64 `json:"lifetime_hours"`
+		Error    string
+	}
+	if code := get("/healthz", &health); code != http.StatusOK || health.Lifetime <= 0 || health.Error != "" {
+		t.Errorf("/healthz status = %d, body %+v", code, health)
+	}
+
+	s.Close()
+	if code := get("/id", nil); code != http.StatusServiceUnavailable {
+		t.Errorf("/id after Close status = %d, want 503", code)
+	}
+	health.Error = ""
+	if code := get("/healthz", &health); code != http.StatusServiceUnavailable || health.Error == "" {
+		t.Errorf("/healthz after Close status = %d, body %+v", code, health)
+	}
+}
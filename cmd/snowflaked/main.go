@@ -0,0 +1,125 @@
+// Command snowflaked 以gRPC和HTTP JSON接口对外提供雪花id生成，供不使用Go的服务调用。
+//
+// 节点由 -worker、-datacenter 参数指定，未指定时读取环境变量
+// SNOWFLAKE_WORKER_ID、SNOWFLAKE_DATACENTER_ID。HTTP接口：
+//
+//	GET /id           {"id":"1234567890"}
+//	GET /ids?count=N  {"ids":["1234567890", ...]}
+//	GET /healthz      {"clock_skew_ms":0,"lifetime_hours":...,"exhaustion_rate":0}，不健康时返回503和error
+//
+// id以字符串返回，避免JavaScript丢失精度。gRPC接口见 github.com/pangush/snowflake/grpc，
+// 指定 -tls-cert、-tls-key、-tls-ca 时使用双向TLS。
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	grpclib "google.golang.org/grpc"
+
+	"github.com/pangush/snowflake"
+	sfgrpc "github.com/pangush/snowflake/grpc"
+)
+
+func main() {
+	var (
+		worker     = flag.Int64("worker", envInt64("SNOWFLAKE_WORKER_ID"), "worker id (env SNOWFLAKE_WORKER_ID)")
+		datacenter = flag.Int64("datacenter", envInt64("SNOWFLAKE_DATACENTER_ID"), "datacenter id (env SNOWFLAKE_DATACENTER_ID)")
+		httpAddr   = flag.String("http", ":8080", "HTTP listen address, empty to disable")
+		grpcAddr   = flag.String("grpc", ":9090", "gRPC listen address, empty to disable")
+		certFile   = flag.String("tls-cert", "", "gRPC server certificate for mutual TLS")
+		keyFile    = flag.String("tls-key", "", "gRPC server key for mutual TLS")
+		caFile     = flag.String("tls-ca", "", "CA certificate used to verify gRPC clients")
+	)
+	flag.Parse()
+
+	s, err := snowflake.New(*worker, *datacenter)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer s.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errc := make(chan error, 2)
+	var httpSrv *http.Server
+	if *httpAddr != "" {
+		httpSrv = &http.Server{Addr: *httpAddr, Handler: newHandler(s)}
+		go func() { errc <- httpSrv.ListenAndServe() }()
+		log.Printf("serving HTTP on %s", *httpAddr)
+	}
+	var grpcSrv *grpclib.Server
+	if *grpcAddr != "" {
+		grpcSrv, err = newGRPCServer(s, *certFile, *keyFile, *caFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		lis, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		go func() { errc <- grpcSrv.Serve(lis) }()
+		log.Printf("serving gRPC on %s", *grpcAddr)
+	}
+
+	select {
+	case <-ctx.Done():
+	case err := <-errc:
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Print(err)
+		}
+	}
+	if httpSrv != nil {
+		httpSrv.Shutdown(context.Background())
+	}
+	if grpcSrv != nil {
+		grpcSrv.GracefulStop()
+	}
+}
+
+// newGRPCServer 证书参数都为空时创建不加密的服务，否则创建双向TLS的服务
+func newGRPCServer(s *snowflake.Snowflake, certFile, keyFile, caFile string) (*grpclib.Server, error) {
+	if certFile == "" && keyFile == "" && caFile == "" {
+		srv := grpclib.NewServer()
+		sfgrpc.Register(srv, s)
+		return srv, nil
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	caPEM, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificates in %s", caFile)
+	}
+	return sfgrpc.NewSecureServer(s, &tls.Config{Certificates: []tls.Certificate{cert}, ClientCAs: pool})
+}
+
+// envInt64 读取整数环境变量作为参数的默认值，未设置时为0
+func envInt64(key string) int64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		log.Fatalf("invalid %s %q: %v", key, v, err)
+	}
+	return n
+}
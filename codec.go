@@ -0,0 +1,67 @@
+package snowflake
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+// Decoder 从二进制流中逐个读取id，每个id为8字节大端序，与 WriteTo、WALLogger 的格式相同
+type Decoder struct {
+	r     io.Reader
+	br    *bufio.Reader
+	epoch int64
+	buf   [8]byte
+}
+
+// NewDecoder 创建从r读取、按epoch解析id的解码器，内部带缓冲
+func NewDecoder(r io.Reader, epoch time.Time) *Decoder {
+	return &Decoder{r: r, br: bufio.NewReader(r), epoch: epoch.UnixMilli()}
+}
+
+// Decode 读取并解析下一个id，没有更多id时返回 io.EOF，末尾不足8字节时返回 io.ErrUnexpectedEOF
+func (d *Decoder) Decode() (ParsedID, error) {
+	if _, err := io.ReadFull(d.br, d.buf[:]); err != nil {
+		return ParsedID{}, err
+	}
+	return parse(ID(binary.BigEndian.Uint64(d.buf[:])), d.epoch), nil
+}
+
+// Close 底层的r实现了 io.Closer 时将其关闭
+func (d *Decoder) Close() error {
+	if c, ok := d.r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Encoder 以8字节大端序向二进制流逐个写入id
+type Encoder struct {
+	w   io.Writer
+	bw  *bufio.Writer
+	buf [8]byte
+}
+
+// NewEncoder 创建向w写入id的编码器，内部带缓冲，写完后需要调用 Close
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w, bw: bufio.NewWriter(w)}
+}
+
+// Encode 写入一个id
+func (e *Encoder) Encode(id int64) error {
+	binary.BigEndian.PutUint64(e.buf[:], uint64(id))
+	_, err := e.bw.Write(e.buf[:])
+	return err
+}
+
+// Close 写出缓冲区中的数据，底层的w实现了 io.Closer 时将其关闭
+func (e *Encoder) Close() error {
+	err := e.bw.Flush()
+	if c, ok := e.w.(io.Closer); ok {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
@@ -0,0 +1,72 @@
+package snowflake
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// closeRecorder 记录是否被关闭
+type closeRecorder struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestEncoderDecoder(t *testing.T) {
+	sf, err := NewUnregistered(4, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out closeRecorder
+	enc := NewEncoder(&out)
+	var want []int64
+	for i := 0; i < 10000; i++ {
+		id, err := sf.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := enc.Encode(id); err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, id)
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !out.closed || out.Len() != 8*len(want) {
+		t.Fatalf("closed %v, wrote %d bytes", out.closed, out.Len())
+	}
+
+	in := &closeRecorder{Buffer: *bytes.NewBuffer(out.Bytes())}
+	dec := NewDecoder(in, time.UnixMilli(twepoch))
+	for i, id := range want {
+		p, err := dec.Decode()
+		if err != nil {
+			t.Fatalf("Decode %d: %v", i, err)
+		}
+		if p != ID(id).Parse() {
+			t.Fatalf("Decode %d = %+v, want %+v", i, p, ID(id).Parse())
+		}
+	}
+	if _, err := dec.Decode(); err != io.EOF {
+		t.Errorf("Decode at end = %v, want io.EOF", err)
+	}
+	if err := dec.Close(); err != nil || !in.closed {
+		t.Errorf("Decoder.Close() = %v, closed %v", err, in.closed)
+	}
+
+	dec = NewDecoder(bytes.NewReader([]byte{1, 2, 3}), time.UnixMilli(twepoch))
+	if _, err := dec.Decode(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Decode(truncated) = %v, want io.ErrUnexpectedEOF", err)
+	}
+	if err := dec.Close(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,119 @@
+// Package compat 解析常见雪花算法变体（Twitter、Discord、Instagram）生成的id，取得生成时间和各个字段
+package compat
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Field id中时间戳之后的一个字段
+type Field struct {
+	Name string
+	Bits uint8
+}
+
+// Format 雪花算法变体的位分布，从高到低依次为时间戳和 Fields，时间戳以毫秒为单位
+type Format struct {
+	Name          string
+	Epoch         time.Time
+	TimestampBits uint8
+	Fields        []Field // 时间戳之后的字段，从高到低
+}
+
+var (
+	// Twitter 41位时间戳 | 5位数据id | 5位机器id | 12位序列，位分布与本包的默认布局相同，起始时间不同
+	Twitter = Format{
+		Name:          "twitter",
+		Epoch:         time.UnixMilli(1288834974657),
+		TimestampBits: 41,
+		Fields:        []Field{{"datacenter_id", 5}, {"worker_id", 5}, {"sequence", 12}},
+	}
+	// Discord 42位时间戳 | 5位机器id | 5位进程id | 12位自增序列，使用全部64位
+	Discord = Format{
+		Name:          "discord",
+		Epoch:         time.UnixMilli(1420070400000),
+		TimestampBits: 42,
+		Fields:        []Field{{"worker_id", 5}, {"process_id", 5}, {"increment", 12}},
+	}
+	// Instagram 41位时间戳 | 13位分片id | 10位序列
+	Instagram = Format{
+		Name:          "instagram",
+		Epoch:         time.UnixMilli(1314220021721),
+		TimestampBits: 41,
+		Fields:        []Field{{"shard_id", 13}, {"sequence", 10}},
+	}
+)
+
+// Formats 本包支持的所有变体
+var Formats = []Format{Twitter, Discord, Instagram}
+
+// Decoded 解析后的id
+type Decoded struct {
+	ID     uint64
+	Format string
+	Time   time.Time // 生成时间
+	Fields []uint64  // 与 Format.Fields 一一对应
+	names  []Field
+}
+
+// Field 按名称取得字段的值，不存在时返回false
+func (d Decoded) Field(name string) (uint64, bool) {
+	for i, f := range d.names {
+		if f.Name == name {
+			return d.Fields[i], true
+		}
+	}
+	return 0, false
+}
+
+// bits 时间戳和所有字段的总位数
+func (f Format) bits() int {
+	n := int(f.TimestampBits)
+	for _, field := range f.Fields {
+		n += int(field.Bits)
+	}
+	return n
+}
+
+// Decode 解析id，id超出该变体的位数时返回错误
+func (f Format) Decode(id uint64) (Decoded, error) {
+	total := f.bits()
+	if total > 64 {
+		return Decoded{}, fmt.Errorf("%s format uses %d bits, more than 64", f.Name, total)
+	}
+	if total < 64 && id>>total != 0 {
+		return Decoded{}, fmt.Errorf("invalid %s id %d: more than %d bits", f.Name, id, total)
+	}
+	shift := total - int(f.TimestampBits)
+	d := Decoded{
+		ID:     id,
+		Format: f.Name,
+		Time:   f.Epoch.Add(time.Duration(id>>shift) * time.Millisecond),
+		Fields: make([]uint64, len(f.Fields)),
+		names:  f.Fields,
+	}
+	for i, field := range f.Fields {
+		shift -= int(field.Bits)
+		d.Fields[i] = id >> shift & (1<<field.Bits - 1)
+	}
+	return d, nil
+}
+
+// DecodeString 解析十进制字符串表示的id，第三方API通常以字符串返回id
+func (f Format) DecodeString(s string) (Decoded, error) {
+	id, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return Decoded{}, fmt.Errorf("invalid %s id %q: %w", f.Name, s, err)
+	}
+	return f.Decode(id)
+}
+
+// Time 取得id的生成时间，id超出该变体的位数时返回零值
+func (f Format) Time(id uint64) time.Time {
+	d, err := f.Decode(id)
+	if err != nil {
+		return time.Time{}
+	}
+	return d.Time
+}
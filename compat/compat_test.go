@@ -0,0 +1,77 @@
+package compat
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pangush/snowflake"
+)
+
+func TestDiscord(t *testing.T) {
+	// Discord 文档中的示例
+	d, err := Discord.DecodeString("175928847299117063")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2016, 4, 30, 11, 18, 25, 796e6, time.UTC); !d.Time.Equal(want) {
+		t.Errorf("Time = %v, want %v", d.Time, want)
+	}
+	for name, want := range map[string]uint64{"worker_id": 1, "process_id": 0, "increment": 7} {
+		if v, ok := d.Field(name); !ok || v != want {
+			t.Errorf("%s = %d, %v, want %d", name, v, ok, want)
+		}
+	}
+	if _, ok := d.Field("shard_id"); ok {
+		t.Error("discord ids have no shard_id")
+	}
+}
+
+func TestInstagram(t *testing.T) {
+	ms := uint64(1387263000)
+	id := ms<<23 | 1341<<10 | 1
+	d, err := Instagram.Decode(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := Instagram.Epoch.Add(time.Duration(ms) * time.Millisecond); !d.Time.Equal(want) {
+		t.Errorf("Time = %v, want %v", d.Time, want)
+	}
+	if shard, _ := d.Field("shard_id"); shard != 1341 {
+		t.Errorf("shard_id = %d, want 1341", shard)
+	}
+	if seq, _ := d.Field("sequence"); seq != 1 {
+		t.Errorf("sequence = %d, want 1", seq)
+	}
+}
+
+func TestTwitter(t *testing.T) {
+	// Twitter 的位分布与本包的默认配置一致
+	sf, err := snowflake.NewUnregistered(7, 3, snowflake.WithEpoch(Twitter.Epoch))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _ := sf.NextId()
+	d, err := Twitter.Decode(uint64(id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := snowflake.ParseWithEpoch(id, Twitter.Epoch)
+	if !d.Time.Equal(p.Time()) {
+		t.Errorf("Time = %v, want %v", d.Time, p.Time())
+	}
+	want := []uint64{uint64(p.DatacenterId()), uint64(p.WorkerId()), uint64(p.Sequence())}
+	for i, v := range want {
+		if d.Fields[i] != v {
+			t.Errorf("%s = %d, want %d", Twitter.Fields[i].Name, d.Fields[i], v)
+		}
+	}
+	if !Twitter.Time(uint64(id)).Equal(p.Time()) {
+		t.Errorf("Twitter.Time = %v", Twitter.Time(uint64(id)))
+	}
+	if _, err := Twitter.DecodeString("-1"); err == nil {
+		t.Error("negative id should fail")
+	}
+	if _, err := Twitter.Decode(1 << 63); err == nil {
+		t.Error("64-bit id should fail for a 63-bit format")
+	}
+}
@@ -0,0 +1,43 @@
+package snowflake
+
+import "encoding/json"
+
+// Config 生成器的配置，不包含运行时状态
+type Config struct {
+	WorkerId         int64 `json:"worker_id"`
+	DatacenterId     int64 `json:"datacenter_id"`
+	EpochMs          int64 `json:"epoch_ms"`
+	SequenceBits     int   `json:"sequence_bits"`
+	VersionBits      int   `json:"version_bits,omitempty"`
+	WorkerIdBits     int   `json:"worker_id_bits"`
+	DatacenterIdBits int   `json:"datacenter_id_bits"`
+	TimestampBits    int   `json:"timestamp_bits"`
+	TimeUnitMs       int64 `json:"time_unit_ms"`
+	MaxIdsPerMs      int64 `json:"max_ids_per_ms"` // 时间单位大于1毫秒时按平均值向下取整
+	EpochExpiryUnix  int64 `json:"epoch_expiry_unix"` // 时间戳用尽的时间(unix时间戳/秒)
+
+	Metadata map[string]string `json:"metadata,omitempty"` // WithMetadata 设置的元数据
+}
+
+// Config 返回生成器的配置。配置在创建后不再变化，可以并发调用。
+func (s *Snowflake) Config() Config {
+	return Config{
+		WorkerId:         s.workerId,
+		DatacenterId:     s.datacenterId,
+		EpochMs:          s.epoch,
+		SequenceBits:     int(s.layout.SequenceBits - s.versionBits),
+		VersionBits:      int(s.versionBits + s.datacenterVersionBits),
+		WorkerIdBits:     int(s.layout.WorkerBits),
+		DatacenterIdBits: int(s.layout.DatacenterBits - s.datacenterVersionBits),
+		TimestampBits:    int(s.layout.TimestampBits),
+		TimeUnitMs:       s.layout.unit(),
+		MaxIdsPerMs:      (s.maxSequence + 1) / s.layout.unit(),
+		EpochExpiryUnix:  s.expiresAt() / 1000,
+		Metadata:         s.Metadata(),
+	}
+}
+
+// ConfigJSON 以JSON格式返回生成器的配置，用于健康检查等诊断接口
+func (s *Snowflake) ConfigJSON() ([]byte, error) {
+	return json.Marshal(s.Config())
+}
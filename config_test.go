@@ -0,0 +1,58 @@
+package snowflake
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+)
+
+func TestSnowflake_ConfigJSON(t *testing.T) {
+	sf, err := New(3, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				sf.NextId()
+			}
+		}()
+	}
+	b, err := sf.ConfigJSON()
+	wg.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]float64{
+		"worker_id":          3,
+		"datacenter_id":      4,
+		"epoch_ms":           1577808000000,
+		"sequence_bits":      12,
+		"worker_id_bits":     5,
+		"datacenter_id_bits": 5,
+		"timestamp_bits":     41,
+		"time_unit_ms":       1,
+		"max_ids_per_ms":     4096,
+		"epoch_expiry_unix":  float64((1577808000000 + 1<<41) / 1000),
+	}
+	for k, v := range want {
+		if m[k] != v {
+			t.Errorf("%s = %v, want %v", k, m[k], v)
+		}
+	}
+	for _, k := range []string{"sequence", "last_timestamp", "version_bits"} {
+		if _, ok := m[k]; ok {
+			t.Errorf("ConfigJSON should not contain %s", k)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Package consul 通过 Consul 会话为雪花算法生成器分配机器id
+package consul
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pangush/snowflake"
+)
+
+// sessionTTL 会话的过期时间，进程异常退出后机器id会在过期后自动释放。测试中会调小。
+var sessionTTL = 15 * time.Second
+
+var ErrNoFreeWorker = errors.New("no free snowflake worker id in consul")
+
+// Generator 从 Consul 申请到机器id的生成器。
+// 会话续期失败（如网络分区超过会话的过期时间）后，KV锁已被释放，其它进程可能拿到相同的机器id，
+// 此时生成器会被 Shutdown，之后生成id都返回 snowflake.ErrShutdown。
+type Generator struct {
+	*snowflake.Snowflake
+
+	client    *api.Client
+	session   string
+	serviceID string
+
+	stopOnce sync.Once
+	done     chan struct{} // Close 时关闭，通知续期goroutine退出
+	renewed  chan struct{} // 续期goroutine退出后关闭
+	renewErr error         // 续期失败的原因，renewed关闭后才能读取
+}
+
+// NewFromConsul 在 Consul 中为datacenterID申请一个未被占用的机器id(0-31)。
+// 机器id通过绑定会话的KV锁占用，会话在后台定期续期，进程退出或会话过期后锁自动释放；
+// 同时以 snowflake-worker:<id> 标签注册serviceName服务，方便在 Consul 中查看各节点的分配情况。
+// 不再使用时调用 Close 停止续期、销毁会话并注销服务。
+func NewFromConsul(consulAddr, serviceName string, datacenterID int64, opts ...snowflake.Option) (*Generator, error) {
+	config := api.DefaultConfig()
+	config.Address = consulAddr
+	client, err := api.NewClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	session, _, err := client.Session().Create(&api.SessionEntry{
+		Name:     serviceName + "-snowflake",
+		TTL:      sessionTTL.String(),
+		Behavior: api.SessionBehaviorDelete,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create consul session: %w", err)
+	}
+
+	for workerID := int64(0); workerID <= 31; workerID++ {
+		ok, _, err := client.KV().Acquire(&api.KVPair{
+			Key:     workerKey(serviceName, datacenterID, workerID),
+			Value:   []byte(session),
+			Session: session,
+		}, nil)
+		if err != nil {
+			client.Session().Destroy(session, nil)
+			return nil, fmt.Errorf("acquire worker id %d: %w", workerID, err)
+		}
+		if !ok {
+			continue
+		}
+
+		s, err := snowflake.New(workerID, datacenterID, opts...)
+		if err != nil {
+			client.Session().Destroy(session, nil)
+			return nil, err
+		}
+		g := &Generator{
+			Snowflake: s,
+			client:    client,
+			session:   session,
+			serviceID: fmt.Sprintf("%s-snowflake-%d-%d", serviceName, datacenterID, workerID),
+			done:      make(chan struct{}),
+			renewed:   make(chan struct{}),
+		}
+		err = client.Agent().ServiceRegister(&api.AgentServiceRegistration{
+			ID:   g.serviceID,
+			Name: serviceName,
+			Tags: []string{workerTag(workerID)},
+		})
+		if err != nil {
+			s.Close()
+			client.Session().Destroy(session, nil)
+			return nil, err
+		}
+
+		go g.renew()
+		return g, nil
+	}
+
+	client.Session().Destroy(session, nil)
+	return nil, ErrNoFreeWorker
+}
+
+// renew 定期续期会话，直到 Close 或续期失败
+func (g *Generator) renew() {
+	err := g.client.Session().RenewPeriodic(sessionTTL.String(), g.session, nil, g.done)
+	select {
+	case <-g.done:
+		// Close 主动停止，RenewPeriodic 已销毁会话
+		close(g.renewed)
+		return
+	default:
+	}
+	// 会话已经过期或无法续期，机器id可能已被其它进程占用
+	if err == nil {
+		err = api.ErrSessionExpired
+	}
+	g.renewErr = err
+	close(g.renewed)
+	g.Snowflake.Shutdown()
+}
+
+// Err 会话续期失败时返回失败原因，生成器仍正常工作时返回nil
+func (g *Generator) Err() error {
+	select {
+	case <-g.renewed:
+		return g.renewErr
+	default:
+		return nil
+	}
+}
+
+// Close 停止生成id，停止续期并销毁会话以释放机器id，然后注销服务。可以重复调用。
+func (g *Generator) Close() error {
+	var err error
+	g.stopOnce.Do(func() {
+		g.Snowflake.Shutdown()
+		close(g.done)
+		<-g.renewed
+		if g.renewErr != nil {
+			// 续期失败时 RenewPeriodic 没有销毁会话
+			g.client.Session().Destroy(g.session, nil)
+		}
+		err = g.client.Agent().ServiceDeregister(g.serviceID)
+		g.Snowflake.Close()
+	})
+	return err
+}
+
+func workerKey(serviceName string, datacenterID, workerID int64) string {
+	return fmt.Sprintf("snowflake/%s/%d/workers/%d", serviceName, datacenterID, workerID)
+}
+
+func workerTag(workerID int64) string {
+	return fmt.Sprintf("snowflake-worker:%d", workerID)
+}
@@ -0,0 +1,162 @@
+package consul
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/pangush/snowflake"
+)
+
+// fakeConsul 模拟 Consul 中用到的几个HTTP接口
+type fakeConsul struct {
+	mu    sync.Mutex
+	held  map[string]string // key -> session
+	tags  []string
+	nextS int
+
+	expired      bool     // 续期时返回会话不存在
+	destroyed    []string // 被销毁的会话
+	deregistered []string // 被注销的服务
+}
+
+func (f *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case r.URL.Path == "/v1/session/create":
+		f.nextS++
+		json.NewEncoder(w).Encode(map[string]string{"ID": "session-" + strconv.Itoa(f.nextS)})
+	case strings.HasPrefix(r.URL.Path, "/v1/kv/"):
+		key := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+		session := r.URL.Query().Get("acquire")
+		if _, ok := f.held[key]; ok {
+			w.Write([]byte("false"))
+			return
+		}
+		f.held[key] = session
+		w.Write([]byte("true"))
+	case r.URL.Path == "/v1/agent/service/register":
+		var reg struct{ Tags []string }
+		json.NewDecoder(r.Body).Decode(&reg)
+		f.tags = append(f.tags, reg.Tags...)
+	case strings.HasPrefix(r.URL.Path, "/v1/session/renew/"):
+		id := strings.TrimPrefix(r.URL.Path, "/v1/session/renew/")
+		if f.expired {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode([]map[string]string{{"ID": id, "TTL": sessionTTL.String()}})
+	case strings.HasPrefix(r.URL.Path, "/v1/session/destroy/"):
+		f.destroyed = append(f.destroyed, strings.TrimPrefix(r.URL.Path, "/v1/session/destroy/"))
+		w.Write([]byte("true"))
+	case strings.HasPrefix(r.URL.Path, "/v1/agent/service/deregister/"):
+		f.deregistered = append(f.deregistered, strings.TrimPrefix(r.URL.Path, "/v1/agent/service/deregister/"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestNewFromConsul(t *testing.T) {
+	f := &fakeConsul{held: map[string]string{
+		workerKey("orders", 2, 0): "other",
+		workerKey("orders", 2, 1): "other",
+	}}
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+	addr := strings.TrimPrefix(srv.URL, "http://")
+
+	s, err := NewFromConsul(addr, "orders", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := s.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.mu.Lock()
+	if _, ok := f.held[workerKey("orders", 2, 2)]; !ok {
+		t.Fatal("worker id 2 was not acquired")
+	}
+	if p := snowflake.ID(id).Parse().WorkerId(); p != 2 {
+		t.Errorf("generated id has worker id %d, want 2", p)
+	}
+	if len(f.tags) != 1 || f.tags[0] != "snowflake-worker:2" {
+		t.Errorf("registered tags = %v, want [snowflake-worker:2]", f.tags)
+	}
+	f.mu.Unlock()
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.mu.Lock()
+	if len(f.destroyed) != 1 || f.destroyed[0] != "session-1" {
+		t.Errorf("destroyed sessions = %v, want [session-1]", f.destroyed)
+	}
+	if len(f.deregistered) != 1 || f.deregistered[0] != "orders-snowflake-2-2" {
+		t.Errorf("deregistered services = %v, want [orders-snowflake-2-2]", f.deregistered)
+	}
+	if _, err := s.NextId(); !errors.Is(err, snowflake.ErrShutdown) {
+		t.Errorf("NextId after Close = %v, want ErrShutdown", err)
+	}
+}
+
+func TestNewFromConsul_SessionExpired(t *testing.T) {
+	defer func(ttl time.Duration) { sessionTTL = ttl }(sessionTTL)
+	sessionTTL = 100 * time.Millisecond
+
+	f := &fakeConsul{held: map[string]string{}}
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	s, err := NewFromConsul(strings.TrimPrefix(srv.URL, "http://"), "orders", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := s.NextId(); err != nil {
+		t.Fatal(err)
+	}
+
+	f.mu.Lock()
+	f.expired = true
+	f.mu.Unlock()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !s.IsShutdown() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !s.IsShutdown() {
+		t.Fatal("generator still running after session expired")
+	}
+	if !errors.Is(s.Err(), api.ErrSessionExpired) {
+		t.Errorf("Err() = %v, want ErrSessionExpired", s.Err())
+	}
+	if _, err := s.NextId(); !errors.Is(err, snowflake.ErrShutdown) {
+		t.Errorf("NextId after expiry = %v, want ErrShutdown", err)
+	}
+}
+
+func TestNewFromConsul_NoFreeWorker(t *testing.T) {
+	f := &fakeConsul{held: map[string]string{}}
+	for i := int64(0); i <= 31; i++ {
+		f.held[workerKey("orders", 0, i)] = "other"
+	}
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	_, err := NewFromConsul(strings.TrimPrefix(srv.URL, "http://"), "orders", 0)
+	if !errors.Is(err, ErrNoFreeWorker) {
+		t.Fatalf("err = %v, want ErrNoFreeWorker", err)
+	}
+}
@@ -0,0 +1,24 @@
+package snowflake
+
+import "context"
+
+// ContextWithID 返回带有id的context，id作为关联id在调用链中传递。
+// 与 Middleware 使用同一个key，Middleware 放入的请求id同样可以用 IDFromContext 取出。
+func ContextWithID(ctx context.Context, id int64) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// IDFromContext 取出 ContextWithID 或 Middleware 放入context的id
+func IDFromContext(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value(contextKey{}).(int64)
+	return id, ok
+}
+
+// MustIDFromContext 与 IDFromContext 相同，context中没有id时panic
+func MustIDFromContext(ctx context.Context) int64 {
+	id, ok := IDFromContext(ctx)
+	if !ok {
+		panic("snowflake: no id in context")
+	}
+	return id
+}
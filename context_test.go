@@ -0,0 +1,42 @@
+package snowflake
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestContextWithID(t *testing.T) {
+	ctx := context.Background()
+	if _, ok := IDFromContext(ctx); ok {
+		t.Error("empty context has an id")
+	}
+	ctx = ContextWithID(ctx, 42)
+	if id, ok := IDFromContext(ctx); !ok || id != 42 {
+		t.Errorf("IDFromContext = %d, %v, want 42, true", id, ok)
+	}
+	if id := MustIDFromContext(ctx); id != 42 {
+		t.Errorf("MustIDFromContext = %d, want 42", id)
+	}
+
+	// Middleware 放入的id可以直接取出
+	sf, err := NewUnregistered(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got int64
+	Middleware(sf)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = MustIDFromContext(r.Context())
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if got == 0 {
+		t.Error("no id from middleware")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("MustIDFromContext on empty context did not panic")
+		}
+	}()
+	MustIDFromContext(context.Background())
+}
@@ -0,0 +1,58 @@
+package snowflake
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// InvalidLine 无法解析出id的行
+type InvalidLine struct {
+	Line int    // 行号，从1开始
+	Text string // 原始内容
+	Err  error
+}
+
+// InvalidLines ParseCSV 中所有无法解析的行
+type InvalidLines []InvalidLine
+
+func (e InvalidLines) Error() string {
+	if len(e) == 1 {
+		return fmt.Sprintf("line %d: %v", e[0].Line, e[0].Err)
+	}
+	return fmt.Sprintf("%d invalid lines, first at line %d: %v", len(e), e[0].Line, e[0].Err)
+}
+
+// ParseCSV 逐行读取以逗号分隔的数据，解析第idColumn列(从0开始)的id。
+// 无法解析的行（包括表头）不会中断读取，而是以 InvalidLines 错误与成功解析的id一起返回。
+func ParseCSV(r io.Reader, idColumn int, epoch time.Time) ([]ParsedID, error) {
+	var ids []ParsedID
+	var invalid InvalidLines
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		fields := strings.Split(text, ",")
+		if idColumn < 0 || idColumn >= len(fields) {
+			invalid = append(invalid, InvalidLine{line, text, fmt.Errorf("missing column %d", idColumn)})
+			continue
+		}
+		id, err := ParseString(strings.TrimSpace(fields[idColumn]))
+		if err != nil {
+			invalid = append(invalid, InvalidLine{line, text, err})
+			continue
+		}
+		ids = append(ids, ParseWithEpoch(int64(id), epoch))
+	}
+	if err := scanner.Err(); err != nil {
+		return ids, err
+	}
+	if len(invalid) > 0 {
+		return ids, invalid
+	}
+	return ids, nil
+}
@@ -0,0 +1,48 @@
+package snowflake
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCSV(t *testing.T) {
+	epoch := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	id1 := int64(1000)<<timestampLeftShift | 1<<datacenterIdShift | 2<<workerIdShift | 3
+	id2 := int64(2000)<<timestampLeftShift | 4<<workerIdShift
+	fixture := "id,name\n" +
+		"2021-01-01," + itoa(id1) + ",alice\n" +
+		"\n" +
+		"2021-01-02, " + itoa(id2) + " ,bob\n" +
+		"2021-01-03,not-an-id,carol\n" +
+		"short\n"
+
+	ids, err := ParseCSV(strings.NewReader(fixture), 1, epoch)
+	if len(ids) != 2 {
+		t.Fatalf("got %d ids, want 2", len(ids))
+	}
+	if ids[0].ID() != ID(id1) || ids[0].DatacenterId() != 1 || ids[0].WorkerId() != 2 || ids[0].Sequence() != 3 {
+		t.Errorf("first id parsed as %+v", ids[0])
+	}
+	if want := epoch.Add(2000 * time.Millisecond); !ids[1].Time().Equal(want) {
+		t.Errorf("second id time = %v, want %v", ids[1].Time(), want)
+	}
+
+	var invalid InvalidLines
+	if !errors.As(err, &invalid) {
+		t.Fatalf("err = %v, want InvalidLines", err)
+	}
+	var lines []int
+	for _, l := range invalid {
+		lines = append(lines, l.Line)
+	}
+	if len(lines) != 3 || lines[0] != 1 || lines[1] != 5 || lines[2] != 6 {
+		t.Errorf("invalid lines = %v, want [1 5 6]", lines)
+	}
+
+	ids, err = ParseCSV(strings.NewReader(itoa(id1)+"\n"), 0, epoch)
+	if err != nil || len(ids) != 1 {
+		t.Errorf("ParseCSV = %v, %v, want one id and no error", ids, err)
+	}
+}
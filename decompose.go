@@ -0,0 +1,7 @@
+package snowflake
+
+// Decompose 按生成器的配置（起始时间、位分布和版本号）解析id，
+// 得到生成时间、数据id、机器id和毫秒内序列
+func (s *Snowflake) Decompose(id int64) ParsedID {
+	return s.parse(id)
+}
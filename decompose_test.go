@@ -0,0 +1,37 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	id := int64(5000)<<timestampLeftShift | 3<<datacenterIdShift | 7<<workerIdShift | 42
+	p := Parse(id)
+	if !p.Time().Equal(time.UnixMilli(twepoch+5000)) || p.DatacenterId() != 3 || p.WorkerId() != 7 || p.Sequence() != 42 {
+		t.Errorf("Parse(%d) = %+v", id, p)
+	}
+	if p != ID(id).Parse() {
+		t.Errorf("Parse and ID.Parse differ")
+	}
+}
+
+func TestSnowflake_Decompose(t *testing.T) {
+	epoch := time.UnixMilli(twepoch + 3600000)
+	clock := newFakeClock(epoch.Add(time.Second))
+	sf, err := New(9, 4, WithEpoch(epoch), WithVersionBits(2, 3), WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+	sf.NextId()
+	id, err := sf.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := sf.Decompose(id)
+	if !p.Time().Equal(epoch.Add(time.Second)) || p.WorkerId() != 9 || p.DatacenterId() != 4 ||
+		p.Sequence() != 1 || p.Version() != 3 || p.ID() != ID(id) {
+		t.Errorf("Decompose(%d) = %+v", id, p)
+	}
+}
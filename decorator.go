@@ -0,0 +1,52 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrDecorated = errors.New("operation not supported on a snowflake generator with decorators")
+
+// IDDecorator 在生成id前后执行自定义逻辑，如写入审计库、变换id等。
+// 两个方法都在生成id的锁内调用，可以调用 Config、Metadata 等不加锁的方法，
+// 但不能调用同一个生成器生成id的方法，否则会死锁。
+type IDDecorator interface {
+	// BeforeGenerate 返回错误时不生成id，直接把错误返回给调用方
+	BeforeGenerate(s *Snowflake) error
+	// AfterGenerate 收到生成的id和错误，返回值代替它们交给下一个装饰器或调用方
+	AfterGenerate(s *Snowflake, id int64, err error) (int64, error)
+}
+
+// WithDecorator 添加装饰器，可以多次使用组成链：BeforeGenerate 按添加的顺序调用，
+// AfterGenerate 按相反的顺序调用，先添加的装饰器包在最外层。
+// 装饰器作用于每次生成单个id的调用；NextIdN、GenerateBlock 只对第一个id调用，其余的id由第一个id推算。
+// IdCache、CachedGenerator 在本地发放的id不经过装饰器，因此不能用于带装饰器的生成器，见 ErrDecorated。
+func WithDecorator(d IDDecorator) Option {
+	return func(s *Snowflake) error {
+		if d == nil {
+			return fmt.Errorf("decorator can't be nil")
+		}
+		s.decorators = append(s.decorators, d)
+		return nil
+	}
+}
+
+// decorate 依次调用装饰器包装generate，调用方需持有锁
+func (s *Snowflake) decorate(generate func() (int64, error)) (int64, error) {
+	n := 0
+	var err error
+	for ; n < len(s.decorators); n++ {
+		if err = s.decorators[n].BeforeGenerate(s); err != nil {
+			break
+		}
+	}
+	id := int64(0)
+	if err == nil {
+		id, err = generate()
+	}
+	// 只有 BeforeGenerate 成功的装饰器才调用 AfterGenerate
+	for n--; n >= 0; n-- {
+		id, err = s.decorators[n].AfterGenerate(s, id, err)
+	}
+	return id, err
+}
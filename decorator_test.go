@@ -0,0 +1,90 @@
+package snowflake
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// recordDecorator 记录每个生成的id以及调用顺序
+type recordDecorator struct {
+	name  string
+	calls *[]string
+	ids   []int64
+}
+
+func (d *recordDecorator) BeforeGenerate(s *Snowflake) error {
+	*d.calls = append(*d.calls, "before "+d.name)
+	return nil
+}
+
+func (d *recordDecorator) AfterGenerate(s *Snowflake, id int64, err error) (int64, error) {
+	*d.calls = append(*d.calls, "after "+d.name)
+	if err == nil {
+		d.ids = append(d.ids, id)
+	}
+	return id, err
+}
+
+// offsetDecorator 给返回的id加上固定的偏移
+type offsetDecorator struct {
+	offset int64
+	err    error // BeforeGenerate 返回的错误
+}
+
+func (d *offsetDecorator) BeforeGenerate(s *Snowflake) error {
+	return d.err
+}
+
+func (d *offsetDecorator) AfterGenerate(s *Snowflake, id int64, err error) (int64, error) {
+	if err != nil {
+		return id, err
+	}
+	return id + d.offset, nil
+}
+
+func TestWithDecorator(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	var calls []string
+	outer := &recordDecorator{name: "outer", calls: &calls}
+	offset := &offsetDecorator{offset: 1000}
+	inner := &recordDecorator{name: "inner", calls: &calls}
+	sf, err := NewUnregistered(1, 1, WithClock(clock), WithDecorator(outer), WithDecorator(offset), WithDecorator(inner))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := sf.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"before outer", "before inner", "after inner", "after outer"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+	raw := inner.ids[0]
+	if id != raw+1000 || !reflect.DeepEqual(outer.ids, []int64{id}) {
+		t.Errorf("id = %d, inner saw %d, outer saw %v", id, raw, outer.ids)
+	}
+	if p := ID(raw).Parse(); p.Timestamp() != twepoch+1000 || p.Sequence() != 0 {
+		t.Errorf("raw id parsed as %+v", p)
+	}
+
+	// BeforeGenerate 失败时不生成id，之后的装饰器不调用
+	calls = nil
+	offset.err = errors.New("audit database unavailable")
+	if _, err := sf.NextId(); !errors.Is(err, offset.err) {
+		t.Fatalf("NextId = %v, want %v", err, offset.err)
+	}
+	if want := []string{"before outer", "after outer"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+	offset.err = nil
+	if id, _ := sf.NextId(); ID(id-1000).Parse().Sequence() != 1 {
+		t.Errorf("sequence advanced by failed BeforeGenerate: %+v", ID(id-1000).Parse())
+	}
+
+	if _, err := NewUnregistered(1, 1, WithDecorator(nil)); err == nil {
+		t.Error("WithDecorator(nil) expected error")
+	}
+}
@@ -0,0 +1,93 @@
+package snowflake
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 包级默认生成器读取的环境变量
+const (
+	EnvWorkerId     = "SNOWFLAKE_WORKER_ID"     // 机器id，未设置时为0
+	EnvDatacenterId = "SNOWFLAKE_DATACENTER_ID" // 数据id，未设置时为0
+	EnvEpoch        = "SNOWFLAKE_EPOCH"         // 起始时间，unix时间戳(毫秒)或RFC3339格式，未设置时使用默认起始时间
+)
+
+var (
+	defaultOnce sync.Once
+	defaultSf   *Snowflake
+	defaultErr  error
+)
+
+// Default 返回进程内的默认生成器，首次调用时按环境变量 SNOWFLAKE_WORKER_ID、
+// SNOWFLAKE_DATACENTER_ID、SNOWFLAKE_EPOCH 创建，之后修改环境变量不再生效。
+// 默认生成器通过 New 登记节点，同一进程中不能再用相同的节点创建生成器。
+// 创建失败时每次调用都返回同一个错误。
+func Default() (*Snowflake, error) {
+	defaultOnce.Do(func() {
+		defaultSf, defaultErr = newFromEnv()
+	})
+	return defaultSf, defaultErr
+}
+
+// NextId 使用默认生成器生成id，适用于每个进程只需要一个生成器的小服务
+func NextId() (int64, error) {
+	s, err := Default()
+	if err != nil {
+		return 0, err
+	}
+	return s.NextId()
+}
+
+// MustNextId 与 NextId 相同，出错时panic
+func MustNextId() int64 {
+	id, err := NextId()
+	if err != nil {
+		panic(fmt.Sprintf("snowflake: %v", err))
+	}
+	return id
+}
+
+// newFromEnv 按环境变量创建生成器
+func newFromEnv() (*Snowflake, error) {
+	workerId, err := envInt64(EnvWorkerId)
+	if err != nil {
+		return nil, err
+	}
+	datacenterId, err := envInt64(EnvDatacenterId)
+	if err != nil {
+		return nil, err
+	}
+	var opts []Option
+	if v := os.Getenv(EnvEpoch); v != "" {
+		epoch, err := parseEpoch(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", EnvEpoch, v, err)
+		}
+		opts = append(opts, WithEpoch(epoch))
+	}
+	return New(workerId, datacenterId, opts...)
+}
+
+// envInt64 读取整数环境变量，未设置时为0
+func envInt64(key string) (int64, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", key, v, err)
+	}
+	return n, nil
+}
+
+// parseEpoch 解析unix时间戳(毫秒)或RFC3339格式的时间
+func parseEpoch(v string) (time.Time, error) {
+	if ms, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
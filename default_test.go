@@ -0,0 +1,69 @@
+package snowflake
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewFromEnv(t *testing.T) {
+	t.Setenv(EnvWorkerId, "3")
+	t.Setenv(EnvDatacenterId, "4")
+	t.Setenv(EnvEpoch, "2020-01-01T00:00:00Z")
+	s, err := newFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.workerId != 3 || s.datacenterId != 4 {
+		t.Errorf("node = %d/%d, want 3/4", s.workerId, s.datacenterId)
+	}
+	if want := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli(); s.epoch != want {
+		t.Errorf("epoch = %d, want %d", s.epoch, want)
+	}
+	s.Close()
+
+	t.Setenv(EnvEpoch, "1577836800000")
+	if s, err = newFromEnv(); err != nil {
+		t.Fatal(err)
+	}
+	if s.epoch != 1577836800000 {
+		t.Errorf("epoch = %d, want 1577836800000", s.epoch)
+	}
+	s.Close()
+
+	for key, v := range map[string]string{EnvWorkerId: "x", EnvDatacenterId: "99", EnvEpoch: "yesterday"} {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, v)
+			if _, err := newFromEnv(); err == nil {
+				t.Errorf("%s=%q should fail", key, v)
+			}
+		})
+	}
+}
+
+func TestNextId_Default(t *testing.T) {
+	t.Setenv(EnvWorkerId, "31")
+	t.Setenv(EnvDatacenterId, "31")
+	t.Setenv(EnvEpoch, "")
+	defer func() {
+		if defaultSf != nil {
+			defaultSf.Close()
+		}
+		defaultOnce, defaultSf, defaultErr = sync.Once{}, nil, nil
+	}()
+
+	a, err := NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := MustNextId()
+	if b <= a {
+		t.Errorf("id %d isn't greater than %d", b, a)
+	}
+	if p := Parse(b); p.WorkerId() != 31 || p.DatacenterId() != 31 {
+		t.Errorf("node = %d/%d, want 31/31", p.WorkerId(), p.DatacenterId())
+	}
+	if _, err := New(31, 31); err == nil {
+		t.Error("default generator should register its node")
+	}
+}
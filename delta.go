@@ -0,0 +1,83 @@
+package snowflake
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	ErrUnsorted       = errors.New("snowflake ids are not sorted")
+	ErrCorruptDelta   = errors.New("corrupt delta encoded snowflake ids")
+	ErrOffsetOverflow = errors.New("snowflake id too far from reference id")
+)
+
+// CompressDelta 对有序的id做差分编码：第一个id按8字节大端序存放，之后依次存放与前一个id之差的varint。
+// 同一时间段内的id差值很小，通常每个id只需要1到3个字节。输入必须升序（允许重复），否则返回 ErrUnsorted。
+func CompressDelta(ids []int64) ([]byte, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	buf := make([]byte, 8, 8+len(ids)*2)
+	binary.BigEndian.PutUint64(buf, uint64(ids[0]))
+	for i := 1; i < len(ids); i++ {
+		if ids[i] < ids[i-1] {
+			return nil, ErrUnsorted
+		}
+		buf = binary.AppendUvarint(buf, uint64(ids[i]-ids[i-1]))
+	}
+	return buf, nil
+}
+
+// DecompressDelta 还原 CompressDelta 编码的id
+func DecompressDelta(b []byte) ([]int64, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+	if len(b) < 8 {
+		return nil, ErrCorruptDelta
+	}
+	id := int64(binary.BigEndian.Uint64(b))
+	ids := []int64{id}
+	for b = b[8:]; len(b) > 0; {
+		delta, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, ErrCorruptDelta
+		}
+		id += int64(delta)
+		ids = append(ids, id)
+		b = b[n:]
+	}
+	return ids, nil
+}
+
+// CompressRelative 将每个id编码为与ref之差，差值以毫秒内序列为单位，即id的整数差，必须能放进int16。
+// 毫秒内序列在id的最低位，差值不超过±32767时才能编码：同一节点同一毫秒内的id总能编码，
+// 时间戳相差1毫秒的id已经相差 1<<22，无法编码，此时返回 ErrOffsetOverflow，应改用 CompressDelta。
+func CompressRelative(ref int64, ids []int64) ([]int16, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	offsets := make([]int16, len(ids))
+	for i, id := range ids {
+		d := id - ref
+		if (id < ref) != (d < 0) || d < math.MinInt16 || d > math.MaxInt16 {
+			return nil, fmt.Errorf("%w: id %d, reference %d", ErrOffsetOverflow, id, ref)
+		}
+		offsets[i] = int16(d)
+	}
+	return offsets, nil
+}
+
+// DecompressRelative 还原 CompressRelative 编码的id
+func DecompressRelative(ref int64, offsets []int16) []int64 {
+	if len(offsets) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(offsets))
+	for i, d := range offsets {
+		ids[i] = ref + int64(d)
+	}
+	return ids
+}
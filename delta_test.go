@@ -0,0 +1,100 @@
+package snowflake
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestCompressDelta(t *testing.T) {
+	sf, err := New(3, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+	ids := make([]int64, 10000)
+	for i := range ids {
+		if ids[i], err = sf.NextId(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	b, err := CompressDelta(ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := DecompressDelta(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, ids) {
+		t.Fatal("round trip mismatch")
+	}
+
+	ratio := float64(len(b)) / float64(len(ids)*8)
+	t.Logf("compressed %d ids into %d bytes, ratio %.3f", len(ids), len(b), ratio)
+	if ratio > 0.5 {
+		t.Errorf("compression ratio %.3f, want at most 0.5", ratio)
+	}
+}
+
+func TestCompressDelta_EdgeCases(t *testing.T) {
+	for _, ids := range [][]int64{nil, {42}, {1, 1, 1}, {0, 1 << 62}} {
+		b, err := CompressDelta(ids)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := DecompressDelta(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ids) == 0 && len(got) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, ids) {
+			t.Errorf("round trip %v = %v", ids, got)
+		}
+	}
+
+	if _, err := CompressDelta([]int64{3, 2}); !errors.Is(err, ErrUnsorted) {
+		t.Errorf("CompressDelta(unsorted) = %v, want ErrUnsorted", err)
+	}
+	for _, b := range [][]byte{{1, 2, 3}, {0, 0, 0, 0, 0, 0, 0, 1, 0x80}} {
+		if _, err := DecompressDelta(b); !errors.Is(err, ErrCorruptDelta) {
+			t.Errorf("DecompressDelta(%v) = %v, want ErrCorruptDelta", b, err)
+		}
+	}
+}
+
+func TestCompressRelative(t *testing.T) {
+	ref := int64(5000)<<timestampLeftShift | 3<<workerIdShift | 100
+	ids := []int64{ref, ref + 1, ref - 100, ref + 4095 - 100, ref + math.MaxInt16, ref + math.MinInt16}
+	offsets, err := CompressRelative(ref, ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offsets[2] != -100 || offsets[4] != math.MaxInt16 {
+		t.Errorf("offsets = %v", offsets)
+	}
+	if got := DecompressRelative(ref, offsets); !reflect.DeepEqual(got, ids) {
+		t.Errorf("round trip = %v, want %v", got, ids)
+	}
+
+	for _, id := range []int64{ref + math.MaxInt16 + 1, ref + math.MinInt16 - 1, ref + 1<<timestampLeftShift} {
+		if _, err := CompressRelative(ref, []int64{ref, id}); !errors.Is(err, ErrOffsetOverflow) {
+			t.Errorf("CompressRelative(%d) = %v, want ErrOffsetOverflow", id-ref, err)
+		}
+	}
+	// 差值溢出int64
+	if _, err := CompressRelative(math.MinInt64, []int64{math.MaxInt64}); !errors.Is(err, ErrOffsetOverflow) {
+		t.Errorf("int64 overflow = %v, want ErrOffsetOverflow", err)
+	}
+
+	if offsets, err := CompressRelative(ref, nil); offsets != nil || err != nil {
+		t.Errorf("CompressRelative(nil) = %v, %v", offsets, err)
+	}
+	if ids := DecompressRelative(ref, nil); ids != nil {
+		t.Errorf("DecompressRelative(nil) = %v", ids)
+	}
+}
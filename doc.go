@@ -0,0 +1,27 @@
+// Package snowflake 实现twitter的雪花算法，生成按时间递增的63位整数id。
+//
+// # id结构
+//
+//	0 - 41位时间戳 - 5位数据id - 5位机器id - 12位毫秒内序列
+//
+// 最高位固定为0，id总是正数。
+//
+// # 容量
+//
+// 节点：数据id和机器id各5位，最多 MaxDatacenters(32) 个数据中心，
+// 每个数据中心最多 MaxWorkersPerDatacenter(32) 个生成器，同时生成id的生成器不能超过
+// MaxConcurrentGenerators(1024) 个。两个节点相同的生成器会生成重复的id，
+// 同一进程内由 New 检查，跨进程需要由部署保证，或使用 consul 等子包分配。
+// 使用 SnowflakePool 时池的大小即为一个数据中心的 MaxWorkersPerDatacenter。
+//
+// 时间：41位毫秒时间戳从起始时间开始可以使用约69年，默认起始时间为2020-01-01，
+// 之后需要通过 WithEpoch 更换起始时间，可以用 ExpiresAt、IsExpiringSoon 提前检查。
+//
+// 吞吐：12位毫秒内序列使每个节点每毫秒最多生成4096个id，用尽后等待下一毫秒。
+// WithVersionBits 会减少毫秒内序列的位数，WithMaxSequence 可以进一步限制每毫秒的个数。
+//
+// 时间戳的精度在创建时确定，WithTimeUnit 只能使用1毫秒或更粗的单位，不支持运行时在毫秒和微秒之间自动切换：
+// 41位的微秒时间戳只能使用约25天，加宽时间戳需要占用节点和序列的位；切换前后的id无法保持递增，
+// 解析时也需要额外的标记位区分。单个节点的吞吐不够时，使用 SnowflakePool、Pool 分散到多个机器id，
+// 或使用 NextIdN、NextIds 批量分配。
+package snowflake
@@ -0,0 +1,81 @@
+package snowflake
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// driftEventBuffer 等待输出的时钟回退事件个数，超出时丢弃新的事件
+const driftEventBuffer = 64
+
+// driftEvent 一次时钟回退事件，按JSON行输出
+type driftEvent struct {
+	Event     string `json:"event"`
+	SkewMs    int64  `json:"skew_ms"`
+	LastTs    int64  `json:"last_ts"`
+	CurrentTs int64  `json:"current_ts"`
+	Timestamp string `json:"timestamp"`
+
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// WithDriftMonitor 时钟回退超过threshold时，向w写入一行JSON：
+//
+//	{"event":"clock_drift","skew_ms":N,"last_ts":M,"current_ts":K,"timestamp":"..."}
+//
+// 写入在后台goroutine中进行，不会阻塞id的生成，来不及写入的事件会被丢弃。
+// 调用 Close 停止后台goroutine。
+func WithDriftMonitor(w io.Writer, threshold time.Duration) Option {
+	return func(s *Snowflake) error {
+		if w == nil {
+			return fmt.Errorf("drift monitor writer can't be nil")
+		}
+		if threshold < 0 {
+			return fmt.Errorf("drift threshold can't be negative")
+		}
+		s.driftWriter = w
+		s.driftThreshold = threshold.Milliseconds()
+		return nil
+	}
+}
+
+func (s *Snowflake) startDriftMonitor() {
+	events := make(chan driftEvent, driftEventBuffer)
+	s.driftEvents = events
+	go func() {
+		enc := json.NewEncoder(s.driftWriter)
+		for e := range events {
+			enc.Encode(e)
+		}
+	}()
+}
+
+// reportDrift 记录回退到timestamp的时钟回退事件，调用方需持有锁
+func (s *Snowflake) reportDrift(timestamp int64) {
+	skew := s.lastTimestamp - timestamp
+	if s.driftEvents == nil || skew <= s.driftThreshold {
+		return
+	}
+	e := driftEvent{
+		Event:     "clock_drift",
+		SkewMs:    skew,
+		LastTs:    s.lastTimestamp,
+		CurrentTs: timestamp,
+		Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
+		Metadata:  s.metadata,
+	}
+	select {
+	case s.driftEvents <- e:
+	default: // 队列已满，丢弃
+	}
+}
+
+// stopDriftMonitor 停止输出时钟回退事件，调用方需持有锁
+func (s *Snowflake) stopDriftMonitor() {
+	if s.driftEvents != nil {
+		close(s.driftEvents)
+		s.driftEvents = nil
+	}
+}
@@ -0,0 +1,113 @@
+package snowflake
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lockedBuffer 可以并发读写的缓冲区
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestWithDriftMonitor(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 10000))
+	var out lockedBuffer
+	sf, err := NewUnregistered(1, 1, WithClock(clock), WithDriftMonitor(&out, 5*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+
+	if _, err := sf.NextId(); err != nil {
+		t.Fatal(err)
+	}
+	// 未超过阈值，不输出
+	clock.Add(-5 * time.Millisecond)
+	if _, err := sf.NextId(); err == nil {
+		t.Fatal("expected clock backwards error")
+	}
+	clock.Add(-2 * time.Millisecond)
+	if _, err := sf.NextId(); err == nil {
+		t.Fatal("expected clock backwards error")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for out.String() == "" && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	var e map[string]interface{}
+	if err := json.Unmarshal([]byte(out.String()), &e); err != nil {
+		t.Fatalf("output %q: %v", out.String(), err)
+	}
+	if e["event"] != "clock_drift" || e["skew_ms"] != float64(7) ||
+		e["last_ts"] != float64(twepoch+10000) || e["current_ts"] != float64(twepoch+9993) {
+		t.Errorf("event = %v", e)
+	}
+	if _, err := time.Parse(time.RFC3339Nano, e["timestamp"].(string)); err != nil {
+		t.Errorf("timestamp: %v", err)
+	}
+}
+
+// blockingWriter 写入时一直阻塞，直到release被关闭
+type blockingWriter struct {
+	release chan struct{}
+}
+
+func (w blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	return len(p), nil
+}
+
+func TestWithDriftMonitor_DoesNotBlock(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 10000))
+	w := blockingWriter{release: make(chan struct{})}
+	defer close(w.release)
+	sf, err := NewUnregistered(1, 1, WithClock(clock), WithDriftMonitor(w, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+	if _, err := sf.NextId(); err != nil {
+		t.Fatal(err)
+	}
+	clock.Add(-time.Second)
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < driftEventBuffer*4; i++ {
+			sf.NextId()
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("NextId blocked on drift monitor")
+	}
+}
+
+func TestWithDriftMonitor_Invalid(t *testing.T) {
+	if _, err := NewUnregistered(1, 1, WithDriftMonitor(nil, 0)); err == nil {
+		t.Error("nil writer expected error")
+	}
+	if _, err := NewUnregistered(1, 1, WithDriftMonitor(&lockedBuffer{}, -time.Millisecond)); err == nil {
+		t.Error("negative threshold expected error")
+	}
+}
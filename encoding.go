@@ -0,0 +1,171 @@
+package snowflake
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz" // 比特币使用的字母表，去掉了 0 O I l
+)
+
+// EncodeBase62 将id编码为base62字符串，负数id按无符号数编码。
+// 编码结果只包含数字和字母，可以直接用在URL中，DecodeBase62 可以还原
+func EncodeBase62(id int64) string {
+	return encodeBase(id, base62Alphabet)
+}
+
+// DecodeBase62 将 EncodeBase62 编码的字符串还原为id
+func DecodeBase62(s string) (int64, error) {
+	return decodeBase(s, base62Alphabet, "base62")
+}
+
+// EncodeBase58 将id编码为base58字符串，不包含容易混淆的 0 O I l，负数id按无符号数编码
+func EncodeBase58(id int64) string {
+	return encodeBase(id, base58Alphabet)
+}
+
+// DecodeBase58 将 EncodeBase58 编码的字符串还原为id
+func DecodeBase58(s string) (int64, error) {
+	return decodeBase(s, base58Alphabet, "base58")
+}
+
+// EncodeBase32 将id编码为Crockford Base32字符串（大写），负数id按无符号数编码
+func EncodeBase32(id int64) string {
+	return encodeBase(id, crockford32Alphabet)
+}
+
+// DecodeBase32 将 EncodeBase32 编码的字符串还原为id，不区分大小写
+func DecodeBase32(s string) (int64, error) {
+	return decodeBase(strings.ToUpper(s), crockford32Alphabet, "base32")
+}
+
+// EncodeHex 将id编码为小写的十六进制字符串，不补齐位数，负数id按无符号数编码
+func EncodeHex(id int64) string {
+	return strconv.FormatUint(uint64(id), 16)
+}
+
+// DecodeHex 将 EncodeHex 编码的字符串还原为id，不区分大小写
+func DecodeHex(s string) (int64, error) {
+	n, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid hex snowflake id %q: %w", s, err)
+	}
+	return int64(n), nil
+}
+
+// Base62 见 EncodeBase62
+func (id ID) Base62() string {
+	return EncodeBase62(int64(id))
+}
+
+// Base58 见 EncodeBase58
+func (id ID) Base58() string {
+	return EncodeBase58(int64(id))
+}
+
+// Base32 见 EncodeBase32
+func (id ID) Base32() string {
+	return EncodeBase32(int64(id))
+}
+
+// Hex 见 EncodeHex
+func (id ID) Hex() string {
+	return EncodeHex(int64(id))
+}
+
+// encodeBase 按字母表将id作为无符号数编码
+func encodeBase(id int64, alphabet string) string {
+	n := uint64(id)
+	if n == 0 {
+		return alphabet[:1]
+	}
+	base := uint64(len(alphabet))
+	var buf [64]byte
+	i := len(buf)
+	for n > 0 {
+		i--
+		buf[i] = alphabet[n%base]
+		n /= base
+	}
+	return string(buf[i:])
+}
+
+// decodeBase 按字母表解析 encodeBase 编码的字符串，超过64位无符号数时返回错误
+func decodeBase(s string, alphabet string, name string) (int64, error) {
+	if s == "" {
+		return 0, fmt.Errorf("empty %s string", name)
+	}
+	base := uint64(len(alphabet))
+	n := uint64(0)
+	for i := 0; i < len(s); i++ {
+		d := strings.IndexByte(alphabet, s[i])
+		if d < 0 {
+			return 0, fmt.Errorf("invalid %s character %q", name, s[i])
+		}
+		if n > (math.MaxUint64-uint64(d))/base {
+			return 0, fmt.Errorf("%s value overflows 64 bits", name)
+		}
+		n = n*base + uint64(d)
+	}
+	return int64(n), nil
+}
+
+// crockford32Alphabet Crockford Base32 字母表，去掉了容易混淆的 I L O U
+const crockford32Alphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+const fingerprintBits = 30
+
+// Fingerprint 将id的低30位编码为6个字符的Crockford Base32短码，方便在电话沟通中口头传递。
+// 短码不唯一：低30位相同的id会得到相同的短码，只能用于辅助确认，不能代替完整id。
+func (id ID) Fingerprint() string {
+	n := int64(id) & (1<<fingerprintBits - 1)
+	var buf [fingerprintBits / 5]byte
+	for i := len(buf) - 1; i >= 0; i-- {
+		buf[i] = crockford32Alphabet[n&0x1f]
+		n >>= 5
+	}
+	return string(buf[:])
+}
+
+// ParseFingerprint 解析 Fingerprint 生成的短码，得到id的低30位。
+// 不区分大小写，按Crockford规则将 I、L 视为 1，O 视为 0。
+func ParseFingerprint(fp string) (partialID int64, err error) {
+	if len(fp) != fingerprintBits/5 {
+		return 0, fmt.Errorf("fingerprint %q must be %d characters", fp, fingerprintBits/5)
+	}
+	for i := 0; i < len(fp); i++ {
+		c := fp[i]
+		if c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		switch c {
+		case 'I', 'L':
+			c = '1'
+		case 'O':
+			c = '0'
+		}
+		v := strings.IndexByte(crockford32Alphabet, c)
+		if v < 0 {
+			return 0, fmt.Errorf("invalid character %q in fingerprint %q", fp[i], fp)
+		}
+		partialID = partialID<<5 | int64(v)
+	}
+	return partialID, nil
+}
+
+// Fingerprint32 用murmur3的64位混合函数对完整的id做哈希，取32位作为审计日志中的短标识。
+// 与 Fingerprint 不同，结果分布均匀、相邻的id也互不相关，适合用于布隆过滤器等只能保存短标识的场景；
+// 不同id仍可能得到相同的值，约7.7万个id中出现碰撞的概率为50%。
+func Fingerprint32(id int64) uint32 {
+	h := uint64(id)
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return uint32(h>>32) ^ uint32(h)
+}
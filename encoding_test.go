@@ -0,0 +1,155 @@
+package snowflake
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncodeBase62(t *testing.T) {
+	cases := map[int64]string{
+		0:       "0",
+		61:      "z",
+		62:      "10",
+		3843:    "zz",
+		1 << 62: "5UfZOVH2ZO4",
+	}
+	for id, want := range cases {
+		if got := EncodeBase62(id); got != want {
+			t.Errorf("EncodeBase62(%d) = %q, want %q", id, got, want)
+		}
+	}
+}
+
+func TestID_Fingerprint(t *testing.T) {
+	id := ID(0x7abc<<30 | 0x2345678)
+	fp := id.Fingerprint()
+	if len(fp) != 6 {
+		t.Fatalf("Fingerprint() = %q, want 6 characters", fp)
+	}
+	for _, s := range []string{fp, strings.ToLower(fp)} {
+		partial, err := ParseFingerprint(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if partial != 0x2345678 {
+			t.Errorf("ParseFingerprint(%q) = %#x, want %#x", s, partial, 0x2345678)
+		}
+	}
+
+	// 高位不同、低30位相同的id短码相同
+	if other := ID(0x1234<<30 | 0x2345678); other.Fingerprint() != fp {
+		t.Errorf("Fingerprint() of ids sharing the low 30 bits differ: %q %q", other.Fingerprint(), fp)
+	}
+
+	if got, err := ParseFingerprint("0OIL1i"); err != nil || got != 0<<25|0<<20|1<<15|1<<10|1<<5|1 {
+		t.Errorf("ParseFingerprint(\"0OIL1i\") = %d, %v", got, err)
+	}
+	for _, s := range []string{"", "ABCDE", "ABCDEFG", "ABCDU0"} {
+		if _, err := ParseFingerprint(s); err == nil {
+			t.Errorf("ParseFingerprint(%q) expected error", s)
+		}
+	}
+}
+
+func TestFingerprint32(t *testing.T) {
+	sf, err := NewUnregistered(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const samples = 200000
+	const buckets = 256
+	var counts [buckets]int
+	var bits [32]int
+	seen := make(map[uint32]bool, samples)
+	collisions := 0
+	for i := 0; i < samples; i++ {
+		id, err := sf.NextId() // 连续的id只有低位不同，最能暴露分布问题
+		if err != nil {
+			t.Fatal(err)
+		}
+		fp := Fingerprint32(id)
+		if fp != Fingerprint32(id) {
+			t.Fatal("Fingerprint32 is not deterministic")
+		}
+		if seen[fp] {
+			collisions++
+		}
+		seen[fp] = true
+		counts[fp%buckets]++
+		for b := 0; b < 32; b++ {
+			bits[b] += int(fp >> b & 1)
+		}
+	}
+
+	// 卡方检验，自由度255，p=0.001时临界值约为330
+	expected := float64(samples) / buckets
+	chi2 := 0.0
+	for _, c := range counts {
+		d := float64(c) - expected
+		chi2 += d * d / expected
+	}
+	if chi2 > 330 {
+		t.Errorf("chi-square = %.1f, distribution is not uniform", chi2)
+	}
+	// 每一位为1的比例接近一半
+	for b, n := range bits {
+		if r := float64(n) / samples; r < 0.49 || r > 0.51 {
+			t.Errorf("bit %d is set in %.3f of fingerprints", b, r)
+		}
+	}
+	// 20万个样本期望约4.7次碰撞
+	if collisions > 20 {
+		t.Errorf("%d collisions in %d samples", collisions, samples)
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	codecs := map[string]struct {
+		encode func(int64) string
+		decode func(string) (int64, error)
+	}{
+		"base62": {EncodeBase62, DecodeBase62},
+		"base58": {EncodeBase58, DecodeBase58},
+		"base32": {EncodeBase32, DecodeBase32},
+		"hex":    {EncodeHex, DecodeHex},
+	}
+	ids := []int64{0, 1, 57, 1 << 40, 1<<63 - 1, -1}
+	for name, c := range codecs {
+		for _, id := range ids {
+			s := c.encode(id)
+			got, err := c.decode(s)
+			if err != nil || got != id {
+				t.Errorf("%s: decode(%q) = %d, %v, want %d", name, s, got, err, id)
+			}
+		}
+		if _, err := c.decode(""); err == nil {
+			t.Errorf("%s: decode(\"\") expected error", name)
+		}
+		if _, err := c.decode("!"); err == nil {
+			t.Errorf("%s: decode(\"!\") expected error", name)
+		}
+	}
+
+	if got := EncodeBase58(57); got != "z" {
+		t.Errorf("EncodeBase58(57) = %q, want \"z\"", got)
+	}
+	if got := EncodeHex(255); got != "ff" {
+		t.Errorf("EncodeHex(255) = %q, want \"ff\"", got)
+	}
+	if got, err := DecodeBase32("zz"); err != nil || got != 1023 {
+		t.Errorf("DecodeBase32(\"zz\") = %d, %v, want 1023", got, err)
+	}
+	if _, err := DecodeBase62("zzzzzzzzzzzz"); err == nil || !strings.Contains(err.Error(), "overflows") {
+		t.Errorf("DecodeBase62 overflow error = %v", err)
+	}
+}
+
+func TestID_String(t *testing.T) {
+	id := ID(1234567890123)
+	if id.String() != "1234567890123" || id.Hex() != EncodeHex(1234567890123) || id.Base58() != EncodeBase58(1234567890123) {
+		t.Errorf("ID methods don't match the encoders")
+	}
+	if back, err := ParseString(id.String()); err != nil || back != id {
+		t.Errorf("ParseString(%q) = %d, %v", id.String(), back, err)
+	}
+}
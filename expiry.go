@@ -0,0 +1,41 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTimestampOverflow 到达 ExpiresAt 之后，时间戳超出了布局所能表示的范围，
+// 继续生成的id会覆盖符号位，因此返回该错误而不是生成id
+var ErrTimestampOverflow = errors.New("snowflake timestamp overflows the bit layout")
+
+// ExpiresAt 时间戳用尽的时间，即起始时间加上毫秒时间戳所能表示的时长，默认的41位约为69年，
+// 之后生成的id会溢出，需要在此之前更换起始时间
+func (s *Snowflake) ExpiresAt() time.Time {
+	return time.UnixMilli(s.expiresAt())
+}
+
+// expiresAt 时间戳用尽的时间(unix时间戳/毫秒)
+func (s *Snowflake) expiresAt() int64 {
+	return s.epoch + (s.timestampMax+1)*s.layout.unit()
+}
+
+// checkOverflow timestamp超出时间戳的表示范围时返回 ErrTimestampOverflow
+func (s *Snowflake) checkOverflow(timestamp int64) error {
+	if timestamp >= s.expiresAt() {
+		return fmt.Errorf("%w: epoch %v expired at %v", ErrTimestampOverflow,
+			time.UnixMilli(s.epoch), time.UnixMilli(s.expiresAt()))
+	}
+	return nil
+}
+
+// IsExpired 当前时间是否已经到达 ExpiresAt
+func (s *Snowflake) IsExpired() bool {
+	return !s.clock.Now().Before(s.ExpiresAt())
+}
+
+// IsExpiringSoon 距离 ExpiresAt 是否不足threshold，用于提前规划起始时间的迁移
+func (s *Snowflake) IsExpiringSoon(threshold time.Duration) bool {
+	return s.ExpiresAt().Sub(s.clock.Now()) <= threshold
+}
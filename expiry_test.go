@@ -0,0 +1,89 @@
+package snowflake
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestExpiry(t *testing.T) {
+	expires := time.UnixMilli(twepoch + maxTimestamp + 1)
+	clock := newFakeClock(expires.Add(-48 * time.Hour))
+	sf, err := NewUnregistered(1, 1, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sf.ExpiresAt().Equal(expires) {
+		t.Errorf("ExpiresAt() = %v, want %v", sf.ExpiresAt(), expires)
+	}
+	if y := sf.ExpiresAt().Sub(time.UnixMilli(twepoch)).Hours() / 24 / 365.25; y < 69 || y >= 70 {
+		t.Errorf("epoch lasts %.2f years, want 69", y)
+	}
+
+	if sf.IsExpired() {
+		t.Error("IsExpired() = true two days before expiry")
+	}
+	if sf.IsExpiringSoon(24 * time.Hour) {
+		t.Error("IsExpiringSoon(24h) = true two days before expiry")
+	}
+	if !sf.IsExpiringSoon(72 * time.Hour) {
+		t.Error("IsExpiringSoon(72h) = false two days before expiry")
+	}
+
+	clock.Add(48*time.Hour - time.Millisecond)
+	if sf.IsExpired() {
+		t.Error("IsExpired() = true 1ms before expiry")
+	}
+	clock.Add(time.Millisecond)
+	if !sf.IsExpired() || !sf.IsExpiringSoon(0) {
+		t.Error("IsExpired() = false at expiry")
+	}
+
+	// 自定义起始时间
+	epoch := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	custom, err := NewUnregistered(1, 1, WithEpoch(epoch))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := epoch.Add(time.Duration(maxTimestamp+1) * time.Millisecond); !custom.ExpiresAt().Equal(want) {
+		t.Errorf("ExpiresAt() = %v, want %v", custom.ExpiresAt(), want)
+	}
+	if custom.IsExpired() {
+		t.Error("new epoch already expired")
+	}
+}
+
+func TestTimestampOverflow(t *testing.T) {
+	expires := time.UnixMilli(twepoch + maxTimestamp + 1)
+	clock := newFakeClock(expires.Add(-time.Millisecond))
+	sf, err := NewUnregistered(1, 1, WithClock(clock), WithMaxSequence(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := sf.NextId()
+	if err != nil || id < 0 {
+		t.Fatalf("last millisecond: %d, %v", id, err)
+	}
+	// 序列用尽时不等待超出范围的下一毫秒
+	if _, err := sf.NextId(); !errors.Is(err, ErrTimestampOverflow) {
+		t.Errorf("exhausted last millisecond: %v, want ErrTimestampOverflow", err)
+	}
+	clock.Add(time.Hour)
+	if _, err := sf.NextId(); !errors.Is(err, ErrTimestampOverflow) {
+		t.Errorf("after expiry: %v, want ErrTimestampOverflow", err)
+	}
+
+	// 每个生成器使用自己的起始时间
+	epoch := time.Now().AddDate(-70, 0, 0)
+	old, err := NewUnregistered(1, 1, WithEpoch(epoch))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := old.NextId(); !errors.Is(err, ErrTimestampOverflow) {
+		t.Errorf("epoch 70 years ago: %v, want ErrTimestampOverflow", err)
+	}
+	recent, _ := NewUnregistered(1, 1, WithEpoch(time.Now().AddDate(-1, 0, 0)))
+	if _, err := recent.NextId(); err != nil {
+		t.Errorf("epoch 1 year ago: %v", err)
+	}
+}
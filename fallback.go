@@ -0,0 +1,23 @@
+package snowflake
+
+import (
+	"context"
+	"log"
+	"log/slog"
+)
+
+// NextIdOrDefault 生成id，出错时记录日志并返回defaultValue，不会panic。
+// 用于生成日志关联id等允许失败的非关键场景。使用 WithLogger 时以Warn级别输出，否则使用 log.Printf。
+func (s *Snowflake) NextIdOrDefault(defaultValue int64) int64 {
+	id, err := s.NextId()
+	if err != nil {
+		if s.logger == nil {
+			log.Printf("snowflake: generate id failed, using default %d: %v", defaultValue, err)
+			return defaultValue
+		}
+		attrs := append([]slog.Attr{slog.Int64("default", defaultValue), slog.String("error", err.Error())}, s.nodeAttrs()...)
+		s.logger.LogAttrs(context.Background(), slog.LevelWarn, "generate id failed, using default", attrs...)
+		return defaultValue
+	}
+	return id
+}
@@ -0,0 +1,68 @@
+package snowflake
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNextIdOrDefault(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, err := NewUnregistered(1, 1, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := sf.NextIdOrDefault(-1)
+	if id <= 0 {
+		t.Fatalf("NextIdOrDefault = %d", id)
+	}
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	clock.Add(-time.Second)
+	if got := sf.NextIdOrDefault(-1); got != -1 {
+		t.Errorf("NextIdOrDefault after clock moved backwards = %d, want -1", got)
+	}
+	if !strings.Contains(logs.String(), "using default -1") || !strings.Contains(logs.String(), "Clock moved backwards") {
+		t.Errorf("log = %q", logs.String())
+	}
+}
+
+func TestNextIdOrDefault_Logger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, err := NewUnregistered(3, 4, WithClock(clock), WithLogger(logger))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sf.NextId()
+	buf.Reset()
+
+	var std bytes.Buffer
+	log.SetOutput(&std)
+	defer log.SetOutput(os.Stderr)
+
+	clock.Add(-time.Second)
+	if got := sf.NextIdOrDefault(-1); got != -1 {
+		t.Errorf("NextIdOrDefault after clock moved backwards = %d, want -1", got)
+	}
+	var rec map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("log %q: %v", buf.String(), err)
+	}
+	if rec["level"] != "WARN" || rec["default"] != float64(-1) || rec["worker_id"] != float64(3) ||
+		rec["datacenter_id"] != float64(4) || !strings.Contains(rec["error"].(string), "Clock moved backwards") {
+		t.Errorf("log record = %v", rec)
+	}
+	if std.Len() != 0 {
+		t.Errorf("standard log = %q, want nothing", std.String())
+	}
+}
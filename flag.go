@@ -0,0 +1,61 @@
+package snowflake
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// IDFlag 实现 flag.Value，用于在命令行参数中传入id：
+//
+//	var after snowflake.IDFlag
+//	flag.Var(&after, "start-after", "start processing after this snowflake ID")
+//
+// 只包含数字的参数按十进制解析，否则按 EncodeBase62 的格式解析，
+// 因此只由数字组成的base62字符串需要换算成十进制传入。
+type IDFlag struct {
+	ID
+}
+
+// Set 解析十进制或base62字符串
+func (f *IDFlag) Set(s string) error {
+	if isDecimal(s) {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid snowflake id %q: decimal value out of range", s)
+		}
+		f.ID = ID(n)
+		return nil
+	}
+	if len(s) > 0 && s[0] == '-' {
+		return fmt.Errorf("invalid snowflake id %q: ids can't be negative", s)
+	}
+	n, err := DecodeBase62(s)
+	if err != nil {
+		return fmt.Errorf("invalid snowflake id %q: not a decimal number, and %v", s, err)
+	}
+	if n < 0 {
+		return fmt.Errorf("invalid snowflake id %q: ids can't be negative", s)
+	}
+	f.ID = ID(n)
+	return nil
+}
+
+// String 十进制输出
+func (f *IDFlag) String() string {
+	if f == nil {
+		return "0"
+	}
+	return strconv.FormatInt(int64(f.ID), 10)
+}
+
+func isDecimal(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
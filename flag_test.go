@@ -0,0 +1,46 @@
+package snowflake
+
+import (
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestIDFlag(t *testing.T) {
+	var f IDFlag
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(&f, "start-after", "start processing after this snowflake ID")
+
+	id := int64(1<<40 | 12345)
+	if err := fs.Parse([]string{"-start-after", "1099511640121"}); err != nil {
+		t.Fatal(err)
+	}
+	if int64(f.ID) != id || f.String() != "1099511640121" {
+		t.Errorf("decimal: got %d (%s), want %d", f.ID, f.String(), id)
+	}
+
+	if err := f.Set(EncodeBase62(id)); err != nil {
+		t.Fatal(err)
+	}
+	if int64(f.ID) != id {
+		t.Errorf("base62: got %d, want %d", f.ID, id)
+	}
+
+	cases := map[string]string{
+		"":                     "empty",
+		"-5":                   "negative",
+		"12ab!":                "invalid base62 character",
+		"99999999999999999999": "out of range",
+		"zzzzzzzzzzzz":         "overflows",
+	}
+	for in, reason := range cases {
+		err := f.Set(in)
+		if err == nil {
+			t.Errorf("Set(%q) expected error", in)
+			continue
+		}
+		if !strings.Contains(err.Error(), reason) {
+			t.Errorf("Set(%q) = %q, want reason %q", in, err, reason)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package snowflake
+
+import "time"
+
+// ForecastResult 生成能力的估算结果
+type ForecastResult struct {
+	MaxIDs                  int64     // 给定时长内最多能生成的id数量
+	PerMillisecond          int64     // 每毫秒最多能生成的id数量
+	EpochExpiresAt          time.Time // 时间戳用尽的时间
+	MillisecondsUntilExpiry int64     // 距时间戳用尽还有多少毫秒
+}
+
+// Forecast 根据生成器的配置估算d时长内最多能生成多少id，不会生成id，也不会修改生成器的状态
+func (s *Snowflake) Forecast(d time.Duration) ForecastResult {
+	perUnit := s.maxSequence + 1
+	expiresAt := s.expiresAt()
+	untilExpiry := expiresAt - s.timeGen()
+	if untilExpiry < 0 {
+		untilExpiry = 0
+	}
+
+	ms := d.Milliseconds()
+	if ms > untilExpiry {
+		ms = untilExpiry
+	}
+	if ms < 0 {
+		ms = 0
+	}
+	return ForecastResult{
+		MaxIDs:                  ms / s.layout.unit() * perUnit,
+		PerMillisecond:          perUnit / s.layout.unit(),
+		EpochExpiresAt:          time.UnixMilli(expiresAt),
+		MillisecondsUntilExpiry: untilExpiry,
+	}
+}
@@ -0,0 +1,43 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSnowflake_Forecast(t *testing.T) {
+	sf, err := New(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+	f := sf.Forecast(time.Second)
+	if f.PerMillisecond != 4096 {
+		t.Errorf("PerMillisecond = %d, want 4096", f.PerMillisecond)
+	}
+	if f.MaxIDs != 4096*1000 {
+		t.Errorf("MaxIDs = %d, want %d", f.MaxIDs, 4096*1000)
+	}
+	// 2020-01-01 + 2^41 毫秒
+	wantExpiry := time.UnixMilli(1577808000000 + 1<<41)
+	if !f.EpochExpiresAt.Equal(wantExpiry) {
+		t.Errorf("EpochExpiresAt = %v, want %v", f.EpochExpiresAt, wantExpiry)
+	}
+	if until := time.Until(wantExpiry).Milliseconds(); f.MillisecondsUntilExpiry < until-1000 || f.MillisecondsUntilExpiry > until+1000 {
+		t.Errorf("MillisecondsUntilExpiry = %d, want about %d", f.MillisecondsUntilExpiry, until)
+	}
+
+	// 超过有效期的部分不计入
+	if f := sf.Forecast(200 * 365 * 24 * time.Hour); f.MaxIDs != f.MillisecondsUntilExpiry*4096 {
+		t.Errorf("MaxIDs = %d, want capped at %d", f.MaxIDs, f.MillisecondsUntilExpiry*4096)
+	}
+
+	versioned, err := New(1, 0, WithVersionBits(4, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer versioned.Close()
+	if f := versioned.Forecast(time.Millisecond); f.PerMillisecond != 256 || f.MaxIDs != 256 {
+		t.Errorf("versioned Forecast = %+v, want 256 per millisecond", f)
+	}
+}
@@ -0,0 +1,98 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrForked = errors.New("operation not supported on a forked snowflake generator")
+
+// ForkSequence 把生成器的毫秒内序列分给stride个新的生成器，用于单节点升级为多节点时共用同一个节点：
+// 第k个生成器只使用序列 k, k+stride, k+2*stride, ...，例如stride为2时分为偶数和奇数两个生成器。
+// 新的生成器继承节点、起始时间、时间源等配置以及上一次生成id的时间戳，从下一毫秒开始生成，
+// 不会与s已经生成的id重复；WithDriftMonitor、WithSlidingWindowLimit、WithBucketedWindowLimit、WithSamplingCallback 不会继承。
+// 分叉后s被 Shutdown，但仍占用节点，所有新的生成器都不再使用时再调用s的 Close。
+// 新的生成器每毫秒最多生成 (maxSequence+1)/stride 个id，不支持 NextIdN、GenerateBlock 和 NextIdWithPriority。
+func (s *Snowflake) ForkSequence(stride int64) ([]*Snowflake, error) {
+	if s.seqStride != 0 {
+		return nil, ErrForked
+	}
+	if s.stateStore != nil {
+		return nil, fmt.Errorf("generators with a state store can't be forked")
+	}
+	if s.randomStart != 0 {
+		return nil, fmt.Errorf("generators with a random sequence start can't be forked")
+	}
+	if stride < 2 || stride > s.maxSequence+1 {
+		return nil, fmt.Errorf("fork stride must be between 2 and %d", s.maxSequence+1)
+	}
+	if s.shutdown.Load() {
+		return nil, ErrShutdown
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.shutdown.Load() {
+		return nil, ErrShutdown
+	}
+	s.shutdown.Store(true)
+
+	forks := make([]*Snowflake, stride)
+	for k := range forks {
+		forks[k] = &Snowflake{
+			lastTimestamp: s.lastTimestamp,
+			workerId:      s.workerId,
+			datacenterId:  s.datacenterId,
+			sequence:      s.maxSequence, // 上一毫秒已用尽，从下一毫秒开始
+
+			sequenceMask:          s.sequenceMask,
+			maxSequence:           s.maxSequence,
+			versionBits:           s.versionBits,
+			datacenterVersionBits: s.datacenterVersionBits,
+			versionValue:          s.versionValue,
+			version:               s.version,
+
+			layout:          s.layout,
+			workerShift:     s.workerShift,
+			datacenterShift: s.datacenterShift,
+			timestampShift:  s.timestampShift,
+			timestampMax:    s.timestampMax,
+
+			epoch:        s.epoch,
+			clock:        s.clock,
+			hasher:       s.hasher,
+			littleEndian: s.littleEndian,
+
+			breakerThreshold: s.breakerThreshold,
+			breakerCooldown:  s.breakerCooldown,
+
+			advancedUntil: s.advancedUntil,
+			rollbackWait:  s.rollbackWait,
+			logicalClock:  s.logicalClock,
+			randomStep:    s.randomStep,
+			borrowLead:    s.borrowLead,
+			metadata:      s.metadata,
+			logger:        s.logger,
+			sleep:         s.sleep,
+			timeBoxUntil:  s.timeBoxUntil,
+			decorators:    s.decorators,
+
+			seqOffset: int64(k),
+			seqStride: stride,
+		}
+	}
+	return forks, nil
+}
+
+// nextSequence 大于sequence的下一个可用序列，分叉的生成器只使用与seqOffset同余的序列
+func (s *Snowflake) nextSequence(sequence int64) int64 {
+	next := sequence + 1
+	if s.seqStride == 0 {
+		return next
+	}
+	if r := (next - s.seqOffset) % s.seqStride; r > 0 {
+		next += s.seqStride - r
+	} else if r < 0 {
+		next -= r
+	}
+	return next
+}
@@ -0,0 +1,88 @@
+package snowflake
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestForkSequence(t *testing.T) {
+	sf, err := New(9, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+	before, err := sf.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	forks, err := sf.ForkSequence(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(forks) != 3 {
+		t.Fatalf("got %d forks, want 3", len(forks))
+	}
+	if _, err := sf.NextId(); !errors.Is(err, ErrShutdown) {
+		t.Errorf("parent NextId after fork = %v, want ErrShutdown", err)
+	}
+	if _, err := New(9, 9); !errors.Is(err, ErrDuplicateNode) {
+		t.Errorf("New with forked node = %v, want ErrDuplicateNode", err)
+	}
+
+	const perFork = 20000
+	var mu sync.Mutex
+	seen := map[int64]bool{before: true}
+	var wg sync.WaitGroup
+	for k, f := range forks {
+		wg.Add(1)
+		go func(k int64, f *Snowflake) {
+			defer wg.Done()
+			ids := make([]int64, perFork)
+			for i := range ids {
+				var err error
+				if ids[i], err = f.NextId(); err != nil {
+					t.Error(err)
+					return
+				}
+				if ids[i] <= before {
+					t.Errorf("fork %d id %d not after parent id %d", k, ids[i], before)
+					return
+				}
+				if seq := ID(ids[i]).Parse().Sequence(); seq%3 != k {
+					t.Errorf("fork %d generated sequence %d", k, seq)
+					return
+				}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, id := range ids {
+				if seen[id] {
+					t.Errorf("duplicate id %d", id)
+				}
+				seen[id] = true
+			}
+		}(int64(k), f)
+	}
+	wg.Wait()
+
+	if _, _, err := forks[0].NextIdN(2); !errors.Is(err, ErrForked) {
+		t.Errorf("NextIdN on fork = %v, want ErrForked", err)
+	}
+	if _, err := forks[0].ForkSequence(2); !errors.Is(err, ErrForked) {
+		t.Errorf("ForkSequence on fork = %v, want ErrForked", err)
+	}
+	if _, err := sf.ForkSequence(2); !errors.Is(err, ErrShutdown) {
+		t.Errorf("ForkSequence twice = %v, want ErrShutdown", err)
+	}
+	other, err := NewUnregistered(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stride := range []int64{0, 1, 4097} {
+		if _, err := other.ForkSequence(stride); err == nil {
+			t.Errorf("ForkSequence(%d) expected error", stride)
+		}
+	}
+}
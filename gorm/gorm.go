@@ -0,0 +1,93 @@
+// Package gorm 提供GORM插件，插入记录时自动为主键填充雪花id。
+// 模型的主键字段可以直接使用 snowflake.ID，它实现了 sql.Scanner 和 driver.Valuer，按bigint读写。
+package gorm
+
+import (
+	"reflect"
+
+	gormlib "gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"github.com/pangush/snowflake"
+)
+
+// Generator 生成id，*snowflake.Snowflake 和 *snowflake.Pool 都实现了该接口
+type Generator interface {
+	NextId() (int64, error)
+}
+
+// Plugin 在 gorm:create 之前为值为零的整数主键填充id，已经设置的主键保持不变。
+// 支持 int64、uint64 及以它们为底层类型的主键（如 snowflake.ID），批量插入时逐条填充
+type Plugin struct {
+	gen Generator
+}
+
+var (
+	_ gormlib.Plugin = (*Plugin)(nil)
+	_ Generator      = (*snowflake.Snowflake)(nil)
+	_ Generator      = (*snowflake.Pool)(nil)
+)
+
+// New 创建使用gen生成id的插件，通过 db.Use(gorm.New(gen)) 注册
+func New(gen Generator) *Plugin {
+	return &Plugin{gen: gen}
+}
+
+// Name 实现 gorm.Plugin
+func (p *Plugin) Name() string {
+	return "snowflake"
+}
+
+// Initialize 实现 gorm.Plugin，注册创建记录前的回调
+func (p *Plugin) Initialize(db *gormlib.DB) error {
+	return db.Callback().Create().Before("gorm:create").Register("snowflake:assign_id", p.assignIds)
+}
+
+// assignIds 为语句中所有记录的零值主键填充id
+func (p *Plugin) assignIds(db *gormlib.DB) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return
+	}
+	var fields []*schema.Field
+	for _, f := range db.Statement.Schema.PrimaryFields {
+		if k := f.FieldType.Kind(); k == reflect.Int64 || k == reflect.Uint64 {
+			fields = append(fields, f)
+		}
+	}
+	if len(fields) == 0 {
+		return
+	}
+
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if err := p.assign(db, fields, reflect.Indirect(rv.Index(i))); err != nil {
+				db.AddError(err)
+				return
+			}
+		}
+	case reflect.Struct:
+		if err := p.assign(db, fields, rv); err != nil {
+			db.AddError(err)
+		}
+	}
+}
+
+// assign 为一条记录的零值主键填充id
+func (p *Plugin) assign(db *gormlib.DB, fields []*schema.Field, rv reflect.Value) error {
+	ctx := db.Statement.Context
+	for _, f := range fields {
+		if _, zero := f.ValueOf(ctx, rv); !zero {
+			continue
+		}
+		id, err := p.gen.NextId()
+		if err != nil {
+			return err
+		}
+		if err := f.Set(ctx, rv, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package gorm
+
+import (
+	"errors"
+	"testing"
+
+	gormlib "gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+
+	"github.com/pangush/snowflake"
+)
+
+type order struct {
+	ID   snowflake.ID `gorm:"primaryKey"`
+	Name string
+}
+
+type account struct {
+	ID   uint64 `gorm:"primaryKey"`
+	Name string
+}
+
+type tag struct {
+	Name string `gorm:"primaryKey"`
+}
+
+func open(t *testing.T, gen Generator) *gormlib.DB {
+	t.Helper()
+	db, err := gormlib.Open(tests.DummyDialector{}, &gormlib.Config{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(New(gen)); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestPlugin(t *testing.T) {
+	sf, err := snowflake.NewUnregistered(1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := open(t, sf)
+
+	o := order{Name: "a"}
+	if err := db.Create(&o).Error; err != nil {
+		t.Fatal(err)
+	}
+	if o.ID == 0 || o.ID.Parse().WorkerId() != 1 {
+		t.Errorf("order id = %d", o.ID)
+	}
+
+	a := account{Name: "b"}
+	if err := db.Create(&a).Error; err != nil {
+		t.Fatal(err)
+	}
+	if a.ID <= uint64(o.ID) {
+		t.Errorf("account id %d isn't greater than %d", a.ID, o.ID)
+	}
+
+	// 已经设置的主键保持不变
+	preset := order{ID: 42}
+	db.Create(&preset)
+	if preset.ID != 42 {
+		t.Errorf("preset id = %d, want 42", preset.ID)
+	}
+
+	batch := []order{{Name: "c"}, {ID: 7}, {Name: "d"}}
+	if err := db.Create(&batch).Error; err != nil {
+		t.Fatal(err)
+	}
+	if batch[0].ID == 0 || batch[1].ID != 7 || batch[2].ID <= batch[0].ID {
+		t.Errorf("batch ids = %d %d %d", batch[0].ID, batch[1].ID, batch[2].ID)
+	}
+
+	// 非整数主键不处理
+	if err := db.Create(&tag{Name: "x"}).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+type failing struct{}
+
+var errGen = errors.New("generator failed")
+
+func (failing) NextId() (int64, error) { return 0, errGen }
+
+func TestPlugin_Error(t *testing.T) {
+	db := open(t, failing{})
+	if err := db.Create(&order{Name: "a"}).Error; !errors.Is(err, errGen) {
+		t.Errorf("Create = %v, want %v", err, errGen)
+	}
+}
@@ -0,0 +1,392 @@
+// Package gossip 不依赖 etcd、Redis 等外部存储，由同一集群的实例互相广播心跳协商唯一的机器id，
+// 适用于节点数量不多、节点地址固定的小集群
+package gossip
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pangush/snowflake"
+)
+
+// 协商用到的时长，测试中会调小
+var (
+	heartbeatInterval = 500 * time.Millisecond // 广播心跳的间隔
+	settleTime        = 3 * time.Second        // 申请机器id后，持续这么久没有冲突才开始生成id
+	peerTimeout       = 5 * time.Second        // 超过这么久没有收到心跳的节点视为已经离开
+)
+
+var (
+	ErrNoFreeWorker    = errors.New("no free snowflake worker id among gossip peers")
+	ErrWorkerIDInUse   = errors.New("snowflake worker id is already used by a gossip peer")
+	ErrWorkerCollision = errors.New("snowflake worker id collides with a gossip peer")
+)
+
+// 心跳中节点的状态
+const (
+	stateClaim  = "claim"  // 正在申请机器id，还不能生成id
+	stateActive = "active" // 已经使用机器id生成id
+	stateLeave  = "leave"  // 已经停止，其它节点可以立即使用它的机器id
+)
+
+// Transport 在集群的实例之间传递心跳，UDPTransport 基于UDP实现
+type Transport interface {
+	// Broadcast 把msg发送给所有其它实例，允许丢失
+	Broadcast(msg []byte) error
+	// Receive 收到的消息，Close 之后关闭
+	Receive() <-chan []byte
+	Close() error
+}
+
+// message 心跳消息
+type message struct {
+	Node         string `json:"node"`
+	DatacenterID int64  `json:"datacenter_id"`
+	WorkerID     int64  `json:"worker_id"`
+	State        string `json:"state"`
+}
+
+// Peer 最近收到过心跳的其它节点
+type Peer struct {
+	Node         string
+	DatacenterID int64
+	WorkerID     int64
+	Active       bool      // 是否已经在生成id，false表示正在申请机器id
+	LastSeen     time.Time // 最近一次收到心跳的时间
+}
+
+// Generator 通过协商得到机器id的生成器。
+// 开始生成id之后仍然持续广播心跳并检查冲突：发现另一个节点也在使用相同的机器id时（如网络分区恢复后），
+// 节点名较大的一方被 Shutdown，之后生成id都返回 snowflake.ErrShutdown，Err 返回 ErrWorkerCollision。
+type Generator struct {
+	*snowflake.Snowflake
+
+	transport    Transport
+	node         string
+	datacenterID int64
+
+	mu       sync.Mutex
+	workerID int64
+	state    string
+	peers    map[string]Peer
+	err      error
+
+	changed  chan struct{} // 收到其它节点的心跳后通知协商过程重新检查冲突
+	done     chan struct{} // Close 时关闭，通知后台goroutine退出
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+}
+
+// Join 加入集群，为datacenterID协商一个其它节点没有使用的机器id(0-31)并创建生成器。
+// 先收听一段时间的心跳了解已有的节点，然后申请编号最小的空闲机器id，
+// settleTime 内没有冲突才开始生成id。两个节点同时申请相同的机器id时，节点名较小的一方获得，
+// 另一方改为申请下一个空闲的机器id。所有机器id都被占用时返回 ErrNoFreeWorker。
+// 不再使用时调用 Close，其它节点会立即释放它的机器id。
+func Join(ctx context.Context, transport Transport, datacenterID int64, opts ...snowflake.Option) (*Generator, error) {
+	return join(ctx, transport, datacenterID, -1, opts)
+}
+
+// JoinWithWorkerID 与 Join 相同，但只使用workerID，机器id已被其它节点使用或申请时拒绝启动，返回 ErrWorkerIDInUse
+func JoinWithWorkerID(ctx context.Context, transport Transport, datacenterID, workerID int64, opts ...snowflake.Option) (*Generator, error) {
+	if workerID < 0 || workerID > snowflake.MaxWorkerID {
+		return nil, fmt.Errorf("%w %d: can't be greater than %d or less than 0", snowflake.ErrInvalidWorkerID, workerID, snowflake.MaxWorkerID)
+	}
+	return join(ctx, transport, datacenterID, workerID, opts)
+}
+
+func join(ctx context.Context, transport Transport, datacenterID, workerID int64, opts []snowflake.Option) (*Generator, error) {
+	node, err := newNode()
+	if err != nil {
+		return nil, err
+	}
+	g := &Generator{
+		transport:    transport,
+		node:         node,
+		datacenterID: datacenterID,
+		workerID:     -1,
+		peers:        make(map[string]Peer),
+		changed:      make(chan struct{}, 1),
+		done:         make(chan struct{}),
+	}
+	g.wg.Add(2)
+	go g.receive()
+	go g.heartbeat()
+
+	if err := g.negotiate(ctx, workerID); err != nil {
+		g.stop()
+		return nil, err
+	}
+	s, err := snowflake.New(g.workerID, datacenterID, opts...)
+	if err != nil {
+		g.stop()
+		return nil, err
+	}
+	g.mu.Lock()
+	g.Snowflake = s
+	g.state = stateActive
+	g.mu.Unlock()
+	g.send()
+	return g, nil
+}
+
+// negotiate 协商机器id，fixed不为-1时只申请fixed
+func (g *Generator) negotiate(ctx context.Context, fixed int64) error {
+	// 先收听已有节点的心跳
+	if err := g.sleep(ctx, 2*heartbeatInterval); err != nil {
+		return err
+	}
+	for {
+		g.mu.Lock()
+		candidate := fixed
+		if candidate < 0 {
+			candidate = g.freeWorker()
+		} else if g.conflict(candidate) {
+			candidate = -1
+		}
+		g.workerID, g.state = candidate, stateClaim
+		g.mu.Unlock()
+		if candidate < 0 {
+			if fixed >= 0 {
+				return fmt.Errorf("%w: %d", ErrWorkerIDInUse, fixed)
+			}
+			return ErrNoFreeWorker
+		}
+		g.send()
+
+		settled, err := g.settle(ctx, candidate)
+		if err != nil || settled {
+			return err
+		}
+		if fixed >= 0 {
+			return fmt.Errorf("%w: %d", ErrWorkerIDInUse, fixed)
+		}
+	}
+}
+
+// settle 等待 settleTime，期间出现冲突时返回false
+func (g *Generator) settle(ctx context.Context, workerID int64) (bool, error) {
+	timer := time.NewTimer(settleTime)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-timer.C:
+			return true, nil
+		case <-g.changed:
+			g.mu.Lock()
+			conflict := g.conflict(workerID)
+			g.mu.Unlock()
+			if conflict {
+				return false, nil
+			}
+		}
+	}
+}
+
+// conflict 申请workerID时是否与其它节点冲突：有节点已经在使用，或者节点名更小的节点也在申请，调用方需持有锁
+func (g *Generator) conflict(workerID int64) bool {
+	for _, p := range g.livePeers() {
+		if p.WorkerID == workerID && (p.Active || p.Node < g.node) {
+			return true
+		}
+	}
+	return false
+}
+
+// freeWorker 编号最小的没有被其它节点使用或申请的机器id，没有时返回-1，调用方需持有锁
+func (g *Generator) freeWorker() int64 {
+	used := make(map[int64]bool)
+	for _, p := range g.livePeers() {
+		used[p.WorkerID] = true
+	}
+	for workerID := int64(0); workerID <= snowflake.MaxWorkerID; workerID++ {
+		if !used[workerID] {
+			return workerID
+		}
+	}
+	return -1
+}
+
+// livePeers 同一数据中心内没有超时的其它节点，调用方需持有锁
+func (g *Generator) livePeers() []Peer {
+	var peers []Peer
+	for node, p := range g.peers {
+		if time.Since(p.LastSeen) > peerTimeout {
+			delete(g.peers, node)
+			continue
+		}
+		if p.DatacenterID == g.datacenterID {
+			peers = append(peers, p)
+		}
+	}
+	return peers
+}
+
+// receive 处理收到的心跳
+func (g *Generator) receive() {
+	defer g.wg.Done()
+	for {
+		select {
+		case <-g.done:
+			return
+		case data, ok := <-g.transport.Receive():
+			if !ok {
+				return
+			}
+			var m message
+			if err := json.Unmarshal(data, &m); err != nil || m.Node == g.node {
+				continue
+			}
+			g.handle(m)
+		}
+	}
+}
+
+// handle 记录节点的状态，已经在生成id时检查冲突
+func (g *Generator) handle(m message) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if m.State == stateLeave {
+		delete(g.peers, m.Node)
+		return
+	}
+	g.peers[m.Node] = Peer{
+		Node:         m.Node,
+		DatacenterID: m.DatacenterID,
+		WorkerID:     m.WorkerID,
+		Active:       m.State == stateActive,
+		LastSeen:     time.Now(),
+	}
+	select {
+	case g.changed <- struct{}{}:
+	default:
+	}
+
+	// 两个节点都在使用相同的机器id，节点名较大的一方停止生成
+	if g.state == stateActive && g.err == nil && m.State == stateActive &&
+		m.DatacenterID == g.datacenterID && m.WorkerID == g.workerID && m.Node < g.node {
+		g.err = fmt.Errorf("%w: worker id %d is also used by %s", ErrWorkerCollision, g.workerID, m.Node)
+		g.Snowflake.Shutdown()
+	}
+}
+
+// heartbeat 每隔 heartbeatInterval 广播一次心跳
+func (g *Generator) heartbeat() {
+	defer g.wg.Done()
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-g.done:
+			return
+		case <-ticker.C:
+			g.send()
+		}
+	}
+}
+
+// send 广播当前状态，还没有申请机器id时不发送
+func (g *Generator) send() {
+	g.mu.Lock()
+	m := message{Node: g.node, DatacenterID: g.datacenterID, WorkerID: g.workerID, State: g.state}
+	g.mu.Unlock()
+	if m.State == "" || m.WorkerID < 0 {
+		return
+	}
+	data, _ := json.Marshal(m)
+	g.transport.Broadcast(data)
+}
+
+// sleep 等待d，ctx结束时返回 ctx.Err()
+func (g *Generator) sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// WorkerID 协商得到的机器id
+func (g *Generator) WorkerID() int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.workerID
+}
+
+// Err 开始生成id后发现的机器id冲突，没有冲突时返回nil
+func (g *Generator) Err() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.err
+}
+
+// Peers 最近收到过心跳的其它节点，按节点名排序
+func (g *Generator) Peers() []Peer {
+	g.mu.Lock()
+	var peers []Peer
+	for _, p := range g.peers {
+		if time.Since(p.LastSeen) <= peerTimeout {
+			peers = append(peers, p)
+		}
+	}
+	g.mu.Unlock()
+	sort.Slice(peers, func(i, j int) bool { return peers[i].Node < peers[j].Node })
+	return peers
+}
+
+// Close 停止生成id，通知其它节点释放机器id，然后关闭transport。可以重复调用。
+func (g *Generator) Close() error {
+	var err error
+	g.stopOnce.Do(func() {
+		g.Snowflake.Shutdown()
+		g.mu.Lock()
+		g.state = stateLeave
+		g.mu.Unlock()
+		g.send()
+		err = g.shutdown()
+		g.Snowflake.Close()
+	})
+	return err
+}
+
+// stop 协商失败时停止后台goroutine并关闭transport
+func (g *Generator) stop() {
+	g.stopOnce.Do(func() {
+		g.mu.Lock()
+		if g.state == stateClaim {
+			g.state = stateLeave
+		}
+		g.mu.Unlock()
+		g.send()
+		g.shutdown()
+	})
+}
+
+func (g *Generator) shutdown() error {
+	close(g.done)
+	err := g.transport.Close()
+	g.wg.Wait()
+	return err
+}
+
+// newNode 节点名：主机名加随机数，节点名的大小决定冲突时哪一方让出机器id
+func newNode() (string, error) {
+	host, _ := os.Hostname()
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return host + "-" + hex.EncodeToString(b[:]), nil
+}
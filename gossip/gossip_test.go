@@ -0,0 +1,237 @@
+package gossip
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pangush/snowflake"
+)
+
+// hub 在内存中转发心跳的网络
+type hub struct {
+	mu    sync.Mutex
+	nodes map[*memTransport]bool
+}
+
+func newHub() *hub {
+	return &hub{nodes: make(map[*memTransport]bool)}
+}
+
+type memTransport struct {
+	hub      *hub
+	received chan []byte
+	once     sync.Once
+}
+
+func (h *hub) transport() *memTransport {
+	t := &memTransport{hub: h, received: make(chan []byte, 256)}
+	h.mu.Lock()
+	h.nodes[t] = true
+	h.mu.Unlock()
+	return t
+}
+
+func (t *memTransport) Broadcast(msg []byte) error {
+	t.hub.mu.Lock()
+	defer t.hub.mu.Unlock()
+	for n := range t.hub.nodes {
+		if n != t {
+			select {
+			case n.received <- msg:
+			default:
+			}
+		}
+	}
+	return nil
+}
+
+func (t *memTransport) Receive() <-chan []byte {
+	return t.received
+}
+
+func (t *memTransport) Close() error {
+	t.once.Do(func() {
+		t.hub.mu.Lock()
+		delete(t.hub.nodes, t)
+		t.hub.mu.Unlock()
+		close(t.received)
+	})
+	return nil
+}
+
+func shortTimings(t *testing.T) {
+	interval, settle, timeout := heartbeatInterval, settleTime, peerTimeout
+	t.Cleanup(func() { heartbeatInterval, settleTime, peerTimeout = interval, settle, timeout })
+	heartbeatInterval, settleTime, peerTimeout = 10*time.Millisecond, 60*time.Millisecond, 200*time.Millisecond
+}
+
+func TestJoin(t *testing.T) {
+	shortTimings(t)
+	h := newHub()
+	ctx := context.Background()
+
+	var gens []*Generator
+	for i := 0; i < 3; i++ {
+		g, err := Join(ctx, h.transport(), 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer g.Close()
+		if g.WorkerID() != int64(i) {
+			t.Errorf("node %d got worker id %d, want %d", i, g.WorkerID(), i)
+		}
+		gens = append(gens, g)
+	}
+	id, err := gens[2].NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := snowflake.Parse(id); p.WorkerId() != 2 || p.DatacenterId() != 3 {
+		t.Errorf("id node = %d/%d, want 2/3", p.WorkerId(), p.DatacenterId())
+	}
+	time.Sleep(3 * heartbeatInterval)
+	if peers := gens[0].Peers(); len(peers) != 2 || !peers[0].Active {
+		t.Errorf("Peers() = %+v, want 2 active peers", peers)
+	}
+
+	// 已被使用的机器id拒绝启动
+	if _, err := JoinWithWorkerID(ctx, h.transport(), 3, 1); !errors.Is(err, ErrWorkerIDInUse) {
+		t.Errorf("JoinWithWorkerID(1) = %v, want ErrWorkerIDInUse", err)
+	}
+	// 其它数据中心不冲突
+	other, err := JoinWithWorkerID(ctx, h.transport(), 4, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.Close()
+
+	// 离开后机器id可以立即被使用
+	gens[1].Close()
+	time.Sleep(3 * heartbeatInterval)
+	g, err := Join(ctx, h.transport(), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+	if g.WorkerID() != 1 {
+		t.Errorf("worker id after leave = %d, want 1", g.WorkerID())
+	}
+	if _, err := gens[1].NextId(); !errors.Is(err, snowflake.ErrShutdown) {
+		t.Errorf("NextId after Close = %v, want ErrShutdown", err)
+	}
+}
+
+func TestJoin_Concurrent(t *testing.T) {
+	shortTimings(t)
+	h := newHub()
+	const n = 4
+	gens := make([]*Generator, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			gens[i], errs[i] = Join(context.Background(), h.transport(), 5)
+		}(i)
+	}
+	wg.Wait()
+	seen := map[int64]bool{}
+	for i, g := range gens {
+		if errs[i] != nil {
+			t.Fatal(errs[i])
+		}
+		defer g.Close()
+		if seen[g.WorkerID()] {
+			t.Errorf("worker id %d assigned twice", g.WorkerID())
+		}
+		seen[g.WorkerID()] = true
+	}
+}
+
+func TestCollision(t *testing.T) {
+	shortTimings(t)
+	h := newHub()
+	g, err := Join(context.Background(), h.transport(), 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+
+	// 网络分区恢复后出现的节点名更小、机器id相同的节点
+	intruder := h.transport()
+	msg, _ := json.Marshal(message{Node: "", DatacenterID: 6, WorkerID: g.WorkerID(), State: stateActive})
+	intruder.Broadcast(msg)
+	deadline := time.Now().Add(time.Second)
+	for g.Err() == nil && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if !errors.Is(g.Err(), ErrWorkerCollision) {
+		t.Fatalf("Err() = %v, want ErrWorkerCollision", g.Err())
+	}
+	if _, err := g.NextId(); !errors.Is(err, snowflake.ErrShutdown) {
+		t.Errorf("NextId after collision = %v, want ErrShutdown", err)
+	}
+}
+
+func TestJoin_NoFreeWorker(t *testing.T) {
+	shortTimings(t)
+	h := newHub()
+	fake := h.transport()
+	stop := make(chan struct{})
+	defer close(stop)
+	interval := heartbeatInterval
+	go func() {
+		for {
+			for w := int64(0); w <= snowflake.MaxWorkerID; w++ {
+				msg, _ := json.Marshal(message{Node: fmt.Sprintf("peer-%02d", w), DatacenterID: 7, WorkerID: w, State: stateActive})
+				fake.Broadcast(msg)
+			}
+			select {
+			case <-stop:
+				return
+			case <-time.After(interval):
+			}
+		}
+	}()
+	g, err := Join(context.Background(), h.transport(), 7)
+	if !errors.Is(err, ErrNoFreeWorker) {
+		t.Errorf("Join = %v, want ErrNoFreeWorker", err)
+	}
+	if g != nil {
+		g.Close()
+	}
+}
+
+func TestUDPTransport(t *testing.T) {
+	shortTimings(t)
+	a, err := NewUDPTransport("127.0.0.1:0", nil)
+	if err != nil {
+		t.Skipf("udp unavailable: %v", err)
+	}
+	b, err := NewUDPTransport("127.0.0.1:0", []string{a.Addr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.peers = append(a.peers, b.conn.LocalAddr().(*net.UDPAddr))
+
+	ga, err := Join(context.Background(), a, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ga.Close()
+	gb, err := Join(context.Background(), b, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gb.Close()
+	if ga.WorkerID() == gb.WorkerID() {
+		t.Errorf("both nodes got worker id %d", ga.WorkerID())
+	}
+}
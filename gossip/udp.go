@@ -0,0 +1,82 @@
+package gossip
+
+import (
+	"fmt"
+	"net"
+)
+
+// maxMessageSize 心跳消息的最大长度
+const maxMessageSize = 1024
+
+// UDPTransport 通过UDP把心跳逐个发送给配置的节点地址
+type UDPTransport struct {
+	conn     *net.UDPConn
+	peers    []*net.UDPAddr
+	received chan []byte
+}
+
+var _ Transport = (*UDPTransport)(nil)
+
+// NewUDPTransport 在listenAddr上监听心跳，并向peers中的地址（host:port）发送心跳。
+// peers可以包含本节点自己的地址，收到自己的心跳会被忽略，因此所有节点可以使用相同的配置
+func NewUDPTransport(listenAddr string, peers []string) (*UDPTransport, error) {
+	addr, err := net.ResolveUDPAddr("udp", listenAddr)
+	if err != nil {
+		return nil, fmt.Errorf("resolve listen address %q: %w", listenAddr, err)
+	}
+	t := &UDPTransport{received: make(chan []byte, 64)}
+	for _, p := range peers {
+		peer, err := net.ResolveUDPAddr("udp", p)
+		if err != nil {
+			return nil, fmt.Errorf("resolve peer address %q: %w", p, err)
+		}
+		t.peers = append(t.peers, peer)
+	}
+	if t.conn, err = net.ListenUDP("udp", addr); err != nil {
+		return nil, err
+	}
+	go t.read()
+	return t, nil
+}
+
+// Addr 监听的地址，listenAddr的端口为0时可以用它取得实际的端口
+func (t *UDPTransport) Addr() net.Addr {
+	return t.conn.LocalAddr()
+}
+
+// Broadcast 实现 Transport，发送失败的节点会被跳过，返回最后一个错误
+func (t *UDPTransport) Broadcast(msg []byte) error {
+	var err error
+	for _, peer := range t.peers {
+		if _, e := t.conn.WriteToUDP(msg, peer); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// Receive 实现 Transport
+func (t *UDPTransport) Receive() <-chan []byte {
+	return t.received
+}
+
+// Close 实现 Transport
+func (t *UDPTransport) Close() error {
+	return t.conn.Close()
+}
+
+// read 读取心跳直到连接关闭，处理不过来时丢弃
+func (t *UDPTransport) read() {
+	defer close(t.received)
+	buf := make([]byte, maxMessageSize)
+	for {
+		n, _, err := t.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		select {
+		case t.received <- append([]byte(nil), buf[:n]...):
+		default:
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Package grpc 以gRPC服务的形式对外提供雪花id生成
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/pangush/snowflake"
+)
+
+// nextIdMethod NextId 方法的完整名称
+const nextIdMethod = "/snowflake.Snowflake/NextId"
+
+// serviceDesc snowflake.Snowflake 服务，请求为 google.protobuf.Empty，响应为 google.protobuf.Int64Value，
+// 其它语言的客户端可以直接使用这两个well-known类型调用。
+var serviceDesc = grpclib.ServiceDesc{
+	ServiceName: "snowflake.Snowflake",
+	HandlerType: (*interface{})(nil),
+	Methods: []grpclib.MethodDesc{{
+		MethodName: "NextId",
+		Handler:    nextIdHandler,
+	}},
+	Metadata: "snowflake.proto",
+}
+
+func nextIdHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpclib.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	s := srv.(*snowflake.Snowflake)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nextId(s)
+	}
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+	return interceptor(ctx, in, &grpclib.UnaryServerInfo{Server: srv, FullMethod: nextIdMethod}, handler)
+}
+
+func nextId(s *snowflake.Snowflake) (*wrapperspb.Int64Value, error) {
+	id, err := s.NextId()
+	switch {
+	case err == nil:
+		return wrapperspb.Int64(id), nil
+	case errors.Is(err, snowflake.ErrShutdown):
+		return nil, status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, snowflake.ErrRateLimited):
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	default:
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+}
+
+// Register 在srv上注册由s生成id的 snowflake.Snowflake 服务
+func Register(srv *grpclib.Server, s *snowflake.Snowflake) {
+	srv.RegisterService(&serviceDesc, s)
+}
+
+// NewSecureServer 创建使用双向TLS的gRPC服务并注册 snowflake.Snowflake 服务。
+// tlsConfig没有设置 ClientAuth 时默认为 tls.RequireAndVerifyClientCert，客户端必须提供
+// 由 ClientCAs 签发的证书；tlsConfig会被复制，不会被修改。tlsConfig为nil时返回错误。
+func NewSecureServer(s *snowflake.Snowflake, tlsConfig *tls.Config) (*grpclib.Server, error) {
+	if tlsConfig == nil {
+		return nil, errors.New("tls config is required for a secure server")
+	}
+	config := tlsConfig.Clone()
+	if config.ClientAuth == tls.NoClientCert {
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	srv := grpclib.NewServer(grpclib.Creds(credentials.NewTLS(config)))
+	Register(srv, s)
+	return srv, nil
+}
+
+// NextId 通过cc调用 snowflake.Snowflake 服务生成id
+func NextId(ctx context.Context, cc grpclib.ClientConnInterface) (int64, error) {
+	out := new(wrapperspb.Int64Value)
+	if err := cc.Invoke(ctx, nextIdMethod, new(emptypb.Empty), out); err != nil {
+		return 0, err
+	}
+	return out.Value, nil
+}
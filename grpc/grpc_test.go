@@ -0,0 +1,136 @@
+package grpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+
+	"github.com/pangush/snowflake"
+)
+
+var (
+	certPool   *x509.CertPool  // 自签名CA
+	serverCert tls.Certificate // CA签发的服务端证书
+	clientCert tls.Certificate // CA签发的客户端证书
+)
+
+func TestMain(m *testing.M) {
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "snowflake test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		panic(err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+	certPool = x509.NewCertPool()
+	certPool.AddCert(ca)
+
+	issue := func(serial int64, usage x509.ExtKeyUsage) tls.Certificate {
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: "localhost"},
+			DNSNames:     []string{"localhost"},
+			IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+		if err != nil {
+			panic(err)
+		}
+		return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+	}
+	serverCert = issue(2, x509.ExtKeyUsageServerAuth)
+	clientCert = issue(3, x509.ExtKeyUsageClientAuth)
+
+	os.Exit(m.Run())
+}
+
+func TestNewSecureServer_NilConfig(t *testing.T) {
+	sf, err := snowflake.NewUnregistered(4, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if srv, err := NewSecureServer(sf, nil); err == nil || srv != nil {
+		t.Errorf("NewSecureServer(nil) = %v, %v, want error", srv, err)
+	}
+}
+
+func TestNewSecureServer(t *testing.T) {
+	sf, err := snowflake.NewUnregistered(4, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &tls.Config{Certificates: []tls.Certificate{serverCert}, ClientCAs: certPool}
+	srv, err := NewSecureServer(sf, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.ClientAuth != tls.NoClientCert {
+		t.Error("NewSecureServer modified tlsConfig")
+	}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	dial := func(certs ...tls.Certificate) *grpclib.ClientConn {
+		creds := credentials.NewTLS(&tls.Config{RootCAs: certPool, Certificates: certs, ServerName: "localhost"})
+		conn, err := grpclib.Dial(lis.Addr().String(), grpclib.WithTransportCredentials(creds))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return conn
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn := dial(clientCert)
+	defer conn.Close()
+	id, err := NextId(ctx, conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := snowflake.ID(id).Parse(); p.WorkerId() != 4 || p.DatacenterId() != 5 {
+		t.Errorf("id %d parsed as worker %d datacenter %d", id, p.WorkerId(), p.DatacenterId())
+	}
+
+	// 没有客户端证书时握手失败
+	noCert := dial()
+	defer noCert.Close()
+	if _, err := NextId(ctx, noCert); status.Code(err) != codes.Unavailable {
+		t.Errorf("NextId without client certificate = %v, want Unavailable", err)
+	}
+
+	sf.Shutdown()
+	if _, err := NextId(ctx, conn); status.Code(err) != codes.Unavailable {
+		t.Errorf("NextId after Shutdown = %v, want Unavailable", err)
+	}
+}
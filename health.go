@@ -0,0 +1,116 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// healthWindow Check 统计毫秒内序列用尽频率的时间窗口
+const healthWindow = 10 * time.Second
+
+var ErrUnhealthy = errors.New("snowflake generator is unhealthy")
+
+// Health 生成器的健康状况，见 Check
+type Health struct {
+	Err            error         // 生成器当前无法生成id的原因（Close、租约丢失、时间盒过期、时间戳用尽、熔断），正常时为nil
+	ClockSkew      time.Duration // 最近生成id的时间戳领先系统时间多久，时钟回退或借用未来时间戳时大于0
+	Lifetime       time.Duration // 距离 ExpiresAt 还有多久，已经用尽时为0
+	ExhaustionRate float64       // 最近 healthWindow 内平均每秒毫秒内序列用尽的次数
+}
+
+// HealthPolicy Healthy 判断生成器是否健康的阈值，字段为0表示不检查该项
+type HealthPolicy struct {
+	MaxClockSkew      time.Duration // ClockSkew 的上限
+	MinLifetime       time.Duration // Lifetime 的下限，用于提前规划起始时间的迁移
+	MaxExhaustionRate float64       // ExhaustionRate 的上限，持续超过说明吞吐已接近上限
+}
+
+// DefaultHealthPolicy Healthy 使用的默认阈值：时钟偏差不超过1秒，距离时间戳用尽不少于一年，
+// 每秒序列用尽不超过100次
+var DefaultHealthPolicy = HealthPolicy{
+	MaxClockSkew:      time.Second,
+	MinLifetime:       365 * 24 * time.Hour,
+	MaxExhaustionRate: 100,
+}
+
+// Check 返回生成器当前的健康状况，可以并发调用，不会生成id
+func (s *Snowflake) Check() Health {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.timeGen()
+	h := Health{
+		ClockSkew:      s.lead(),
+		ExhaustionRate: s.exhaustionRate(now),
+	}
+	if left := s.expiresAt() - now; left > 0 {
+		h.Lifetime = time.Duration(left) * time.Millisecond
+	}
+	switch {
+	case s.shutdown.Load():
+		h.Err = ErrShutdown
+	case s.leaseLost.Load():
+		h.Err = ErrLeaseLost
+	case s.timeBoxExpired(now):
+		h.Err = ErrTimeBoxExpired
+	case s.breakerOpen(now):
+		h.Err = ErrCircuitOpen
+	default:
+		h.Err = s.checkOverflow(now)
+	}
+	return h
+}
+
+// Healthy 按 DefaultHealthPolicy 检查生成器，健康时返回nil，否则返回包装了 ErrUnhealthy 的错误，
+// 适合作为gRPC、HTTP服务的就绪检查，在生成器开始返回错误之前告警
+func (s *Snowflake) Healthy() error {
+	return DefaultHealthPolicy.Evaluate(s.Check())
+}
+
+// Evaluate 按阈值检查h，返回遇到的第一个问题
+func (p HealthPolicy) Evaluate(h Health) error {
+	switch {
+	case h.Err != nil:
+		return fmt.Errorf("%w: %w", ErrUnhealthy, h.Err)
+	case p.MaxClockSkew > 0 && h.ClockSkew > p.MaxClockSkew:
+		return fmt.Errorf("%w: clock skew %v exceeds %v", ErrUnhealthy, h.ClockSkew, p.MaxClockSkew)
+	case p.MinLifetime > 0 && h.Lifetime < p.MinLifetime:
+		return fmt.Errorf("%w: epoch expires in %v, less than %v", ErrUnhealthy, h.Lifetime, p.MinLifetime)
+	case p.MaxExhaustionRate > 0 && h.ExhaustionRate > p.MaxExhaustionRate:
+		return fmt.Errorf("%w: sequence exhausted %.1f times per second, more than %.1f", ErrUnhealthy, h.ExhaustionRate, p.MaxExhaustionRate)
+	}
+	return nil
+}
+
+// recordExhaustion 记录timestamp时毫秒内序列用尽一次，按 healthWindow 分段计数，调用方需持有锁
+func (s *Snowflake) recordExhaustion(timestamp int64) {
+	s.rotateExhaustion(timestamp)
+	s.exhaustCur++
+}
+
+// rotateExhaustion 当前分段已经结束时开始新的分段，调用方需持有锁
+func (s *Snowflake) rotateExhaustion(timestamp int64) {
+	window := healthWindow.Milliseconds()
+	switch elapsed := timestamp - s.exhaustStart; {
+	case elapsed < window:
+	case elapsed < 2*window:
+		s.exhaustPrev, s.exhaustCur = s.exhaustCur, 0
+		s.exhaustStart += window
+	default:
+		s.exhaustPrev, s.exhaustCur = 0, 0
+		s.exhaustStart = timestamp
+	}
+}
+
+// exhaustionRate 最近 healthWindow 内平均每秒序列用尽的次数，上一分段按仍在窗口内的比例计入，调用方需持有锁
+func (s *Snowflake) exhaustionRate(now int64) float64 {
+	s.rotateExhaustion(now)
+	window := healthWindow.Milliseconds()
+	elapsed := now - s.exhaustStart
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	count := float64(s.exhaustCur) + float64(s.exhaustPrev)*float64(window-elapsed)/float64(window)
+	return count / healthWindow.Seconds()
+}
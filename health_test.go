@@ -0,0 +1,104 @@
+package snowflake
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSnowflake_Check(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, err := NewUnregistered(1, 2, WithClock(clock), WithMaxSequence(3),
+		WithSleeper(func(time.Duration) { clock.Add(time.Millisecond) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := sf.Check()
+	if h.Err != nil || h.ClockSkew != 0 || h.ExhaustionRate != 0 {
+		t.Fatalf("initial health = %+v", h)
+	}
+	if want := sf.ExpiresAt().Sub(clock.Now()); h.Lifetime != want {
+		t.Errorf("Lifetime = %v, want %v", h.Lifetime, want)
+	}
+	if err := sf.Healthy(); err != nil {
+		t.Fatalf("Healthy = %v", err)
+	}
+
+	// 每4个id用尽一次序列，2000次用尽折合每秒200次
+	for i := 0; i < 8000; i++ {
+		if _, err := sf.NextId(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if h = sf.Check(); h.ExhaustionRate < 199 || h.ExhaustionRate > 200 {
+		t.Errorf("ExhaustionRate = %v, want about 200", h.ExhaustionRate)
+	}
+	if err := sf.Healthy(); !errors.Is(err, ErrUnhealthy) {
+		t.Errorf("Healthy after exhaustion = %v, want ErrUnhealthy", err)
+	}
+	// 超过两个窗口没有用尽后恢复
+	clock.Add(2 * healthWindow)
+	if h = sf.Check(); h.ExhaustionRate != 0 {
+		t.Errorf("ExhaustionRate after idle = %v, want 0", h.ExhaustionRate)
+	}
+	if err := sf.Healthy(); err != nil {
+		t.Errorf("Healthy after idle = %v", err)
+	}
+
+	// 时钟回退2秒
+	if _, err := sf.NextId(); err != nil {
+		t.Fatal(err)
+	}
+	clock.Add(-2 * time.Second)
+	if h = sf.Check(); h.ClockSkew != 2*time.Second {
+		t.Errorf("ClockSkew = %v, want 2s", h.ClockSkew)
+	}
+	if err := sf.Healthy(); !errors.Is(err, ErrUnhealthy) {
+		t.Errorf("Healthy after rollback = %v, want ErrUnhealthy", err)
+	}
+	clock.Add(2 * time.Second)
+
+	sf.Shutdown()
+	if h = sf.Check(); !errors.Is(h.Err, ErrShutdown) {
+		t.Errorf("Err after Shutdown = %v, want ErrShutdown", h.Err)
+	}
+	if err := sf.Healthy(); !errors.Is(err, ErrUnhealthy) || !errors.Is(err, ErrShutdown) {
+		t.Errorf("Healthy after Shutdown = %v", err)
+	}
+}
+
+func TestHealthPolicy_Evaluate(t *testing.T) {
+	p := HealthPolicy{MaxClockSkew: time.Second, MinLifetime: time.Hour, MaxExhaustionRate: 10}
+	ok := Health{ClockSkew: time.Second, Lifetime: time.Hour, ExhaustionRate: 10}
+	if err := p.Evaluate(ok); err != nil {
+		t.Errorf("Evaluate(%+v) = %v", ok, err)
+	}
+	for _, h := range []Health{
+		{Err: ErrLeaseLost, Lifetime: time.Hour},
+		{ClockSkew: 2 * time.Second, Lifetime: time.Hour},
+		{Lifetime: time.Minute},
+		{Lifetime: time.Hour, ExhaustionRate: 11},
+	} {
+		if err := p.Evaluate(h); !errors.Is(err, ErrUnhealthy) {
+			t.Errorf("Evaluate(%+v) = %v, want ErrUnhealthy", h, err)
+		}
+	}
+	// 为0的阈值不检查
+	if err := (HealthPolicy{}).Evaluate(Health{ClockSkew: time.Hour, ExhaustionRate: 1e6}); err != nil {
+		t.Errorf("zero policy Evaluate = %v", err)
+	}
+}
+
+func TestSnowflake_CheckExpiring(t *testing.T) {
+	sf, err := NewUnregistered(1, 2, WithEpoch(time.Now().AddDate(-69, 0, 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := sf.Check(); h.Err != nil || h.Lifetime <= 0 || h.Lifetime >= DefaultHealthPolicy.MinLifetime {
+		t.Fatalf("health = %+v", h)
+	}
+	if err := sf.Healthy(); !errors.Is(err, ErrUnhealthy) {
+		t.Errorf("Healthy = %v, want ErrUnhealthy", err)
+	}
+}
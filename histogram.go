@@ -0,0 +1,69 @@
+package snowflake
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// HourHistogram 按一天中的小时(0-23)统计的id个数
+type HourHistogram [24]int64
+
+// histogramWidth String 中最长的柱的字符数
+const histogramWidth = 50
+
+// BuildHourHistogram 统计ids按epoch解析出的生成时间落在loc时区每个小时内的个数，用于分析流量的分布。
+// loc为nil时使用UTC。
+func BuildHourHistogram(ids []int64, epoch time.Time, loc *time.Location) HourHistogram {
+	if loc == nil {
+		loc = time.UTC
+	}
+	var h HourHistogram
+	for _, id := range ids {
+		h[ParseWithEpoch(id, epoch).Time().In(loc).Hour()]++
+	}
+	return h
+}
+
+// Max 个数最多的小时及其个数，有多个时返回最早的小时
+func (h HourHistogram) Max() (hour int, count int64) {
+	for i, c := range h {
+		if c > h[hour] {
+			hour = i
+		}
+	}
+	return hour, h[hour]
+}
+
+// Min 个数最少的小时及其个数，有多个时返回最早的小时
+func (h HourHistogram) Min() (hour int, count int64) {
+	for i, c := range h {
+		if c < h[hour] {
+			hour = i
+		}
+	}
+	return hour, h[hour]
+}
+
+// Total id总数
+func (h HourHistogram) Total() int64 {
+	total := int64(0)
+	for _, c := range h {
+		total += c
+	}
+	return total
+}
+
+// String 以ASCII柱状图输出，每小时一行，柱的长度按最大值缩放
+func (h HourHistogram) String() string {
+	_, max := h.Max()
+	var b strings.Builder
+	for hour, c := range h {
+		n := 0
+		if max > 0 {
+			n = int(c * histogramWidth / max)
+		}
+		fmt.Fprintf(&b, "%02d | %-*s %d\n", hour, histogramWidth, strings.Repeat("#", n), c)
+	}
+	return b.String()
+}
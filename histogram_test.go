@@ -0,0 +1,55 @@
+package snowflake
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildHourHistogram(t *testing.T) {
+	epoch := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(hour, minute int) int64 {
+		ms := time.Date(2024, 5, 6, hour, minute, 0, 0, time.UTC).Sub(epoch).Milliseconds()
+		return ms << timestampLeftShift
+	}
+	ids := []int64{at(14, 0), at(14, 59), at(15, 30), at(14, 10), at(0, 0), at(23, 59)}
+
+	h := BuildHourHistogram(ids, epoch, nil)
+	if h[14] != 3 || h[15] != 1 || h[0] != 1 || h[23] != 1 {
+		t.Errorf("histogram = %v", h)
+	}
+	if hour, c := h.Max(); hour != 14 || c != 3 {
+		t.Errorf("Max = %d, %d, want 14, 3", hour, c)
+	}
+	if hour, c := h.Min(); hour != 1 || c != 0 {
+		t.Errorf("Min = %d, %d, want 1, 0", hour, c)
+	}
+	if h.Total() != int64(len(ids)) {
+		t.Errorf("Total = %d, want %d", h.Total(), len(ids))
+	}
+
+	// 东八区的14点是UTC的6点
+	shanghai := time.FixedZone("CST", 8*3600)
+	if h := BuildHourHistogram(ids, epoch, shanghai); h[22] != 3 || h[7] != 1 {
+		t.Errorf("histogram in +08:00 = %v", h)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(h.String(), "\n"), "\n")
+	if len(lines) != 24 {
+		t.Fatalf("String has %d lines, want 24", len(lines))
+	}
+	if want := "14 | " + strings.Repeat("#", histogramWidth) + " 3"; lines[14] != want {
+		t.Errorf("line 14 = %q, want %q", lines[14], want)
+	}
+	if !strings.HasPrefix(lines[15], "15 | "+strings.Repeat("#", histogramWidth/3)+" ") {
+		t.Errorf("line 15 = %q", lines[15])
+	}
+	if strings.Contains(lines[1], "#") {
+		t.Errorf("line 1 = %q, want empty bar", lines[1])
+	}
+
+	var empty HourHistogram
+	if hour, c := empty.Max(); hour != 0 || c != 0 {
+		t.Errorf("empty Max = %d, %d", hour, c)
+	}
+}
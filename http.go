@@ -0,0 +1,30 @@
+package snowflake
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// contextKey 在context中存放id的key
+type contextKey struct{}
+
+// Middleware 为每个请求生成一个id，放入请求的context并通过 X-Request-ID 响应头返回
+func Middleware(s *Snowflake) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id, err := s.NextId()
+			if err != nil {
+				http.Error(w, "failed to generate request id", http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("X-Request-ID", strconv.FormatInt(id, 10))
+			next.ServeHTTP(w, r.WithContext(ContextWithID(r.Context(), id)))
+		})
+	}
+}
+
+// RequestID 取出 Middleware 放入context的id
+func RequestID(ctx context.Context) (int64, bool) {
+	return IDFromContext(ctx)
+}
@@ -0,0 +1,48 @@
+package snowflake
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	sf, err := New(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+	var ctxId int64
+	var ok bool
+	h := Middleware(sf)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctxId, ok = RequestID(r.Context())
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if !ok {
+		t.Fatal("request context has no id")
+	}
+	header, err := strconv.ParseInt(rec.Header().Get("X-Request-ID"), 10, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header != ctxId {
+		t.Errorf("X-Request-ID = %d, context id = %d", header, ctxId)
+	}
+
+	// 生成失败时返回500，不调用下一个handler
+	sf.lastTimestamp = timeGen() + 60000
+	ok = false
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusInternalServerError || ok {
+		t.Errorf("status = %d, handler called = %v, want 500 and not called", rec.Code, ok)
+	}
+
+	if _, ok := RequestID(context.Background()); ok {
+		t.Error("RequestID on empty context should return false")
+	}
+}
@@ -0,0 +1,253 @@
+package snowflake
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+var ErrUnsupportedType = errors.New("unsupported snowflake id type")
+
+// ID 雪花算法生成的id
+type ID int64
+
+// ParsedID 解析后的id，包含各个字段的值
+type ParsedID struct {
+	id           ID
+	timestamp    int64 // 生成时间(unix时间戳/毫秒)
+	datacenterId int64
+	workerId     int64
+	sequence     int64
+	version      int64
+}
+
+// Parse 按默认的位分布和起始时间解析id
+func (id ID) Parse() ParsedID {
+	return parse(id, twepoch)
+}
+
+// Parse 按默认的位分布和起始时间解析id，可以通过 Time、DatacenterId、WorkerId、Sequence
+// 取得各个字段，用于调试、分片和日志关联。自定义配置的生成器使用 Snowflake.Decompose
+func Parse(id int64) ParsedID {
+	return parse(ID(id), twepoch)
+}
+
+// ParseWithEpoch 按默认的位分布和指定的起始时间解析id
+func ParseWithEpoch(id int64, epoch time.Time) ParsedID {
+	return parse(ID(id), epoch.UnixMilli())
+}
+
+func parse(id ID, epoch int64) ParsedID {
+	return ParsedID{
+		id:           id,
+		timestamp:    (int64(id) >> timestampLeftShift) + epoch,
+		datacenterId: (int64(id) >> datacenterIdShift) & maxDatacenterId,
+		workerId:     (int64(id) >> workerIdShift) & maxWorkerId,
+		sequence:     int64(id) & sequenceMask,
+	}
+}
+
+// ParseString 将十进制字符串解析为id
+func ParseString(s string) (ID, error) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid snowflake id %q: %w", s, err)
+	}
+	return ID(n), nil
+}
+
+// Decode 将API中常见的各种表示形式统一转换为id，支持 int64、uint64、
+// 十进制的 string 和 []byte、json.Number 以及 ID，其它类型返回 ErrUnsupportedType。
+func Decode(v interface{}) (ID, error) {
+	switch x := v.(type) {
+	case ID:
+		return x, nil
+	case int64:
+		return ID(x), nil
+	case uint64:
+		if x > math.MaxInt64 {
+			return 0, fmt.Errorf("snowflake id %d overflows int64", x)
+		}
+		return ID(x), nil
+	case string:
+		return ParseString(x)
+	case []byte:
+		return ParseString(string(x))
+	case json.Number:
+		return ParseString(x.String())
+	default:
+		return 0, fmt.Errorf("%w: %T", ErrUnsupportedType, v)
+	}
+}
+
+// ParseWithVersion 解析使用 WithVersionBits 生成的id，bits需与生成时一致。
+// 未使用版本号生成的旧id，只要毫秒内序列的高bits位为0，解析出的版本号即为0。
+func ParseWithVersion(id int64, bits uint8) ParsedID {
+	return parseWithVersion(ID(id), twepoch, bits)
+}
+
+func parseWithVersion(id ID, epoch int64, bits uint8) ParsedID {
+	p := parse(id, epoch)
+	if bits == 0 || bits >= sequenceBits {
+		return p
+	}
+	p.version = p.sequence >> (sequenceBits - bits)
+	p.sequence &= sequenceMask >> bits
+	return p
+}
+
+// ParseWithDatacenterVersion 解析使用 WithDatacenterVersionBits 生成的id，bits需与生成时一致
+func ParseWithDatacenterVersion(id int64, bits uint8) ParsedID {
+	return parseWithDatacenterVersion(ID(id), twepoch, bits)
+}
+
+func parseWithDatacenterVersion(id ID, epoch int64, bits uint8) ParsedID {
+	p := parse(id, epoch)
+	if bits == 0 || bits >= datacenterIdBits {
+		return p
+	}
+	p.version = p.datacenterId >> (datacenterIdBits - bits)
+	p.datacenterId &= maxDatacenterId >> bits
+	return p
+}
+
+// ID 原始id
+func (p ParsedID) ID() ID {
+	return p.id
+}
+
+// Timestamp 生成时间(unix时间戳/毫秒)
+func (p ParsedID) Timestamp() int64 {
+	return p.timestamp
+}
+
+// Time 生成时间
+func (p ParsedID) Time() time.Time {
+	return time.UnixMilli(p.timestamp)
+}
+
+// DatacenterId 数据id
+func (p ParsedID) DatacenterId() int64 {
+	return p.datacenterId
+}
+
+// WorkerId 机器id
+func (p ParsedID) WorkerId() int64 {
+	return p.workerId
+}
+
+// Sequence 毫秒内序列
+func (p ParsedID) Sequence() int64 {
+	return p.sequence
+}
+
+// Version 版本号，只有 ParseWithVersion 和 ParseWithDatacenterVersion 会解析出非0的值
+func (p ParsedID) Version() int64 {
+	return p.version
+}
+
+// RedactionLevel 脱敏级别，可以组合使用
+type RedactionLevel uint8
+
+const (
+	RedactSequence   RedactionLevel = 1 << iota // 清除毫秒内序列
+	RedactWorker                                // 清除机器id
+	RedactDatacenter                            // 清除数据id
+
+	RedactAll = RedactSequence | RedactWorker | RedactDatacenter // 只保留时间戳
+)
+
+// Redact 将指定字段的位清零，用于在审计日志中隐藏生成id的节点（部署拓扑）。
+// 返回值仍然可以解析，被清除的字段为0。
+func (id ID) Redact(level RedactionLevel) ID {
+	mask := int64(0)
+	if level&RedactSequence != 0 {
+		mask |= sequenceMask
+	}
+	if level&RedactWorker != 0 {
+		mask |= maxWorkerId << workerIdShift
+	}
+	if level&RedactDatacenter != 0 {
+		mask |= maxDatacenterId << datacenterIdShift
+	}
+	return ID(int64(id) &^ mask)
+}
+
+// Time 按默认起始时间得到id的生成时间
+func (id ID) Time() time.Time {
+	return id.Parse().Time()
+}
+
+// Diff 两个id生成时间的差，即 a.Time().Sub(b.Time())，a早于b时为负数。
+// 同一毫秒内生成的id差为0，与毫秒内序列无关。
+func (a ID) Diff(b ID) time.Duration {
+	return a.Time().Sub(b.Time())
+}
+
+// String 十进制字符串，ParseString 可以还原
+func (id ID) String() string {
+	return strconv.FormatInt(int64(id), 10)
+}
+
+// GoString 实现 fmt.GoStringer，%#v 输出合法的Go字面量
+func (id ID) GoString() string {
+	return fmt.Sprintf("snowflake.ID(%d)", int64(id))
+}
+
+// IsValid 判断id是否可能由本包按默认配置生成：不能为负数，生成时间不能晚于当前时间
+func (id ID) IsValid() bool {
+	return id >= 0 && id.Parse().Timestamp() <= timeGen()
+}
+
+// TotalOrder 依次按时间戳、数据id、机器id、毫秒内序列比较两个id，
+// a在b之前返回-1，相等返回0，之后返回1。
+func TotalOrder(a, b ID) int {
+	pa, pb := a.Parse(), b.Parse()
+	if c := compareInt64(pa.timestamp, pb.timestamp); c != 0 {
+		return c
+	}
+	if c := compareInt64(pa.datacenterId, pb.datacenterId); c != 0 {
+		return c
+	}
+	if c := compareInt64(pa.workerId, pb.workerId); c != 0 {
+		return c
+	}
+	return compareInt64(pa.sequence, pb.sequence)
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// TruncateToMs 清除id中的数据id、机器id和毫秒内序列，只保留时间戳，
+// 得到该毫秒内最小的id，可用于按时间分桶或构造范围查询。
+// 时间戳位于id的高位，截断不依赖起始时间，epoch参数仅为与其它按起始时间解析的函数保持一致。
+func TruncateToMs(id int64, epoch time.Time) int64 {
+	return id &^ (1<<timestampLeftShift - 1)
+}
+
+// ParseStrict 按epoch解析id并校验，用于检查外部输入的id：
+// 拒绝负数id（符号位为1），拒绝生成时间早于起始时间或晚于当前时间maxFutureDrift以上的id。
+// maxFutureDrift为0时拒绝任何生成时间在未来的id。
+func ParseStrict(id int64, epoch time.Time, maxFutureDrift time.Duration) (ParsedID, error) {
+	if id < 0 {
+		return ParsedID{}, fmt.Errorf("invalid snowflake id %d: sign bit is set", id)
+	}
+	p := parse(ID(id), epoch.UnixMilli())
+	if p.Time().Before(epoch.Truncate(time.Millisecond)) {
+		return ParsedID{}, fmt.Errorf("invalid snowflake id %d: timestamp predates epoch %v", id, epoch)
+	}
+	if limit := time.Now().Add(maxFutureDrift); p.Time().After(limit) {
+		return ParsedID{}, fmt.Errorf("invalid snowflake id %d: timestamp %v is in the future", id, p.Time())
+	}
+	return p, nil
+}
@@ -0,0 +1,231 @@
+package snowflake
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestID_Redact(t *testing.T) {
+	sf, err := New(int64(3), int64(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+	raw, err := sf.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := ID(raw | 5) // 保证序列不为0
+	p := id.Parse()
+
+	cases := []struct {
+		level                        RedactionLevel
+		sequence, worker, datacenter int64
+	}{
+		{RedactSequence, 0, 3, 7},
+		{RedactWorker, p.Sequence(), 0, 7},
+		{RedactDatacenter, p.Sequence(), 3, 0},
+		{RedactWorker | RedactDatacenter, p.Sequence(), 0, 0},
+		{RedactAll, 0, 0, 0},
+	}
+	for _, c := range cases {
+		r := id.Redact(c.level).Parse()
+		if r.Sequence() != c.sequence || r.WorkerId() != c.worker || r.DatacenterId() != c.datacenter {
+			t.Errorf("Redact(%d) = seq %d worker %d datacenter %d, want %d %d %d", c.level,
+				r.Sequence(), r.WorkerId(), r.DatacenterId(), c.sequence, c.worker, c.datacenter)
+		}
+		if r.Timestamp() != p.Timestamp() {
+			t.Errorf("Redact(%d) timestamp = %d, want %d", c.level, r.Timestamp(), p.Timestamp())
+		}
+	}
+
+	if got := id.Redact(RedactAll).Parse().Time(); !got.Equal(p.Time()) {
+		t.Errorf("Redact(RedactAll).Parse().Time() = %v, want %v", got, p.Time())
+	}
+}
+
+func TestTotalOrder(t *testing.T) {
+	compose := func(ts, dc, worker, seq int64) ID {
+		return ID(ts<<timestampLeftShift | dc<<datacenterIdShift | worker<<workerIdShift | seq)
+	}
+	var ids []ID
+	for _, ts := range []int64{0, 1, 1 << 40} {
+		for _, dc := range []int64{0, 1, maxDatacenterId} {
+			for _, worker := range []int64{0, 1, maxWorkerId} {
+				for _, seq := range []int64{0, 1, sequenceMask} {
+					ids = append(ids, compose(ts, dc, worker, seq))
+				}
+			}
+		}
+	}
+
+	// ids 按字段字典序构造，下标顺序即期望的全序
+	for i, a := range ids {
+		for j, b := range ids {
+			want := compareInt64(int64(i), int64(j))
+			if got := TotalOrder(a, b); got != want {
+				t.Fatalf("TotalOrder(%d, %d) = %d, want %d", a, b, got, want)
+			}
+			if TotalOrder(a, b) != -TotalOrder(b, a) {
+				t.Fatalf("TotalOrder(%d, %d) is not antisymmetric", a, b)
+			}
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	const want = ID(1234567890123456789)
+	inputs := []interface{}{
+		want,
+		int64(want),
+		uint64(want),
+		"1234567890123456789",
+		[]byte("1234567890123456789"),
+		json.Number("1234567890123456789"),
+	}
+	for _, v := range inputs {
+		got, err := Decode(v)
+		if err != nil {
+			t.Errorf("Decode(%T): %v", v, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("Decode(%T) = %d, want %d", v, got, want)
+		}
+	}
+
+	if _, err := Decode(uint64(math.MaxInt64) + 1); err == nil {
+		t.Error("Decode(uint64 overflow) expected error")
+	}
+	if _, err := Decode("12ab"); err == nil {
+		t.Error("Decode(\"12ab\") expected error")
+	}
+	for _, v := range []interface{}{nil, 1.5, int32(1), struct{}{}} {
+		if _, err := Decode(v); !errors.Is(err, ErrUnsupportedType) {
+			t.Errorf("Decode(%T) = %v, want ErrUnsupportedType", v, err)
+		}
+	}
+}
+
+func TestTruncateToMs(t *testing.T) {
+	epoch := time.UnixMilli(twepoch)
+	id := int64(12345)<<timestampLeftShift | 31<<datacenterIdShift | 7<<workerIdShift | 4095
+	got := TruncateToMs(id, epoch)
+	if got != 12345<<timestampLeftShift {
+		t.Errorf("TruncateToMs(%d) = %d, want %d", id, got, int64(12345)<<timestampLeftShift)
+	}
+	if ID(got).Parse().Timestamp() != ID(id).Parse().Timestamp() {
+		t.Error("TruncateToMs changed the timestamp")
+	}
+	if TruncateToMs(got, epoch) != got {
+		t.Error("TruncateToMs is not idempotent")
+	}
+}
+
+func itoa(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
+
+func TestID_Diff(t *testing.T) {
+	a := ID(1000<<timestampLeftShift | 5)
+	b := ID(1250<<timestampLeftShift | 7<<workerIdShift)
+	if d := b.Diff(a); d != 250*time.Millisecond {
+		t.Errorf("b.Diff(a) = %v, want 250ms", d)
+	}
+	if d := a.Diff(b); d != -250*time.Millisecond {
+		t.Errorf("a.Diff(b) = %v, want -250ms", d)
+	}
+	// 同一毫秒内序列不同
+	if d := a.Diff(a + 3); d != 0 {
+		t.Errorf("same millisecond Diff = %v, want 0", d)
+	}
+}
+
+func TestID_GoString(t *testing.T) {
+	var _ fmt.GoStringer = ID(0)
+	if got := fmt.Sprintf("%#v", ID(1234567890)); got != "snowflake.ID(1234567890)" {
+		t.Errorf("%%#v = %q", got)
+	}
+	if got := fmt.Sprintf("%#v", []ID{1, -2}); got != "[]snowflake.ID{snowflake.ID(1), snowflake.ID(-2)}" {
+		t.Errorf("%%#v = %q", got)
+	}
+}
+
+func TestParseStrict(t *testing.T) {
+	epoch := time.UnixMilli(twepoch)
+	sf, err := NewUnregistered(1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := sf.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := ParseStrict(id, epoch, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p != ID(id).Parse() {
+		t.Errorf("ParseStrict = %+v, want %+v", p, ID(id).Parse())
+	}
+
+	elapsed := time.Now().UnixMilli() - twepoch
+	future := (elapsed + 60000) << timestampLeftShift // 1分钟之后
+	if _, err := ParseStrict(future, epoch, 0); err == nil {
+		t.Error("future id expected error with zero drift")
+	}
+	if _, err := ParseStrict(future, epoch, 30*time.Second); err == nil {
+		t.Error("future id expected error with 30s drift")
+	}
+	if _, err := ParseStrict(future, epoch, 2*time.Minute); err != nil {
+		t.Errorf("future id within drift: %v", err)
+	}
+	if _, err := ParseStrict(-id, epoch, time.Hour); err == nil {
+		t.Error("negative id expected error")
+	}
+	if _, err := ParseStrict(0, epoch, 0); err != nil {
+		t.Errorf("ParseStrict(0): %v", err)
+	}
+}
+
+func TestID_PadLeft(t *testing.T) {
+	cases := []struct {
+		id    ID
+		width int
+		char  rune
+		want  string
+	}{
+		{1234, 8, '0', "00001234"},
+		{1234, 6, ' ', "  1234"},
+		{1234, 4, '0', "1234"},
+		{math.MaxInt64, 19, '0', "9223372036854775807"},
+		{1234567890123456789, 25, '0', "0000001234567890123456789"},
+		{42, 5, '＊', "＊＊＊42"},
+		{-42, 5, '0', "-0042"},
+	}
+	for _, c := range cases {
+		if got := c.id.PadLeft(c.width, c.char); got != c.want {
+			t.Errorf("PadLeft(%d, %d, %q) = %q, want %q", c.id, c.width, c.char, got, c.want)
+		}
+	}
+
+	for _, c := range []struct {
+		id    ID
+		width int
+	}{{0, 0}, {1234, 3}} {
+		func() {
+			defer func() {
+				err, _ := recover().(error)
+				if !errors.Is(err, ErrWidthTooSmall) {
+					t.Errorf("PadLeft(%d, %d) panicked with %v, want ErrWidthTooSmall", c.id, c.width, err)
+				}
+			}()
+			c.id.PadLeft(c.width, '0')
+		}()
+	}
+}
@@ -0,0 +1,29 @@
+package snowflake
+
+import (
+	"fmt"
+	"time"
+)
+
+// WithStartupJitter 创建生成器时随机等待[0, maxJitter]，大量节点同时启动（如滚动重启）时，
+// 避免所有节点在同一毫秒从序列0开始生成id，造成以id为主键的数据库出现热点。
+func WithStartupJitter(maxJitter time.Duration) Option {
+	return func(s *Snowflake) error {
+		if maxJitter < 0 {
+			return fmt.Errorf("startup jitter can't be negative")
+		}
+		s.startupJitter = maxJitter
+		return nil
+	}
+}
+
+// WithSleeper 替换等待使用的函数，默认为 time.Sleep，主要用于测试
+func WithSleeper(fn func(time.Duration)) Option {
+	return func(s *Snowflake) error {
+		if fn == nil {
+			return fmt.Errorf("sleeper can't be nil")
+		}
+		s.sleep = fn
+		return nil
+	}
+}
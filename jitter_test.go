@@ -0,0 +1,42 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithStartupJitter(t *testing.T) {
+	const max = 50 * time.Millisecond
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 20; i++ {
+		var slept []time.Duration
+		sleeper := func(d time.Duration) { slept = append(slept, d) }
+		if _, err := NewUnregistered(1, 1, WithStartupJitter(max), WithSleeper(sleeper)); err != nil {
+			t.Fatal(err)
+		}
+		if len(slept) != 1 {
+			t.Fatalf("slept %d times, want 1", len(slept))
+		}
+		if slept[0] < 0 || slept[0] > max {
+			t.Fatalf("slept %v, want within [0, %v]", slept[0], max)
+		}
+		seen[slept[0]] = true
+	}
+	if len(seen) < 2 {
+		t.Error("startup jitter is not random")
+	}
+
+	// 未启用时不等待
+	if _, err := NewUnregistered(1, 1, WithSleeper(func(d time.Duration) {
+		t.Errorf("unexpected sleep %v", d)
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewUnregistered(1, 1, WithStartupJitter(-time.Second)); err == nil {
+		t.Error("negative jitter expected error")
+	}
+	if _, err := NewUnregistered(1, 1, WithSleeper(nil)); err == nil {
+		t.Error("nil sleeper expected error")
+	}
+}
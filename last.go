@@ -0,0 +1,22 @@
+package snowflake
+
+import "time"
+
+// LastGeneratedID 最近一次成功返回的id，还没有生成过id时为0，用于排查问题。
+// 需要获取生成id的锁，不要在热点路径上频繁调用。
+func (s *Snowflake) LastGeneratedID() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastId
+}
+
+// LastGeneratedAt 最近一次生成id使用的时间戳，即 time.UnixMilli(lastTimestamp)，
+// 序列用尽或 NextIdAfter 推进后可能晚于系统时间。还没有生成过id时为零值。同样需要获取锁。
+func (s *Snowflake) LastGeneratedAt() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lastId == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(s.lastTimestamp)
+}
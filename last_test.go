@@ -0,0 +1,52 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLastGeneratedID(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, err := NewUnregistered(1, 2, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := sf.LastGeneratedID(); id != 0 {
+		t.Errorf("LastGeneratedID before generating = %d, want 0", id)
+	}
+	if at := sf.LastGeneratedAt(); !at.IsZero() {
+		t.Errorf("LastGeneratedAt before generating = %v, want zero", at)
+	}
+
+	for i := 0; i < 5; i++ {
+		id, err := sf.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if last := sf.LastGeneratedID(); last != id {
+			t.Fatalf("LastGeneratedID = %d, want %d", last, id)
+		}
+		clock.Add(time.Millisecond)
+	}
+	if at := sf.LastGeneratedAt(); !at.Equal(time.UnixMilli(twepoch + 1004)) {
+		t.Errorf("LastGeneratedAt = %v, want %v", at, time.UnixMilli(twepoch+1004))
+	}
+
+	first, count, err := sf.NextIdN(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := sf.LastGeneratedID(); last != first+int64(count)-1 {
+		t.Errorf("LastGeneratedID after NextIdN = %d, want %d", last, first+int64(count)-1)
+	}
+
+	// 生成失败时不变
+	before := sf.LastGeneratedID()
+	clock.Add(-time.Second)
+	if _, err := sf.NextId(); err == nil {
+		t.Fatal("expected clock moved backwards error")
+	}
+	if last := sf.LastGeneratedID(); last != before {
+		t.Errorf("LastGeneratedID after failure = %d, want %d", last, before)
+	}
+}
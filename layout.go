@@ -0,0 +1,258 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrLayoutMismatch      = errors.New("snowflake id can't be represented in the target layout")
+	ErrInvalidWorkerID     = errors.New("invalid snowflake worker id")
+	ErrInvalidDatacenterID = errors.New("invalid snowflake datacenter id")
+)
+
+// BitLayout id中各字段所占的位数，从高到低依次为时间戳、数据id、机器id、毫秒内序列，合计63位
+type BitLayout struct {
+	TimestampBits  uint8
+	DatacenterBits uint8
+	WorkerBits     uint8
+	SequenceBits   uint8
+
+	TimeUnit time.Duration // 时间戳的单位，必须是毫秒的整数倍，0表示1毫秒
+}
+
+// DefaultLayout 本包生成id使用的布局
+var DefaultLayout = BitLayout{
+	TimestampBits:  timestampBits,
+	DatacenterBits: datacenterIdBits,
+	WorkerBits:     workerIdBits,
+	SequenceBits:   sequenceBits,
+}
+
+// SonyflakeLayout 与Sonyflake容量相同的布局：39位时间戳（10毫秒为单位，约可以使用174年）、16位机器id、8位序列，
+// 支持65536个节点，但每个节点每10毫秒最多生成256个id。
+// 字段的顺序仍与本包的其它布局一致，生成的id与Sonyflake的id不通用。
+var SonyflakeLayout = BitLayout{
+	TimestampBits:  39,
+	DatacenterBits: 0,
+	WorkerBits:     16,
+	SequenceBits:   8,
+	TimeUnit:       10 * time.Millisecond,
+}
+
+func (l BitLayout) validate() error {
+	if int(l.TimestampBits)+int(l.DatacenterBits)+int(l.WorkerBits)+int(l.SequenceBits) != 63 {
+		return fmt.Errorf("bit layout %d-%d-%d-%d doesn't add up to 63 bits",
+			l.TimestampBits, l.DatacenterBits, l.WorkerBits, l.SequenceBits)
+	}
+	return validateTimeUnit(l.TimeUnit)
+}
+
+func validateTimeUnit(d time.Duration) error {
+	if d < 0 || d%time.Millisecond != 0 {
+		return fmt.Errorf("time unit %v must be a non-negative multiple of 1ms", d)
+	}
+	return nil
+}
+
+// unit 时间戳单位的毫秒数
+func (l BitLayout) unit() int64 {
+	if l.TimeUnit <= time.Millisecond {
+		return 1
+	}
+	return l.TimeUnit.Milliseconds()
+}
+
+// minTimestampBits 自定义布局时时间戳至少占的位数，35位约可以使用一年
+const minTimestampBits = 35
+
+// WithWorkerBits 机器id占bits位，默认5位。与 WithDatacenterBits、WithSequenceBits 一起调整布局，
+// 在节点数、每毫秒吞吐和可用年限之间取舍：剩余的位数都留给时间戳，时间戳至少需要35位（约1年），
+// 例如机器id 8位、数据id 0位、序列14位时时间戳为41位。
+// 解析时使用 ParseWithLayout 并传入与 Config 一致的布局。
+func WithWorkerBits(bits uint8) Option {
+	return func(s *Snowflake) error {
+		s.layout.WorkerBits = bits
+		return nil
+	}
+}
+
+// WithDatacenterBits 数据id占bits位，默认5位，为0时只使用机器id区分节点。参见 WithWorkerBits
+func WithDatacenterBits(bits uint8) Option {
+	return func(s *Snowflake) error {
+		s.layout.DatacenterBits = bits
+		return nil
+	}
+}
+
+// WithSequenceBits 毫秒内序列占bits位，默认12位，每毫秒最多生成 1<<bits 个id。参见 WithWorkerBits
+func WithSequenceBits(bits uint8) Option {
+	return func(s *Snowflake) error {
+		if bits == 0 {
+			return fmt.Errorf("sequence bits must be positive")
+		}
+		s.layout.SequenceBits = bits
+		return nil
+	}
+}
+
+// WithTimeUnit 时间戳以d为单位，默认1毫秒，d必须是毫秒的整数倍。
+// 单位越大可用年限越长，但毫秒内序列变为每个单位时间内的序列，吞吐相应降低。参见 WithWorkerBits
+func WithTimeUnit(d time.Duration) Option {
+	return func(s *Snowflake) error {
+		if err := validateTimeUnit(d); err != nil {
+			return err
+		}
+		s.layout.TimeUnit = d
+		return nil
+	}
+}
+
+// WithLayout 使用layout中的数据id、机器id、毫秒内序列的位数和时间单位，时间戳的位数由剩余的位数决定
+func WithLayout(layout BitLayout) Option {
+	return func(s *Snowflake) error {
+		if err := validateTimeUnit(layout.TimeUnit); err != nil {
+			return err
+		}
+		if layout.SequenceBits == 0 {
+			return fmt.Errorf("sequence bits must be positive")
+		}
+		s.layout = layout
+		return nil
+	}
+}
+
+// NewSonyflakeLayout 按 SonyflakeLayout 创建生成器，machineID为0到65535，适用于节点多、
+// 需要更长使用年限而不需要很高单节点吞吐的部署。解析时使用 ParseWithLayout(id, SonyflakeLayout, epoch)
+func NewSonyflakeLayout(machineID int64, opts ...Option) (*Snowflake, error) {
+	opts = append([]Option{WithLayout(SonyflakeLayout), WithWorkerID(machineID)}, opts...)
+	return NewWithOptions(opts...)
+}
+
+// WithWorkerID 设置机器id，用于 NewWithOptions。New 等构造函数的参数会覆盖这一配置
+func WithWorkerID(workerId int64) Option {
+	return func(s *Snowflake) error {
+		s.workerId = workerId
+		return nil
+	}
+}
+
+// WithDatacenterID 设置数据id，用于 NewWithOptions。New 等构造函数的参数会覆盖这一配置
+func WithDatacenterID(datacenterId int64) Option {
+	return func(s *Snowflake) error {
+		s.datacenterId = datacenterId
+		return nil
+	}
+}
+
+// NewWithOptions 完全由配置创建生成器，节点由 WithWorkerID、WithDatacenterID 指定，默认为0，例如
+//
+//	snowflake.NewWithOptions(snowflake.WithWorkerBits(8), snowflake.WithDatacenterBits(0),
+//		snowflake.WithSequenceBits(14), snowflake.WithWorkerID(200), snowflake.WithEpoch(epoch))
+//
+// 与 New 一样登记到进程内的节点注册表，不再使用时需要调用 Close。
+func NewWithOptions(opts ...Option) (*Snowflake, error) {
+	s, err := applyOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	return s.init(true)
+}
+
+// initLayout 检查布局和节点，计算各字段的位置，调用方在应用配置之后调用
+func (s *Snowflake) initLayout() error {
+	l := &s.layout
+	used := int(l.DatacenterBits) + int(l.WorkerBits) + int(l.SequenceBits)
+	if 63-used < minTimestampBits {
+		return fmt.Errorf("datacenter, worker and sequence bits leave %d bits for the timestamp, need at least %d", 63-used, minTimestampBits)
+	}
+	l.TimestampBits = uint8(63 - used)
+	if l.TimeUnit == time.Millisecond {
+		l.TimeUnit = 0
+	}
+	s.workerShift = l.SequenceBits
+	s.datacenterShift = l.SequenceBits + l.WorkerBits
+	s.timestampShift = s.datacenterShift + l.DatacenterBits
+	s.timestampMax = bitMask(l.TimestampBits)
+
+	if max := bitMask(l.WorkerBits); s.workerId < 0 || s.workerId > max {
+		return fmt.Errorf("%w %d: can't be greater than %d or less than 0", ErrInvalidWorkerID, s.workerId, max)
+	}
+	if max := bitMask(l.DatacenterBits); s.datacenterId < 0 || s.datacenterId > max {
+		return fmt.Errorf("%w %d: can't be greater than %d or less than 0", ErrInvalidDatacenterID, s.datacenterId, max)
+	}
+
+	s.sequenceMask = bitMask(l.SequenceBits)
+	switch {
+	case s.versionBits != 0:
+		if s.versionBits >= l.SequenceBits {
+			return fmt.Errorf("version bits must be between 1 and %d", int(l.SequenceBits)-1)
+		}
+		s.sequenceMask >>= s.versionBits
+		s.version = s.versionValue << (l.SequenceBits - s.versionBits)
+	case s.datacenterVersionBits != 0:
+		if s.datacenterVersionBits >= l.DatacenterBits {
+			return fmt.Errorf("datacenter version bits must be between 1 and %d", int(l.DatacenterBits)-1)
+		}
+		if max := bitMask(l.DatacenterBits) >> s.datacenterVersionBits; s.datacenterId > max {
+			return fmt.Errorf("%w %d: can't be greater than %d with %d version bits", ErrInvalidDatacenterID, s.datacenterId, max, s.datacenterVersionBits)
+		}
+		s.version = s.versionValue << (s.timestampShift - s.datacenterVersionBits)
+	}
+	return nil
+}
+
+// ParseWithLayout 按指定的布局和起始时间解析id，用于自定义布局的生成器，layout中的 TimestampBits 不使用
+func ParseWithLayout(id int64, layout BitLayout, epoch time.Time) ParsedID {
+	return parseLayout(ID(id), epoch.UnixMilli(), layout)
+}
+
+func parseLayout(id ID, epoch int64, l BitLayout) ParsedID {
+	workerShift := l.SequenceBits
+	datacenterShift := workerShift + l.WorkerBits
+	return ParsedID{
+		id:           id,
+		timestamp:    (int64(id)>>(datacenterShift+l.DatacenterBits))*l.unit() + epoch,
+		datacenterId: (int64(id) >> datacenterShift) & bitMask(l.DatacenterBits),
+		workerId:     (int64(id) >> workerShift) & bitMask(l.WorkerBits),
+		sequence:     int64(id) & bitMask(l.SequenceBits),
+	}
+}
+
+// MigrateID 把按from布局、fromEpoch起始时间生成的id重新编码为to布局、toEpoch起始时间的id，用于迁移历史数据。
+// 生成时间和毫秒内序列保持不变，无法表示时返回 ErrLayoutMismatch；
+// 原来的数据id和机器id合并为一个节点号（数据id在高位），按to布局重新拆分，
+// to布局的节点位数较少时只保留节点号的低位，不同节点的id迁移后可能重复。
+func MigrateID(id int64, from, to BitLayout, fromEpoch, toEpoch time.Time) (int64, error) {
+	if err := from.validate(); err != nil {
+		return 0, err
+	}
+	if err := to.validate(); err != nil {
+		return 0, err
+	}
+	if id < 0 {
+		return 0, fmt.Errorf("invalid snowflake id %d: ids can't be negative", id)
+	}
+
+	sequence := id & bitMask(from.SequenceBits)
+	node := id >> from.SequenceBits & bitMask(from.DatacenterBits+from.WorkerBits)
+	timestamp := (id>>(63-from.TimestampBits))*from.unit() + fromEpoch.UnixMilli() - toEpoch.UnixMilli()
+	if timestamp >= 0 {
+		timestamp /= to.unit()
+	}
+
+	if timestamp < 0 || timestamp > bitMask(to.TimestampBits) {
+		return 0, fmt.Errorf("%w: timestamp of id %d is out of range", ErrLayoutMismatch, id)
+	}
+	if sequence > bitMask(to.SequenceBits) {
+		return 0, fmt.Errorf("%w: sequence %d of id %d needs more than %d bits", ErrLayoutMismatch, sequence, id, to.SequenceBits)
+	}
+	node &= bitMask(to.DatacenterBits + to.WorkerBits)
+	return timestamp<<(63-to.TimestampBits) | node<<to.SequenceBits | sequence, nil
+}
+
+// bitMask 低bits位为1
+func bitMask(bits uint8) int64 {
+	return -1 ^ (-1 << bits)
+}
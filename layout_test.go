@@ -0,0 +1,192 @@
+package snowflake
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMigrateID(t *testing.T) {
+	epoch := time.UnixMilli(twepoch)
+	flat := BitLayout{TimestampBits: 41, DatacenterBits: 0, WorkerBits: 10, SequenceBits: 12}
+	id := int64(5000)<<timestampLeftShift | 3<<datacenterIdShift | 7<<workerIdShift | 42
+
+	// 5-5-12 到 0-10-12：节点号为 3<<5|7
+	got, err := MigrateID(id, DefaultLayout, flat, epoch, epoch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != id {
+		t.Errorf("same width layout changed bits: %d, want %d", got, id)
+	}
+	back, err := MigrateID(got, flat, DefaultLayout, epoch, epoch)
+	if err != nil || back != id {
+		t.Errorf("migrate back = %d, %v, want %d", back, err, id)
+	}
+
+	// 起始时间提前1秒，时间戳加1000
+	got, err = MigrateID(id, DefaultLayout, DefaultLayout, epoch, epoch.Add(-time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := ID(got).Parse(); p.Timestamp()-twepoch != 6000 || p.DatacenterId() != 3 || p.WorkerId() != 7 || p.Sequence() != 42 {
+		t.Errorf("epoch shift parsed as %+v", p)
+	}
+	if !ParseWithEpoch(got, epoch.Add(-time.Second)).Time().Equal(ID(id).Time()) {
+		t.Error("generation time changed")
+	}
+
+	// 节点位数减少时只保留低位：43-2-6-12，节点号 3<<5|7 = 103，保留低8位
+	narrow := BitLayout{TimestampBits: 43, DatacenterBits: 2, WorkerBits: 6, SequenceBits: 12}
+	got, err = MigrateID(id, DefaultLayout, narrow, epoch, epoch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node := got >> 12 & 0xff; node != 103 || got>>20 != 5000 || got&0xfff != 42 {
+		t.Errorf("narrow layout = timestamp %d node %d sequence %d", got>>20, node, got&0xfff)
+	}
+
+	cases := []struct {
+		name     string
+		to       BitLayout
+		toEpoch  time.Time
+		mismatch bool
+	}{
+		{"sequence too wide", BitLayout{TimestampBits: 48, DatacenterBits: 5, WorkerBits: 5, SequenceBits: 5}, epoch, true},
+		{"timestamp too wide", BitLayout{TimestampBits: 10, DatacenterBits: 20, WorkerBits: 21, SequenceBits: 12}, epoch, true},
+		{"before target epoch", DefaultLayout, epoch.Add(time.Hour), true},
+		{"invalid layout", BitLayout{TimestampBits: 41, WorkerBits: 10, SequenceBits: 10}, epoch, false},
+	}
+	for _, c := range cases {
+		_, err := MigrateID(id, DefaultLayout, c.to, epoch, c.toEpoch)
+		if err == nil {
+			t.Errorf("%s: expected error", c.name)
+		} else if errors.Is(err, ErrLayoutMismatch) != c.mismatch {
+			t.Errorf("%s: err = %v", c.name, err)
+		}
+	}
+}
+
+func TestNewWithOptionsLayout(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	s, err := NewWithOptions(WithWorkerBits(8), WithDatacenterBits(0), WithSequenceBits(14),
+		WithWorkerID(200), WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	layout := BitLayout{DatacenterBits: 0, WorkerBits: 8, SequenceBits: 14}
+	var prev int64
+	for i := 0; i < 1<<14+10; i++ {
+		id, err := s.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id <= prev {
+			t.Fatalf("id %d isn't greater than %d", id, prev)
+		}
+		prev = id
+		if i == 1<<14-1 {
+			clock.Add(time.Millisecond)
+		}
+	}
+	p := ParseWithLayout(prev, layout, time.UnixMilli(twepoch))
+	if p.WorkerId() != 200 || p.DatacenterId() != 0 || p.Sequence() != 9 || p.Timestamp() != twepoch+1001 {
+		t.Errorf("parsed %+v", p)
+	}
+
+	cfg := s.Config()
+	if cfg.TimestampBits != 41 || cfg.WorkerIdBits != 8 || cfg.SequenceBits != 14 || cfg.MaxIdsPerMs != 1<<14 {
+		t.Errorf("config = %+v", cfg)
+	}
+	if want := time.UnixMilli(twepoch + 1<<41); !s.ExpiresAt().Equal(want) {
+		t.Errorf("ExpiresAt = %v, want %v", s.ExpiresAt(), want)
+	}
+	if _, err := s.NextIdWithPriority(0); err == nil {
+		t.Error("priority ids with a custom layout should fail")
+	}
+}
+
+func TestNewWithOptionsInvalidLayout(t *testing.T) {
+	if _, err := NewWithOptions(WithWorkerBits(16), WithSequenceBits(8)); err == nil {
+		t.Error("timestamp shorter than 35 bits should fail")
+	}
+	if _, err := NewWithOptions(WithWorkerBits(4), WithWorkerID(16)); !errors.Is(err, ErrInvalidWorkerID) {
+		t.Errorf("worker id out of range: %v, want ErrInvalidWorkerID", err)
+	}
+	if _, err := NewUnregistered(1, 32); !errors.Is(err, ErrInvalidDatacenterID) {
+		t.Errorf("datacenter id out of range: %v, want ErrInvalidDatacenterID", err)
+	}
+	if _, err := NewAtomic(-1, 0); !errors.Is(err, ErrInvalidWorkerID) {
+		t.Errorf("NewAtomic worker id out of range: %v, want ErrInvalidWorkerID", err)
+	}
+	if _, err := NewWithOptions(WithSequenceBits(0)); err == nil {
+		t.Error("zero sequence bits should fail")
+	}
+	s, err := NewWithOptions(WithWorkerID(31), WithDatacenterID(31))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	id, _ := s.NextId()
+	if p := ID(id).Parse(); p.WorkerId() != 31 || p.DatacenterId() != 31 {
+		t.Errorf("default layout parsed %+v", p)
+	}
+}
+
+func TestNewSonyflakeLayout(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1005))
+	s, err := NewSonyflakeLayout(60000, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	epoch := time.UnixMilli(twepoch)
+	var ids []int64
+	for i := 0; i < 257; i++ {
+		if i == 256 { // 10毫秒内的256个序列已用尽
+			clock.Add(10 * time.Millisecond)
+		}
+		id, err := s.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	first := ParseWithLayout(ids[0], SonyflakeLayout, epoch)
+	if first.WorkerId() != 60000 || first.DatacenterId() != 0 || first.Sequence() != 0 || first.Timestamp() != twepoch+1000 {
+		t.Errorf("first id parsed as %+v", first)
+	}
+	if p := s.Decompose(ids[255]); p.Sequence() != 255 || p.Timestamp() != twepoch+1000 {
+		t.Errorf("id 255 parsed as %+v", p)
+	}
+	if p := s.Decompose(ids[256]); p.Sequence() != 0 || p.Timestamp() != twepoch+1010 {
+		t.Errorf("id 256 parsed as %+v", p)
+	}
+
+	if want := time.UnixMilli(twepoch + 1<<39*10); !s.ExpiresAt().Equal(want) {
+		t.Errorf("ExpiresAt = %v, want %v", s.ExpiresAt(), want)
+	}
+	cfg := s.Config()
+	if cfg.TimestampBits != 39 || cfg.WorkerIdBits != 16 || cfg.SequenceBits != 8 || cfg.TimeUnitMs != 10 || cfg.MaxIdsPerMs != 25 {
+		t.Errorf("config = %+v", cfg)
+	}
+
+	// 迁移到默认布局后生成时间不变
+	migrated, err := MigrateID(ids[256], SonyflakeLayout, DefaultLayout, epoch, epoch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts := ID(migrated).Parse().Timestamp(); ts != twepoch+1010 {
+		t.Errorf("migrated timestamp = %d, want %d", ts, twepoch+1010)
+	}
+
+	if _, err := NewSonyflakeLayout(1 << 16); err == nil {
+		t.Error("machine id over 16 bits should fail")
+	}
+	if _, err := NewWithOptions(WithTimeUnit(1500 * time.Microsecond)); err == nil {
+		t.Error("time unit that isn't a whole number of milliseconds should fail")
+	}
+}
@@ -0,0 +1,86 @@
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrLeaseLost = errors.New("snowflake worker id lease lost")
+
+// LeaseRenewer 续期机器id的租约，如etcd的lease、ZooKeeper的会话
+type LeaseRenewer interface {
+	Renew(ctx context.Context) error
+}
+
+// WithLeaseTTL 设置机器id租约的有效期，StartLeaseRenewal 每隔ttl的一半续期一次
+func WithLeaseTTL(ttl time.Duration) Option {
+	return func(s *Snowflake) error {
+		if ttl <= 0 {
+			return fmt.Errorf("lease ttl must be positive")
+		}
+		s.leaseTTL = ttl
+		return nil
+	}
+}
+
+// StartLeaseRenewal 启动后台goroutine，每隔租约有效期（WithLeaseTTL）的一半调用一次renewer.Renew，
+// ctx结束时停止。每次续期最多等待有效期的一半，超时视为失败。距离上一次续期成功（或启动）已经达到有效期时，
+// 租约可能已被其它进程拿到，生成器进入降级状态，之后生成id都返回 ErrLeaseLost，并且不再续期。每个生成器只能启动一次。
+func (s *Snowflake) StartLeaseRenewal(ctx context.Context, renewer LeaseRenewer) error {
+	if s.leaseTTL == 0 {
+		return fmt.Errorf("lease ttl is not set, use WithLeaseTTL")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.leaseStarted {
+		return fmt.Errorf("lease renewal already started")
+	}
+	s.leaseStarted = true
+	go s.renewLease(ctx, renewer)
+	return nil
+}
+
+func (s *Snowflake) renewLease(ctx context.Context, renewer LeaseRenewer) {
+	ticker := time.NewTicker(s.leaseTTL / 2)
+	defer ticker.Stop()
+	renewed := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := s.renewOnce(ctx, renewer); err == nil {
+			renewed = time.Now()
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(renewed) >= s.leaseTTL {
+			s.leaseLost.Store(true)
+			return
+		}
+	}
+}
+
+// renewOnce 续期一次，最多等待有效期的一半。renewer不响应ctx时不再等待它返回
+func (s *Snowflake) renewOnce(ctx context.Context, renewer LeaseRenewer) error {
+	ctx, cancel := context.WithTimeout(ctx, s.leaseTTL/2)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- renewer.Renew(ctx) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// IsDegraded 租约是否已经丢失，丢失后生成id都返回 ErrLeaseLost
+func (s *Snowflake) IsDegraded() bool {
+	return s.leaseLost.Load()
+}
@@ -0,0 +1,150 @@
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRenewer 按需失败的租约续期
+type fakeRenewer struct {
+	mu    sync.Mutex
+	fail  bool
+	calls int
+}
+
+func (r *fakeRenewer) Renew(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls++
+	if r.fail {
+		return errors.New("lease not found")
+	}
+	return nil
+}
+
+func (r *fakeRenewer) setFail(fail bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fail = fail
+}
+
+func (r *fakeRenewer) callCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.calls
+}
+
+// waitFor 等待cond成立，最多1秒
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within 1s")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestStartLeaseRenewal(t *testing.T) {
+	sf, err := NewUnregistered(1, 1, WithLeaseTTL(20*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &fakeRenewer{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := sf.StartLeaseRenewal(ctx, r); err != nil {
+		t.Fatal(err)
+	}
+	if err := sf.StartLeaseRenewal(ctx, r); err == nil {
+		t.Error("second StartLeaseRenewal expected error")
+	}
+	waitFor(t, func() bool { return r.callCount() >= 3 })
+
+	// 有效期内偶尔失败不影响生成
+	r.setFail(true)
+	n := r.callCount()
+	waitFor(t, func() bool { return r.callCount() >= n+1 })
+	r.setFail(false)
+	n = r.callCount()
+	waitFor(t, func() bool { return r.callCount() >= n+2 })
+	if sf.IsDegraded() {
+		t.Fatal("degraded after 1 failure")
+	}
+	if _, err := sf.NextId(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 超过有效期没有续期成功后降级
+	r.setFail(true)
+	waitFor(t, sf.IsDegraded)
+	if _, err := sf.NextId(); !errors.Is(err, ErrLeaseLost) {
+		t.Errorf("NextId after lease lost = %v, want ErrLeaseLost", err)
+	}
+	n = r.callCount()
+	time.Sleep(50 * time.Millisecond)
+	if c := r.callCount(); c != n {
+		t.Errorf("renewed %d more times after lease lost", c-n)
+	}
+}
+
+// blockingRenewer 续期在release关闭前不返回，模拟网络分区，也不响应ctx
+type blockingRenewer struct {
+	release chan struct{}
+}
+
+func (r blockingRenewer) Renew(ctx context.Context) error {
+	<-r.release
+	return nil
+}
+
+func TestStartLeaseRenewal_Blocking(t *testing.T) {
+	sf, err := NewUnregistered(1, 1, WithLeaseTTL(20*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := blockingRenewer{release: make(chan struct{})}
+	defer close(r.release)
+	start := time.Now()
+	if err := sf.StartLeaseRenewal(ctx, r); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, sf.IsDegraded)
+	if d := time.Since(start); d < 20*time.Millisecond {
+		t.Errorf("degraded after %v, want at least the lease ttl", d)
+	}
+	if _, err := sf.NextId(); !errors.Is(err, ErrLeaseLost) {
+		t.Errorf("NextId after lease lost = %v, want ErrLeaseLost", err)
+	}
+}
+
+func TestStartLeaseRenewal_Cancel(t *testing.T) {
+	sf, err := NewUnregistered(1, 1, WithLeaseTTL(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &fakeRenewer{}
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := sf.StartLeaseRenewal(ctx, r); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return r.callCount() >= 1 })
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+	n := r.callCount()
+	time.Sleep(50 * time.Millisecond)
+	if c := r.callCount(); c != n {
+		t.Errorf("renewed %d times after cancel", c-n)
+	}
+
+	noTTL, _ := NewUnregistered(1, 1)
+	if err := noTTL.StartLeaseRenewal(context.Background(), r); err == nil {
+		t.Error("StartLeaseRenewal without ttl expected error")
+	}
+}
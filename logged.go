@@ -0,0 +1,36 @@
+package snowflake
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LoggedSnowflake 把每个生成的id记录到 slog.Logger 的生成器，用于需要留存全部id的合规场景
+type LoggedSnowflake struct {
+	inner  *Snowflake
+	logger *slog.Logger
+	level  slog.Level
+}
+
+// NewLogged 包装inner，NextId 生成的每个id及生成失败的错误都以level级别记录到logger
+func NewLogged(inner *Snowflake, logger *slog.Logger, level slog.Level) *LoggedSnowflake {
+	return &LoggedSnowflake{inner: inner, logger: logger, level: level}
+}
+
+// NextId 生成id并在返回前记录，记录中包含按inner的配置解析出的时间戳、机器id、数据id和毫秒内序列
+func (l *LoggedSnowflake) NextId() (int64, error) {
+	id, err := l.inner.NextId()
+	if err != nil {
+		l.logger.LogAttrs(context.Background(), l.level, "snowflake id generation failed",
+			slog.String("error", err.Error()))
+		return 0, err
+	}
+	p := l.inner.parse(id)
+	l.logger.LogAttrs(context.Background(), l.level, "snowflake id generated",
+		IDAttr("id", id),
+		slog.Int64("timestamp", p.Timestamp()),
+		slog.Int64("worker_id", p.WorkerId()),
+		slog.Int64("datacenter_id", p.DatacenterId()),
+		slog.Int64("sequence", p.Sequence()))
+	return id, nil
+}
@@ -0,0 +1,48 @@
+package snowflake
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewLogged(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, err := NewUnregistered(3, 4, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	l := NewLogged(sf, slog.New(slog.NewTextHandler(&buf, nil)), slog.LevelWarn)
+
+	id, err := l.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"level=WARN",
+		`msg="snowflake id generated"`,
+		fmt.Sprintf("id=%d", id),
+		fmt.Sprintf("timestamp=%d", twepoch+1000),
+		"worker_id=3",
+		"datacenter_id=4",
+		"sequence=0",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log %q missing %q", out, want)
+		}
+	}
+
+	buf.Reset()
+	sf.Shutdown()
+	if _, err := l.NextId(); err == nil {
+		t.Fatal("NextId after Shutdown expected error")
+	}
+	if out := buf.String(); !strings.Contains(out, `msg="snowflake id generation failed"`) || !strings.Contains(out, ErrShutdown.Error()) {
+		t.Errorf("error log = %q", out)
+	}
+}
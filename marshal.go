@@ -0,0 +1,71 @@
+package snowflake
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+)
+
+var _ driver.Valuer = ID(0)
+
+// MarshalJSON 编码为十进制的JSON字符串，避免JavaScript按53位精度的浮点数解析时丢失精度
+func (id ID) MarshalJSON() ([]byte, error) {
+	b := make([]byte, 0, 22)
+	b = append(b, '"')
+	b = strconv.AppendInt(b, int64(id), 10)
+	return append(b, '"'), nil
+}
+
+// UnmarshalJSON 解析十进制的JSON字符串，也接受JSON数字，null不改变id
+func (id *ID) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) >= 2 && data[0] == '"' && data[len(data)-1] == '"' {
+		data = data[1 : len(data)-1]
+	}
+	n, err := ParseString(string(data))
+	if err != nil {
+		return err
+	}
+	*id = n
+	return nil
+}
+
+// MarshalText 编码为十进制文本，用于JSON的map键、XML、YAML等
+func (id ID) MarshalText() ([]byte, error) {
+	return strconv.AppendInt(nil, int64(id), 10), nil
+}
+
+// UnmarshalText 解析十进制文本
+func (id *ID) UnmarshalText(text []byte) error {
+	n, err := ParseString(string(text))
+	if err != nil {
+		return err
+	}
+	*id = n
+	return nil
+}
+
+// Value 实现 driver.Valuer，按 int64 写入数据库
+func (id ID) Value() (driver.Value, error) {
+	return int64(id), nil
+}
+
+// Scan 实现 sql.Scanner，支持整数列和十进制的字符串列，NULL返回错误，可空的列请使用 sql.Null[ID]
+func (id *ID) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case int64:
+		*id = ID(v)
+		return nil
+	case []byte:
+		return id.UnmarshalText(v)
+	case string:
+		return id.UnmarshalText([]byte(v))
+	case nil:
+		return fmt.Errorf("snowflake: cannot scan NULL into ID")
+	default:
+		return fmt.Errorf("%w: cannot scan %T into ID", ErrUnsupportedType, src)
+	}
+}
@@ -0,0 +1,67 @@
+package snowflake
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+)
+
+func TestID_JSON(t *testing.T) {
+	type payload struct {
+		ID   ID          `json:"id"`
+		Refs map[ID]bool `json:"refs"`
+	}
+	id := ID(1<<62 + 1) // 超过JavaScript的53位安全整数
+	b, err := json.Marshal(payload{ID: id, Refs: map[ID]bool{id: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"id":"4611686018427387905","refs":{"4611686018427387905":true}}`
+	if string(b) != want {
+		t.Errorf("Marshal = %s, want %s", b, want)
+	}
+	var got payload
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != id || !got.Refs[id] {
+		t.Errorf("Unmarshal = %+v", got)
+	}
+
+	for in, want := range map[string]ID{`"42"`: 42, `42`: 42, `null`: 7} {
+		v := ID(7)
+		if err := json.Unmarshal([]byte(in), &v); err != nil || v != want {
+			t.Errorf("Unmarshal(%s) = %d, %v, want %d", in, v, err, want)
+		}
+	}
+	var v ID
+	if err := json.Unmarshal([]byte(`"abc"`), &v); err == nil {
+		t.Error("Unmarshal of non-numeric string expected error")
+	}
+}
+
+func TestID_SQL(t *testing.T) {
+	var _ sql.Scanner = (*ID)(nil)
+
+	v, err := ID(42).Value()
+	if err != nil || v != int64(42) {
+		t.Errorf("Value = %v, %v", v, err)
+	}
+	for _, src := range []interface{}{int64(42), []byte("42"), "42"} {
+		var id ID
+		if err := id.Scan(src); err != nil || id != 42 {
+			t.Errorf("Scan(%#v) = %d, %v", src, id, err)
+		}
+	}
+	var id ID
+	if err := id.Scan(nil); err == nil {
+		t.Error("Scan(nil) expected error")
+	}
+	if err := id.Scan(3.5); err == nil {
+		t.Error("Scan(float64) expected error")
+	}
+	var n sql.Null[ID]
+	if err := n.Scan(nil); err != nil || n.Valid {
+		t.Errorf("sql.Null[ID].Scan(nil) = %+v, %v", n, err)
+	}
+}
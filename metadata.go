@@ -0,0 +1,57 @@
+package snowflake
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// WithMetadata 为生成器设置元数据，如 "service": "payments"、"region": "us-east-1"，
+// 会出现在 ConfigJSON、String、启动日志和时钟回退事件中，用于在诊断和监控中识别生成器。
+// meta会被复制，之后修改meta不影响生成器。
+func WithMetadata(meta map[string]string) Option {
+	return func(s *Snowflake) error {
+		s.metadata = copyMetadata(meta)
+		return nil
+	}
+}
+
+// Metadata 返回元数据的副本，未设置时返回nil
+func (s *Snowflake) Metadata() map[string]string {
+	return copyMetadata(s.metadata)
+}
+
+// String 实现 fmt.Stringer，包含节点和按键排序的元数据
+func (s *Snowflake) String() string {
+	return fmt.Sprintf("snowflake(worker=%d, datacenter=%d%s)", s.workerId, s.datacenterId, s.metadataSuffix())
+}
+
+// metadataSuffix 按键排序的元数据，格式为 ", k=v, k=v"，没有元数据时为空
+func (s *Snowflake) metadataSuffix() string {
+	var b strings.Builder
+	for _, k := range s.metadataKeys() {
+		fmt.Fprintf(&b, ", %s=%s", k, s.metadata[k])
+	}
+	return b.String()
+}
+
+// metadataKeys 按字母排序的元数据键
+func (s *Snowflake) metadataKeys() []string {
+	keys := make([]string, 0, len(s.metadata))
+	for k := range s.metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func copyMetadata(meta map[string]string) map[string]string {
+	if len(meta) == 0 {
+		return nil
+	}
+	c := make(map[string]string, len(meta))
+	for k, v := range meta {
+		c[k] = v
+	}
+	return c
+}
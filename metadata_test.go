@@ -0,0 +1,86 @@
+package snowflake
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithMetadata(t *testing.T) {
+	meta := map[string]string{"service": "payments", "region": "us-east-1"}
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	sf, err := NewUnregistered(3, 4, WithMetadata(meta))
+	log.SetOutput(os.Stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(logs.String(), "region=us-east-1, service=payments") {
+		t.Errorf("startup log %q doesn't contain metadata", logs.String())
+	}
+
+	// 复制，互不影响
+	meta["service"] = "changed"
+	got := sf.Metadata()
+	if got["service"] != "payments" || got["region"] != "us-east-1" {
+		t.Fatalf("Metadata() = %v", got)
+	}
+	got["region"] = "changed"
+	if sf.Metadata()["region"] != "us-east-1" {
+		t.Error("Metadata() returned the internal map")
+	}
+
+	if s := sf.String(); s != "snowflake(worker=3, datacenter=4, region=us-east-1, service=payments)" {
+		t.Errorf("String() = %q", s)
+	}
+
+	b, err := sf.ConfigJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c Config
+	if err := json.Unmarshal(b, &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Metadata["service"] != "payments" {
+		t.Errorf("ConfigJSON() = %s", b)
+	}
+
+	plain, err := NewUnregistered(3, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain.Metadata() != nil || plain.String() != "snowflake(worker=3, datacenter=4)" {
+		t.Errorf("without metadata: %v %q", plain.Metadata(), plain.String())
+	}
+	if b, _ := plain.ConfigJSON(); strings.Contains(string(b), "metadata") {
+		t.Errorf("ConfigJSON() without metadata = %s", b)
+	}
+}
+
+func TestWithMetadata_DriftEvent(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 10000))
+	var out lockedBuffer
+	sf, err := NewUnregistered(1, 1, WithClock(clock), WithDriftMonitor(&out, 0),
+		WithMetadata(map[string]string{"service": "payments"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+	sf.NextId()
+	clock.Add(-time.Second)
+	sf.NextId()
+
+	deadline := time.Now().Add(time.Second)
+	for out.String() == "" && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if !strings.Contains(out.String(), `"metadata":{"service":"payments"}`) {
+		t.Errorf("drift event %q doesn't contain metadata", out.String())
+	}
+}
@@ -0,0 +1,108 @@
+package snowflake
+
+import (
+	"fmt"
+	"time"
+)
+
+// migratedBit 迁移后生成的id在时间戳字段的最高位置1作为标记。
+// 旧id的时间戳字段是距旧起始时间的毫秒数，只要迁移发生在旧起始时间之后约34年内，
+// 这一位就不会为1，因此新旧id可以无歧义地区分，并且所有新id都大于旧id。
+const migratedBit = int64(1) << (timestampBits - 1)
+
+// MigratingSnowflake 更换起始时间期间使用的生成器。
+// 新生成的id使用新的起始时间，时间戳字段的最高位置1作为标记，毫秒内序列不受影响；
+// 新的起始时间之后约34年内有效。
+// 迁移期间还没有升级的下游仍然需要旧格式的id时，可以用 NextLegacyId 同时按旧起始时间生成，
+// 两种id由标记位区分，不会重复。
+type MigratingSnowflake struct {
+	*Snowflake
+	legacy   *Snowflake // 按旧起始时间生成id
+	oldEpoch time.Time
+	newEpoch time.Time
+}
+
+// NewMigrating 创建使用newEpoch生成id的生成器，opts同时用于 NextLegacyId 使用的旧格式生成器。
+// 创建后不应再使用其它oldEpoch的生成器，否则旧id可能晚于新id。
+func NewMigrating(oldEpoch, newEpoch time.Time, workerID, datacenterID int64, opts ...Option) (*MigratingSnowflake, error) {
+	if !newEpoch.After(oldEpoch) {
+		return nil, fmt.Errorf("new epoch %v must be after old epoch %v", newEpoch, oldEpoch)
+	}
+	if time.Since(oldEpoch).Milliseconds() >= migratedBit {
+		return nil, fmt.Errorf("old epoch %v is too far in the past to migrate", oldEpoch)
+	}
+	if newEpoch.After(time.Now()) {
+		return nil, fmt.Errorf("epoch %v can't be in the future", newEpoch)
+	}
+	base := opts[:len(opts):len(opts)]
+	// 起始时间提前 migratedBit 毫秒，生成的时间戳字段即为距newEpoch的毫秒数加上标记位
+	s, err := New(workerID, datacenterID, append(base, WithEpoch(newEpoch.Add(-time.Duration(migratedBit)*time.Millisecond)))...)
+	if err != nil {
+		return nil, err
+	}
+	if s.layout != DefaultLayout {
+		s.Close()
+		return nil, fmt.Errorf("migrating generator can't use a custom bit layout")
+	}
+	// 旧格式的id与新id的节点相同，由标记位区分，不登记到节点注册表
+	legacy, err := NewUnregistered(workerID, datacenterID, append(base, WithEpoch(oldEpoch))...)
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	return &MigratingSnowflake{Snowflake: s, legacy: legacy, oldEpoch: oldEpoch, newEpoch: newEpoch}, nil
+}
+
+// NextLegacyId 按旧起始时间生成旧格式的id。
+// 距旧起始时间约34年后，旧格式的时间戳会占用标记位，此时返回 ErrTimestampOverflow
+func (m *MigratingSnowflake) NextLegacyId() (int64, error) {
+	id, err := m.legacy.NextId()
+	if err != nil {
+		return 0, err
+	}
+	if IsMigratedID(id) {
+		return 0, fmt.Errorf("%w: legacy id %d would carry the migration marker", ErrTimestampOverflow, id)
+	}
+	return id, nil
+}
+
+// Migrate 把旧格式的id转换为新格式：生成时间、节点和毫秒内序列不变，改用新的起始时间并加上标记位，
+// 用于迁移历史数据。已经是新格式的id原样返回；生成时间早于新起始时间的id无法表示，返回 ErrLayoutMismatch
+func (m *MigratingSnowflake) Migrate(oldID int64) (int64, error) {
+	if oldID >= 0 && IsMigratedID(oldID) {
+		return oldID, nil
+	}
+	id, err := MigrateID(oldID, DefaultLayout, DefaultLayout, m.oldEpoch, m.newEpoch)
+	if err != nil {
+		return 0, err
+	}
+	if IsMigratedID(id) {
+		return 0, fmt.Errorf("%w: timestamp of id %d is out of range", ErrLayoutMismatch, oldID)
+	}
+	return id | migratedBit<<timestampLeftShift, nil
+}
+
+// Close 停止生成新旧两种格式的id并注销生成器
+func (m *MigratingSnowflake) Close() error {
+	m.legacy.Close()
+	return m.Snowflake.Close()
+}
+
+// IsMigratedID id是否带有迁移后的标记位，即是否由 MigratingSnowflake 按新起始时间生成
+func IsMigratedID(id int64) bool {
+	return (id>>timestampLeftShift)&migratedBit != 0
+}
+
+// ParseMigrating 解析迁移前后生成的id，返回解析结果和id使用的起始时间。
+// 只依赖id本身的标记位，不需要创建生成器。
+func ParseMigrating(id int64, oldEpoch, newEpoch time.Time) (ParsedID, time.Time, error) {
+	if id < 0 {
+		return ParsedID{}, time.Time{}, fmt.Errorf("invalid snowflake id %d", id)
+	}
+	if IsMigratedID(id) {
+		p := parse(ID(id&^(migratedBit<<timestampLeftShift)), newEpoch.UnixMilli())
+		p.id = ID(id)
+		return p, newEpoch, nil
+	}
+	return parse(ID(id), oldEpoch.UnixMilli()), oldEpoch, nil
+}
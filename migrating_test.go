@@ -0,0 +1,177 @@
+package snowflake
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMigratingSnowflake(t *testing.T) {
+	oldEpoch := time.UnixMilli(twepoch)
+	newEpoch := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Now()
+
+	// 迁移前按旧起始时间生成
+	old, err := NewUnregistered(1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldIds := make([]int64, 5000)
+	for i := range oldIds {
+		if oldIds[i], err = old.NextId(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 很久以前的旧id，按新起始时间解析也不在未来
+	march2021 := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, at := range []time.Time{oldEpoch, march2021, now.Add(-(newEpoch.Sub(oldEpoch) + time.Hour))} {
+		ts := at.UnixMilli() - twepoch
+		oldIds = append(oldIds, ts<<timestampLeftShift|2<<datacenterIdShift|1<<workerIdShift|3000)
+	}
+
+	m, err := NewMigrating(oldEpoch, newEpoch, 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	for _, id := range oldIds {
+		p, epoch, err := ParseMigrating(id, oldEpoch, newEpoch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !epoch.Equal(oldEpoch) {
+			t.Fatalf("old id %d detected epoch %v", id, epoch)
+		}
+		if p != ID(id).Parse() {
+			t.Fatalf("old id %d parsed as %+v", id, p)
+		}
+	}
+
+	last := oldIds[4999]
+	for i := 0; i < 5000; i++ {
+		id, err := m.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id <= last {
+			t.Fatalf("new id %d is not greater than %d", id, last)
+		}
+		last = id
+		p, epoch, err := ParseMigrating(id, oldEpoch, newEpoch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !epoch.Equal(newEpoch) {
+			t.Fatalf("new id %d detected epoch %v", id, epoch)
+		}
+		if d := p.Time().Sub(now); d < -time.Second || d > time.Minute {
+			t.Fatalf("new id %d time %v is not close to %v", id, p.Time(), now)
+		}
+		if p.ID() != ID(id) || p.DatacenterId() != 3 || p.WorkerId() != 1 {
+			t.Fatalf("new id %d parsed as %+v", id, p)
+		}
+	}
+
+	if _, _, err := ParseMigrating(-1, oldEpoch, newEpoch); err == nil {
+		t.Error("ParseMigrating(-1) expected error")
+	}
+}
+
+func TestNewMigrating_Invalid(t *testing.T) {
+	oldEpoch := time.UnixMilli(twepoch)
+	if _, err := NewMigrating(oldEpoch, oldEpoch, 1, 1); err == nil {
+		t.Error("same epochs expected error")
+	}
+	if _, err := NewMigrating(oldEpoch, time.Now().Add(time.Hour), 1, 1); err == nil {
+		t.Error("future new epoch expected error")
+	}
+	if _, err := NewMigrating(time.Now().AddDate(-40, 0, 0), oldEpoch, 1, 1); err == nil {
+		t.Error("ancient old epoch expected error")
+	}
+}
+
+func TestMigratingSnowflake_DualIssue(t *testing.T) {
+	oldEpoch := time.UnixMilli(twepoch)
+	newEpoch := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m, err := NewMigrating(oldEpoch, newEpoch, 4, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	seen := make(map[int64]bool)
+	for i := 0; i < 1000; i++ {
+		legacy, err := m.NextLegacyId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, err := m.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if IsMigratedID(legacy) || !IsMigratedID(id) {
+			t.Fatalf("IsMigratedID(%d), IsMigratedID(%d) = %v, %v", legacy, id, IsMigratedID(legacy), IsMigratedID(id))
+		}
+		if seen[legacy] || seen[id] {
+			t.Fatalf("duplicate id %d or %d", legacy, id)
+		}
+		seen[legacy], seen[id] = true, true
+
+		p, epoch, err := ParseMigrating(legacy, oldEpoch, newEpoch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !epoch.Equal(oldEpoch) || p.DatacenterId() != 5 || p.WorkerId() != 4 {
+			t.Fatalf("legacy id %d parsed as %+v with epoch %v", legacy, p, epoch)
+		}
+	}
+}
+
+func TestMigratingSnowflake_Migrate(t *testing.T) {
+	oldEpoch := time.UnixMilli(twepoch)
+	newEpoch := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m, err := NewMigrating(oldEpoch, newEpoch, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	legacy, err := m.NextLegacyId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _, err := ParseMigrating(legacy, oldEpoch, newEpoch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrated, err := m.Migrate(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsMigratedID(migrated) {
+		t.Fatalf("Migrate(%d) = %d without migration marker", legacy, migrated)
+	}
+	got, epoch, err := ParseMigrating(migrated, oldEpoch, newEpoch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !epoch.Equal(newEpoch) || !got.Time().Equal(want.Time()) ||
+		got.DatacenterId() != want.DatacenterId() || got.WorkerId() != want.WorkerId() || got.Sequence() != want.Sequence() {
+		t.Fatalf("Migrate(%d) parsed as %+v, want %+v", legacy, got, want)
+	}
+
+	// 已经是新格式的id原样返回
+	if again, err := m.Migrate(migrated); err != nil || again != migrated {
+		t.Fatalf("Migrate(%d) = %d, %v", migrated, again, err)
+	}
+
+	// 早于新起始时间的旧id无法转换
+	before := (newEpoch.UnixMilli()-twepoch-1)<<timestampLeftShift | 2<<datacenterIdShift | 1<<workerIdShift
+	if _, err := m.Migrate(before); !errors.Is(err, ErrLayoutMismatch) {
+		t.Fatalf("Migrate(%d) error = %v, want ErrLayoutMismatch", before, err)
+	}
+	if _, err := m.Migrate(-1); err == nil {
+		t.Fatal("Migrate(-1) expected error")
+	}
+}
@@ -0,0 +1,29 @@
+// Package msgpack 为雪花id提供 vmihailenco/msgpack 编解码，id编码为msgpack的uint64类型
+package msgpack
+
+import (
+	vmsgpack "github.com/vmihailenco/msgpack/v5"
+
+	"github.com/pangush/snowflake"
+)
+
+// ID 实现 msgpack.CustomEncoder 和 msgpack.CustomDecoder 的雪花id
+type ID snowflake.ID
+
+var (
+	_ vmsgpack.CustomEncoder = ID(0)
+	_ vmsgpack.CustomDecoder = (*ID)(nil)
+)
+
+func (id ID) EncodeMsgpack(enc *vmsgpack.Encoder) error {
+	return enc.EncodeUint64(uint64(id))
+}
+
+func (id *ID) DecodeMsgpack(dec *vmsgpack.Decoder) error {
+	n, err := dec.DecodeUint64()
+	if err != nil {
+		return err
+	}
+	*id = ID(n)
+	return nil
+}
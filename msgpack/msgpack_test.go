@@ -0,0 +1,51 @@
+package msgpack
+
+import (
+	"testing"
+
+	vmsgpack "github.com/vmihailenco/msgpack/v5"
+)
+
+func TestID_RoundTrip(t *testing.T) {
+	id := ID(1234567890123456789)
+	b, err := vmsgpack.Marshal(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != 9 || b[0] != 0xcf {
+		t.Errorf("encoding = %x, want uint64 (0xcf) format", b)
+	}
+	var got ID
+	if err := vmsgpack.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != id {
+		t.Errorf("round trip = %d, want %d", got, id)
+	}
+
+	// 作为结构体字段
+	type order struct {
+		ID   ID     `msgpack:"id"`
+		Name string `msgpack:"name"`
+	}
+	b, err = vmsgpack.Marshal(order{ID: id, Name: "book"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var o order
+	if err := vmsgpack.Unmarshal(b, &o); err != nil {
+		t.Fatal(err)
+	}
+	if o.ID != id || o.Name != "book" {
+		t.Errorf("round trip = %+v", o)
+	}
+
+	// 其它编码器写入的较短整数也能解码
+	b, err = vmsgpack.Marshal(uint8(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vmsgpack.Unmarshal(b, &got); err != nil || got != 7 {
+		t.Errorf("decode small int = %d, %v", got, err)
+	}
+}
@@ -0,0 +1,163 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"os"
+)
+
+// NewFromIPv4 根据IPv4地址分配节点：第三段的低5位作为数据id，第四段的低5位作为机器id。
+// 同一个/27或更小网段内的机器会得到唯一且固定的节点。
+func NewFromIPv4(ip net.IP, opts ...Option) (*Snowflake, error) {
+	ip4 := ip.To4()
+	if ip4 == nil {
+		return nil, fmt.Errorf("%v is not an IPv4 address", ip)
+	}
+	return New(int64(ip4[3]&maxWorkerId), int64(ip4[2]&maxDatacenterId), opts...)
+}
+
+// FNV1aHasher 64位FNV-1a哈希，是 WithIDHasher 的默认值
+func FNV1aHasher(input []byte) uint64 {
+	h := fnv.New64a()
+	h.Write(input)
+	return h.Sum64()
+}
+
+// WithIDHasher 自定义由主机名、MAC地址等信息计算节点时使用的哈希函数，
+// 例如使用与一致性哈希环相同的xxhash、murmur3，使节点分配与环的拓扑保持一致。
+// 哈希值的低5位作为机器id，接下来的5位作为数据id。
+func WithIDHasher(fn func(input []byte) uint64) Option {
+	return func(s *Snowflake) error {
+		if fn == nil {
+			return fmt.Errorf("id hasher can't be nil")
+		}
+		s.hasher = fn
+		return nil
+	}
+}
+
+// NewFromHostname 根据主机名的哈希值分配节点。不同主机名可能得到相同的节点，
+// 节点数较多时应改用显式分配或 NewFromIPv4。
+func NewFromHostname(opts ...Option) (*Snowflake, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+	return NewFromBytes([]byte(hostname), opts...)
+}
+
+// NewFromMAC 根据MAC地址的哈希值分配节点，哈希函数可以用 WithIDHasher 指定
+func NewFromMAC(mac net.HardwareAddr, opts ...Option) (*Snowflake, error) {
+	if len(mac) == 0 {
+		return nil, fmt.Errorf("empty MAC address")
+	}
+	return NewFromBytes(mac, opts...)
+}
+
+// NewFromBytes 根据input（如Kubernetes的pod名称）的哈希值分配节点，哈希函数可以用 WithIDHasher 指定
+func NewFromBytes(input []byte, opts ...Option) (*Snowflake, error) {
+	s, err := applyOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	h := s.hasher(input)
+	s.workerId = int64(h) & bitMask(s.layout.WorkerBits)
+	s.datacenterId = int64(h>>s.layout.WorkerBits) & bitMask(s.layout.DatacenterBits)
+	return s.init(true)
+}
+
+var ErrNoCIDRMatch = errors.New("no local ip matches the cidr map")
+
+// localIPs 返回本机的IP地址，测试时可以替换
+var localIPs = func() ([]net.IP, error) {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil, err
+	}
+	var ips []net.IP
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLoopback() {
+			ips = append(ips, ipNet.IP)
+		}
+	}
+	return ips, nil
+}
+
+// NewFromCIDRMap 根据本机IP所在的网段确定数据id，cidrMap为网段到数据id的映射，
+// 例如 {"10.1.0.0/16": 1, "10.2.0.0/16": 2}。本机有多个IP或网段互相包含时，取前缀最长的匹配。
+// 没有匹配的网段时返回 ErrNoCIDRMatch。
+func NewFromCIDRMap(cidrMap map[string]int64, workerID int64, opts ...Option) (*Snowflake, error) {
+	type entry struct {
+		ipNet        *net.IPNet
+		datacenterId int64
+	}
+	entries := make([]entry, 0, len(cidrMap))
+	for cidr, datacenterId := range cidrMap {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry{ipNet, datacenterId})
+	}
+
+	ips, err := localIPs()
+	if err != nil {
+		return nil, err
+	}
+	best, bestOnes := int64(-1), -1
+	for _, ip := range ips {
+		for _, e := range entries {
+			if ones, _ := e.ipNet.Mask.Size(); e.ipNet.Contains(ip) && ones > bestOnes {
+				best, bestOnes = e.datacenterId, ones
+			}
+		}
+	}
+	if bestOnes < 0 {
+		return nil, ErrNoCIDRMatch
+	}
+	return New(workerID, best, opts...)
+}
+
+var ErrNoNodeAddress = errors.New("no private ipv4 or mac address to derive the node from")
+
+// localMACs 返回本机网卡的MAC地址，测试时可以替换
+var localMACs = func() ([]net.HardwareAddr, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+	var macs []net.HardwareAddr
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback == 0 && len(iface.HardwareAddr) != 0 {
+			macs = append(macs, iface.HardwareAddr)
+		}
+	}
+	return macs, nil
+}
+
+// NewAuto 根据本机的网络地址自动分配节点，与Sonyflake类似，适用于容器等难以手工分配节点的部署：
+// 有私有IPv4地址时按 NewFromIPv4 使用第一个私有地址的低位，否则按 NewFromMAC 使用第一块网卡的MAC地址，
+// 都没有时返回 ErrNoNodeAddress。
+// 按IP分配时只有同一个/27网段内的机器保证不冲突，跨网段的机器、以及按MAC的哈希分配时都可能得到相同的节点，
+// 节点较多的集群应使用 redis、consul 等子包协调分配。
+func NewAuto(opts ...Option) (*Snowflake, error) {
+	ips, err := localIPs()
+	if err != nil {
+		return nil, err
+	}
+	for _, ip := range ips {
+		if ip.To4() != nil && ip.IsPrivate() {
+			return NewFromIPv4(ip, opts...)
+		}
+	}
+	macs, err := localMACs()
+	if err != nil {
+		return nil, err
+	}
+	if len(macs) == 0 {
+		return nil, ErrNoNodeAddress
+	}
+	return NewFromMAC(macs[0], opts...)
+}
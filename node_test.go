@@ -0,0 +1,171 @@
+package snowflake
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestNewFromIPv4(t *testing.T) {
+	cases := []struct {
+		ip                 string
+		worker, datacenter int64
+	}{
+		{"10.0.0.1", 1, 0},
+		{"192.168.1.31", 31, 1},
+		{"192.168.1.32", 0, 1},
+		{"172.16.255.255", 31, 31},
+		{"10.1.66.130", 2, 2},
+		{"::ffff:10.0.3.7", 7, 3},
+	}
+	for _, c := range cases {
+		sf, err := NewFromIPv4(net.ParseIP(c.ip))
+		if err != nil {
+			t.Fatalf("NewFromIPv4(%s): %v", c.ip, err)
+		}
+		defer sf.Close()
+		if sf.workerId != c.worker || sf.datacenterId != c.datacenter {
+			t.Errorf("NewFromIPv4(%s) = worker %d datacenter %d, want %d %d",
+				c.ip, sf.workerId, sf.datacenterId, c.worker, c.datacenter)
+		}
+	}
+
+	for _, ip := range []net.IP{nil, net.ParseIP("2001:db8::1")} {
+		if _, err := NewFromIPv4(ip); err == nil {
+			t.Errorf("NewFromIPv4(%v) expected error", ip)
+		}
+	}
+}
+
+func TestWithIDHasher(t *testing.T) {
+	hasher := func(input []byte) uint64 {
+		return 0xabc<<10 | 17<<5 | 9
+	}
+	sf, err := NewFromHostname(WithIDHasher(hasher))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+	if sf.workerId != 9 || sf.datacenterId != 17 {
+		t.Errorf("worker %d datacenter %d, want 9 17", sf.workerId, sf.datacenterId)
+	}
+
+	// 默认使用FNV-1a，结果固定
+	h := FNV1aHasher([]byte("pod-0"))
+	sf, err = NewFromBytes([]byte("pod-0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+	if sf.workerId != int64(h&31) || sf.datacenterId != int64(h>>5&31) {
+		t.Errorf("worker %d datacenter %d, want %d %d", sf.workerId, sf.datacenterId, h&31, h>>5&31)
+	}
+	if FNV1aHasher([]byte("a")) != 0xaf63dc4c8601ec8c {
+		t.Errorf("FNV1aHasher(\"a\") = %#x", FNV1aHasher([]byte("a")))
+	}
+
+	if _, err := NewFromHostname(WithIDHasher(nil)); err == nil {
+		t.Error("WithIDHasher(nil) expected error")
+	}
+
+	mac, _ := net.ParseMAC("02:42:ac:11:00:02")
+	sf, err = NewFromMAC(mac, WithIDHasher(func(input []byte) uint64 {
+		if string(input) != string(mac) {
+			t.Errorf("hasher input = %x, want %x", input, []byte(mac))
+		}
+		return 3<<5 | 4
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+	if sf.workerId != 4 || sf.datacenterId != 3 {
+		t.Errorf("NewFromMAC worker %d datacenter %d, want 4 3", sf.workerId, sf.datacenterId)
+	}
+	if _, err := NewFromMAC(nil); err == nil {
+		t.Error("NewFromMAC(nil) expected error")
+	}
+}
+
+func TestNewFromCIDRMap(t *testing.T) {
+	defer func(f func() ([]net.IP, error)) { localIPs = f }(localIPs)
+	setIPs := func(ips ...string) {
+		localIPs = func() ([]net.IP, error) {
+			var res []net.IP
+			for _, ip := range ips {
+				res = append(res, net.ParseIP(ip))
+			}
+			return res, nil
+		}
+	}
+	cidrMap := map[string]int64{
+		"10.1.0.0/16": 1,
+		"10.2.0.0/16": 2,
+		"10.2.8.0/24": 3,
+	}
+
+	cases := []struct {
+		ips        []string
+		datacenter int64
+	}{
+		{[]string{"10.1.3.4"}, 1},
+		{[]string{"192.168.0.2", "10.2.0.9"}, 2},
+		{[]string{"10.2.8.20"}, 3}, // 前缀更长的网段优先
+	}
+	for _, c := range cases {
+		setIPs(c.ips...)
+		sf, err := NewFromCIDRMap(cidrMap, 7)
+		if err != nil {
+			t.Fatalf("ips %v: %v", c.ips, err)
+		}
+		defer sf.Close()
+		if sf.datacenterId != c.datacenter || sf.workerId != 7 {
+			t.Errorf("ips %v: datacenter %d worker %d, want %d 7", c.ips, sf.datacenterId, sf.workerId, c.datacenter)
+		}
+	}
+
+	setIPs("172.16.0.1")
+	if _, err := NewFromCIDRMap(cidrMap, 7); !errors.Is(err, ErrNoCIDRMatch) {
+		t.Errorf("err = %v, want ErrNoCIDRMatch", err)
+	}
+	if _, err := NewFromCIDRMap(map[string]int64{"10.0.0.0/33": 1}, 7); err == nil {
+		t.Error("invalid cidr expected error")
+	}
+}
+
+func TestNewAuto(t *testing.T) {
+	defer func(f func() ([]net.IP, error)) { localIPs = f }(localIPs)
+	defer func(f func() ([]net.HardwareAddr, error)) { localMACs = f }(localMACs)
+	mac := net.HardwareAddr{0x02, 0x42, 0xac, 0x11, 0x00, 0x02}
+	localMACs = func() ([]net.HardwareAddr, error) { return []net.HardwareAddr{mac}, nil }
+
+	// 优先使用私有IPv4地址
+	localIPs = func() ([]net.IP, error) {
+		return []net.IP{net.ParseIP("8.8.8.8"), net.ParseIP("fd00::1"), net.ParseIP("10.0.3.38")}, nil
+	}
+	sf, err := NewAuto()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sf.datacenterId != 3 || sf.workerId != 6 {
+		t.Errorf("datacenter %d worker %d, want 3 6", sf.datacenterId, sf.workerId)
+	}
+	sf.Close()
+
+	// 没有私有IPv4地址时使用MAC地址
+	localIPs = func() ([]net.IP, error) { return []net.IP{net.ParseIP("8.8.8.8")}, nil }
+	sf, err = NewAuto()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := FNV1aHasher(mac)
+	if sf.workerId != int64(h&maxWorkerId) || sf.datacenterId != int64(h>>workerIdBits&maxDatacenterId) {
+		t.Errorf("datacenter %d worker %d don't match the mac hash", sf.datacenterId, sf.workerId)
+	}
+	sf.Close()
+
+	localMACs = func() ([]net.HardwareAddr, error) { return nil, nil }
+	if _, err := NewAuto(); !errors.Is(err, ErrNoNodeAddress) {
+		t.Errorf("err = %v, want ErrNoNodeAddress", err)
+	}
+}
@@ -0,0 +1,20 @@
+package snowflake
+
+import "encoding/binary"
+
+// ToObjectID 将id转换为12字节的MongoDB ObjectID，可以直接作为 primitive.ObjectID 使用。
+// 按ObjectID规范，前4字节为大端序的unix时间戳(秒)，取自id的生成时间，
+// ObjectID.Timestamp() 返回的就是id的生成时间（精确到秒）；后8字节为大端序的id。
+// 机器id和数据id已经包含在id中，不再单独存放。
+// 字节序与id的大小顺序一致，按ObjectID排序仍是按生成时间排序。
+func ToObjectID(id int64) [12]byte {
+	var oid [12]byte
+	binary.BigEndian.PutUint32(oid[0:4], uint32(ID(id).Parse().Timestamp()/1000))
+	binary.BigEndian.PutUint64(oid[4:12], uint64(id))
+	return oid
+}
+
+// FromObjectID 从 ToObjectID 生成的ObjectID中取出id，忽略前4字节的时间戳
+func FromObjectID(oid [12]byte) int64 {
+	return int64(binary.BigEndian.Uint64(oid[4:12]))
+}
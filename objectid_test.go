@@ -0,0 +1,42 @@
+package snowflake
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+func TestObjectID(t *testing.T) {
+	sf, err := NewUnregistered(5, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		id, err := sf.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		oid := ToObjectID(id)
+		if got := FromObjectID(oid); got != id {
+			t.Fatalf("FromObjectID(ToObjectID(%d)) = %d", id, got)
+		}
+
+		// 按ObjectID规范，前4字节是unix时间戳(秒)
+		oidTime := time.Unix(int64(binary.BigEndian.Uint32(oid[0:4])), 0)
+		if d := ID(id).Time().Sub(oidTime); d < 0 || d >= time.Second {
+			t.Errorf("ObjectID time %v doesn't match id time %v", oidTime, ID(id).Time())
+		}
+		if d := time.Since(oidTime); d < 0 || d > time.Minute {
+			t.Errorf("ObjectID time %v is not close to now", oidTime)
+		}
+	}
+
+	// 保持顺序，包括跨秒的id
+	ids := []int64{1 << 40, 1<<40 + 1, 1<<40 + 1000<<timestampLeftShift, 1 << 50}
+	for i := 1; i < len(ids); i++ {
+		a, b := ToObjectID(ids[i-1]), ToObjectID(ids[i])
+		if string(a[:]) >= string(b[:]) {
+			t.Errorf("ObjectID order doesn't follow id order for %d, %d", ids[i-1], ids[i])
+		}
+	}
+}
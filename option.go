@@ -0,0 +1,71 @@
+package snowflake
+
+import (
+	"fmt"
+	"time"
+)
+
+// Option 创建生成器时的可选配置
+type Option func(*Snowflake) error
+
+// WithVersionBits 从毫秒内序列的高位划出bits位存放版本号version，用于区分不同id方案生成的id。
+// 例如bits为4时，毫秒内序列只剩8位，每毫秒最多生成256个id。
+// 解析时使用 ParseWithVersion 并传入相同的bits。
+func WithVersionBits(bits uint8, version uint8) Option {
+	return func(s *Snowflake) error {
+		if bits == 0 {
+			return fmt.Errorf("version bits must be positive")
+		}
+		if int64(version) >= 1<<bits {
+			return fmt.Errorf("version %d can't be represented in %d bits", version, bits)
+		}
+		if s.datacenterVersionBits != 0 {
+			return fmt.Errorf("version bits can't be taken from both sequence and datacenter id")
+		}
+		s.versionBits = bits
+		s.versionValue = int64(version)
+		return nil
+	}
+}
+
+// WithDatacenterVersionBits 与 WithVersionBits 相同，但从数据id的高位划出bits位存放版本号，
+// 毫秒内序列保持12位，数据id的最大值相应减小，例如bits为2时数据id只能为0-7。
+// 解析时使用 ParseWithDatacenterVersion 并传入相同的bits。
+func WithDatacenterVersionBits(bits uint8, version uint8) Option {
+	return func(s *Snowflake) error {
+		if bits == 0 {
+			return fmt.Errorf("datacenter version bits must be positive")
+		}
+		if int64(version) >= 1<<bits {
+			return fmt.Errorf("version %d can't be represented in %d bits", version, bits)
+		}
+		if s.versionBits != 0 {
+			return fmt.Errorf("version bits can't be taken from both sequence and datacenter id")
+		}
+		s.datacenterVersionBits = bits
+		s.versionValue = int64(version)
+		return nil
+	}
+}
+
+// WithMaxSequence 限制每毫秒最多生成max+1个id，序列到达max后等待下一毫秒。
+// 用于多租户场景下限制单个生成器的吞吐，max不能超过毫秒内序列的最大值。
+func WithMaxSequence(max int64) Option {
+	return func(s *Snowflake) error {
+		if max < 0 {
+			return fmt.Errorf("max sequence can't be less than 0")
+		}
+		s.maxSequence = max
+		return nil
+	}
+}
+
+// WithEpoch 使用自定义的起始时间，id中的时间戳为距离起始时间的毫秒数。
+// 起始时间不能晚于当前时间（使用 WithClock 时为该时钟的当前时间），解析时需要使用相同的起始时间。
+func WithEpoch(t time.Time) Option {
+	return func(s *Snowflake) error {
+		s.epoch = t.UnixMilli()
+		s.epochSet = true
+		return nil
+	}
+}
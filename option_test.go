@@ -0,0 +1,170 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithVersionBits(t *testing.T) {
+	sf, err := New(1, 2, WithVersionBits(4, 9))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+	for i := 0; i < 1000; i++ {
+		id, err := sf.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := ParseWithVersion(id, 4)
+		if p.Version() != 9 {
+			t.Fatalf("Version() = %d, want 9", p.Version())
+		}
+		if p.Sequence() > 0xff {
+			t.Fatalf("Sequence() = %d, want at most 8 bits", p.Sequence())
+		}
+		if p.WorkerId() != 1 || p.DatacenterId() != 2 {
+			t.Fatalf("worker %d datacenter %d, want 1 2", p.WorkerId(), p.DatacenterId())
+		}
+	}
+
+	old, err := New(1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer old.Close()
+	id, err := old.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := ParseWithVersion(id, 4).Version(); v != 0 {
+		t.Errorf("old id Version() = %d, want 0", v)
+	}
+}
+
+func TestWithVersionBits_Invalid(t *testing.T) {
+	cases := []struct{ bits, version uint8 }{
+		{0, 0},
+		{12, 1},
+		{4, 16},
+	}
+	for _, c := range cases {
+		if _, err := New(0, 0, WithVersionBits(c.bits, c.version)); err == nil {
+			t.Errorf("WithVersionBits(%d, %d) expected error", c.bits, c.version)
+		}
+	}
+}
+
+func TestWithDatacenterVersionBits(t *testing.T) {
+	sf, err := NewUnregistered(1, 5, WithDatacenterVersionBits(2, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5000; i++ {
+		id, err := sf.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := ParseWithDatacenterVersion(id, 2)
+		if p.Version() != 3 || p.DatacenterId() != 5 || p.WorkerId() != 1 {
+			t.Fatalf("id %d parsed as version %d datacenter %d worker %d", id, p.Version(), p.DatacenterId(), p.WorkerId())
+		}
+		// 毫秒内序列仍为12位
+		if p.Sequence() != ID(id).Parse().Sequence() {
+			t.Fatalf("id %d sequence %d", id, p.Sequence())
+		}
+	}
+	if err := sf.Probe(); err != nil {
+		t.Error(err)
+	}
+	if c := sf.Config(); c.DatacenterIdBits != 3 || c.SequenceBits != 12 || c.VersionBits != 2 {
+		t.Errorf("Config() = %+v", c)
+	}
+
+	if _, err := NewUnregistered(8, 8, WithDatacenterVersionBits(2, 0)); err == nil {
+		t.Error("datacenter id 8 with 2 version bits expected error")
+	}
+	for _, c := range []struct{ bits, version uint8 }{{0, 0}, {5, 1}, {2, 4}} {
+		if _, err := NewUnregistered(0, 0, WithDatacenterVersionBits(c.bits, c.version)); err == nil {
+			t.Errorf("WithDatacenterVersionBits(%d, %d) expected error", c.bits, c.version)
+		}
+	}
+	if _, err := NewUnregistered(0, 0, WithVersionBits(1, 1), WithDatacenterVersionBits(1, 1)); err == nil {
+		t.Error("both version options expected error")
+	}
+}
+
+func TestWithMaxSequence(t *testing.T) {
+	sf, err := NewUnregistered(0, 0, WithMaxSequence(9))
+	if err != nil {
+		t.Fatal(err)
+	}
+	perMs := make(map[int64]int)
+	seen := make(map[int64]bool)
+	for i := 0; i < 200; i++ {
+		id, err := sf.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen[id] {
+			t.Fatalf("duplicate id %d", id)
+		}
+		seen[id] = true
+		p := ID(id).Parse()
+		if p.Sequence() > 9 {
+			t.Fatalf("sequence %d exceeds max 9", p.Sequence())
+		}
+		perMs[p.Timestamp()]++
+	}
+	for ts, n := range perMs {
+		if n > 10 {
+			t.Errorf("%d ids generated at %d, want at most 10", n, ts)
+		}
+	}
+	if f := sf.Forecast(time.Millisecond); f.PerMillisecond != 10 {
+		t.Errorf("PerMillisecond = %d, want 10", f.PerMillisecond)
+	}
+
+	if _, err := NewUnregistered(0, 0, WithMaxSequence(-1)); err == nil {
+		t.Error("WithMaxSequence(-1) expected error")
+	}
+	if _, err := NewUnregistered(0, 0, WithMaxSequence(4096)); err == nil {
+		t.Error("WithMaxSequence(4096) expected error")
+	}
+	// 与版本号一起使用时不能超过剩余的序列位
+	if _, err := NewUnregistered(0, 0, WithMaxSequence(300), WithVersionBits(4, 1)); err == nil {
+		t.Error("WithMaxSequence(300) with 8 sequence bits expected error")
+	}
+}
+
+func TestWithEpoch(t *testing.T) {
+	epoch := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	sf, err := NewUnregistered(0, 0, WithEpoch(epoch))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := sf.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(ParseWithEpoch(id, epoch).Time()); d < 0 || d > time.Second {
+		t.Errorf("id parsed with custom epoch is %v old", d)
+	}
+	if got := sf.Config().EpochMs; got != epoch.UnixMilli() {
+		t.Errorf("Config().EpochMs = %d, want %d", got, epoch.UnixMilli())
+	}
+
+	if _, err := NewUnregistered(0, 0, WithEpoch(time.Now().Add(time.Hour))); err == nil {
+		t.Error("WithEpoch(future) expected error")
+	}
+
+	// 按 WithClock 的时钟检查，与选项的顺序无关
+	clock := newFakeClock(time.Now().Add(24 * time.Hour))
+	if _, err := NewUnregistered(0, 0, WithEpoch(time.Now().Add(time.Hour)), WithClock(clock)); err != nil {
+		t.Errorf("WithEpoch before the fake clock: %v", err)
+	}
+	past := newFakeClock(time.UnixMilli(twepoch + 1000))
+	if _, err := NewUnregistered(0, 0, WithClock(past), WithEpoch(time.UnixMilli(twepoch+2000))); err == nil {
+		t.Error("WithEpoch after the fake clock expected error")
+	}
+}
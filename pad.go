@@ -0,0 +1,25 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrWidthTooSmall = errors.New("width is smaller than the snowflake id")
+
+// PadLeft 在id的十进制表示左边填充char到正好width个字符，用于定宽文件、Excel等需要固定宽度的场合。
+// int64最多19位数字，width大于19时同样有效。用'0'填充负数时负号保留在最前面。
+// id的十进制表示已经超过width时不会截断，而是以 ErrWidthTooSmall panic。
+func (id ID) PadLeft(width int, char rune) string {
+	s := strconv.FormatInt(int64(id), 10)
+	if len(s) > width {
+		panic(fmt.Errorf("%w: %s needs %d characters, width %d", ErrWidthTooSmall, s, len(s), width))
+	}
+	pad := strings.Repeat(string(char), width-len(s))
+	if char == '0' && id < 0 {
+		return "-" + pad + s[1:]
+	}
+	return pad + s
+}
@@ -0,0 +1,31 @@
+package snowflake
+
+import "time"
+
+// Peek 计算 NextId 下一次将会生成的id，不改变生成器的状态，用于预检。
+// 返回的id不会被保留，实际生成的id可能因为时间前进、其它调用或随机序列（WithRandomSequenceStart 等）而更大。
+// 时钟回退或生成器已停止时返回与 NextId 相同的错误，但不计入熔断和时钟回退事件。
+func (s *Snowflake) Peek() (int64, error) {
+	if s.shutdown.Load() {
+		return 0, ErrShutdown
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	timestamp := s.advanced(s.timeGen())
+	if timestamp < s.lastTimestamp && s.toleratesRollback(s.lastTimestamp-timestamp) {
+		timestamp = s.lastTimestamp // 等待或沿用逻辑时钟之后，最早在上一次的毫秒生成
+	}
+	if timestamp < s.lastTimestamp {
+		return 0, &ErrClockMovedBackwards{Duration: time.Duration(s.lastTimestamp-timestamp) * time.Millisecond}
+	}
+	sequence := s.nextSequence(-1)
+	if timestamp == s.lastTimestamp {
+		sequence = s.nextSequence(s.sequence)
+		if sequence > s.maxSequence { // 序列用尽，最早在下一毫秒生成
+			timestamp += s.layout.unit()
+			sequence = s.nextSequence(-1)
+		}
+	}
+	return s.compose(timestamp, sequence), nil
+}
@@ -0,0 +1,50 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeek(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, err := NewUnregistered(2, 3, WithClock(clock), WithMaxSequence(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 6; i++ {
+		peeked, err := sf.Peek()
+		if err != nil {
+			t.Fatal(err)
+		}
+		again, _ := sf.Peek()
+		if again != peeked {
+			t.Fatalf("Peek changed state: %d then %d", peeked, again)
+		}
+		if i == 4 {
+			clock.Add(time.Millisecond) // 第4个id后序列用尽
+		}
+		id, err := sf.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id < peeked {
+			t.Fatalf("NextId = %d, less than peeked %d", id, peeked)
+		}
+		if i != 4 && id != peeked {
+			t.Errorf("NextId = %d, want peeked %d", id, peeked)
+		}
+	}
+
+	// 时间前进后反映新的时间戳
+	clock.Add(5 * time.Millisecond)
+	peeked, _ := sf.Peek()
+	if p := ID(peeked).Parse(); p.Timestamp() != twepoch+1006 || p.Sequence() != 0 {
+		t.Errorf("peeked %+v after clock moved forward", p)
+	}
+
+	clock.Add(-10 * time.Millisecond)
+	if _, err := sf.Peek(); err == nil {
+		t.Error("Peek after clock moved backwards expected error")
+	}
+}
@@ -0,0 +1,55 @@
+// Package pgx 为 jackc/pgx 驱动提供雪花id的 PostgreSQL 编解码，对应 int8(bigint) 类型
+package pgx
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgtype"
+	"github.com/pangush/snowflake"
+)
+
+var errNull = errors.New("snowflake: cannot decode NULL into PgxID")
+
+// PgxID 实现 pgtype 的编解码接口，二进制格式与 PostgreSQL int8 的8字节大端序一致
+type PgxID snowflake.ID
+
+var (
+	_ pgtype.TextEncoder   = PgxID(0)
+	_ pgtype.BinaryEncoder = PgxID(0)
+	_ pgtype.TextDecoder   = (*PgxID)(nil)
+	_ pgtype.BinaryDecoder = (*PgxID)(nil)
+)
+
+func (id PgxID) EncodeText(ci *pgtype.ConnInfo, buf []byte) ([]byte, error) {
+	return strconv.AppendInt(buf, int64(id), 10), nil
+}
+
+func (id PgxID) EncodeBinary(ci *pgtype.ConnInfo, buf []byte) ([]byte, error) {
+	return binary.BigEndian.AppendUint64(buf, uint64(id)), nil
+}
+
+func (id *PgxID) DecodeText(ci *pgtype.ConnInfo, src []byte) error {
+	if src == nil {
+		return errNull
+	}
+	n, err := strconv.ParseInt(string(src), 10, 64)
+	if err != nil {
+		return fmt.Errorf("snowflake: invalid int8 text %q: %w", src, err)
+	}
+	*id = PgxID(n)
+	return nil
+}
+
+func (id *PgxID) DecodeBinary(ci *pgtype.ConnInfo, src []byte) error {
+	if src == nil {
+		return errNull
+	}
+	if len(src) != 8 {
+		return fmt.Errorf("snowflake: invalid length for int8: %d", len(src))
+	}
+	*id = PgxID(binary.BigEndian.Uint64(src))
+	return nil
+}
@@ -0,0 +1,85 @@
+package pgx
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jackc/pgtype"
+)
+
+func TestPgxID_Text(t *testing.T) {
+	ci := pgtype.NewConnInfo()
+	id := PgxID(1234567890123456789)
+
+	buf, err := id.EncodeText(ci, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var g pgtype.GenericText
+	if err := g.DecodeText(ci, buf); err != nil {
+		t.Fatal(err)
+	}
+	if g.String != "1234567890123456789" {
+		t.Errorf("text encoding = %q", g.String)
+	}
+
+	src, err := (pgtype.GenericText{String: "42", Status: pgtype.Present}).EncodeText(ci, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got PgxID
+	if err := got.DecodeText(ci, src); err != nil {
+		t.Fatal(err)
+	}
+	if got != 42 {
+		t.Errorf("DecodeText = %d, want 42", got)
+	}
+
+	if err := got.DecodeText(ci, nil); err == nil {
+		t.Error("DecodeText(NULL) expected error")
+	}
+	if err := got.DecodeText(ci, []byte("abc")); err == nil {
+		t.Error("DecodeText(abc) expected error")
+	}
+}
+
+func TestPgxID_Binary(t *testing.T) {
+	ci := pgtype.NewConnInfo()
+	id := PgxID(1234567890123456789)
+
+	buf, err := id.EncodeBinary(ci, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var g pgtype.GenericBinary
+	if err := g.DecodeBinary(ci, buf); err != nil {
+		t.Fatal(err)
+	}
+	// 与PostgreSQL int8的线上格式一致
+	want, err := (&pgtype.Int8{Int: int64(id), Status: pgtype.Present}).EncodeBinary(ci, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(g.Bytes, want) {
+		t.Errorf("binary encoding = %x, want %x", g.Bytes, want)
+	}
+
+	src, err := (pgtype.GenericBinary{Bytes: want, Status: pgtype.Present}).EncodeBinary(ci, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got PgxID
+	if err := got.DecodeBinary(ci, src); err != nil {
+		t.Fatal(err)
+	}
+	if got != id {
+		t.Errorf("DecodeBinary = %d, want %d", got, id)
+	}
+
+	if err := got.DecodeBinary(ci, nil); err == nil {
+		t.Error("DecodeBinary(NULL) expected error")
+	}
+	if err := got.DecodeBinary(ci, []byte{1, 2, 3}); err == nil {
+		t.Error("DecodeBinary(3 bytes) expected error")
+	}
+}
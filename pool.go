@@ -0,0 +1,88 @@
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var ErrLeaseReleased = errors.New("lease already released")
+
+// Generator id生成器
+type Generator interface {
+	NextId() (int64, error)
+}
+
+// Lease 从 SnowflakePool 租用的生成器，使用完毕后需要调用 Release 归还机器id
+type Lease interface {
+	Generator
+	Release() error
+}
+
+// SnowflakePool 同一数据中心下所有机器id的生成器池
+type SnowflakePool struct {
+	free chan *Snowflake
+}
+
+// NewSnowflakePool 为datacenterId下的每个机器id创建一个生成器。
+// 归还的生成器会被复用，保留其上一次生成id的时间戳，避免同一毫秒内产生重复id。
+func NewSnowflakePool(datacenterId int64, opts ...Option) (*SnowflakePool, error) {
+	p := &SnowflakePool{free: make(chan *Snowflake, maxWorkerId+1)}
+	for workerId := int64(0); workerId <= maxWorkerId; workerId++ {
+		s, err := New(workerId, datacenterId, opts...)
+		if err != nil {
+			p.Close()
+			return nil, err
+		}
+		p.free <- s
+	}
+	return p, nil
+}
+
+// Acquire 租用一个空闲的机器id，没有空闲时阻塞直到有归还或ctx结束
+func (p *SnowflakePool) Acquire(ctx context.Context) (Lease, error) {
+	select {
+	case s := <-p.free:
+		return &lease{pool: p, s: s}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close 注销池中所有空闲的生成器，应在所有租约归还之后调用
+func (p *SnowflakePool) Close() error {
+	for {
+		select {
+		case s := <-p.free:
+			s.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+type lease struct {
+	mu   sync.Mutex
+	pool *SnowflakePool
+	s    *Snowflake
+}
+
+func (l *lease) NextId() (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.s == nil {
+		return 0, ErrLeaseReleased
+	}
+	return l.s.NextId()
+}
+
+func (l *lease) Release() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.s == nil {
+		return ErrLeaseReleased
+	}
+	l.pool.free <- l.s
+	l.s = nil
+	return nil
+}
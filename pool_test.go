@@ -0,0 +1,65 @@
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSnowflakePool_AcquireRelease(t *testing.T) {
+	p, err := NewSnowflakePool(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	leases := make([]Lease, 0, maxWorkerId+1)
+	seen := make(map[int64]bool)
+	for i := 0; i <= maxWorkerId; i++ {
+		l, err := p.Acquire(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		workerId := l.(*lease).s.workerId
+		if seen[workerId] {
+			t.Fatalf("worker id %d leased twice", workerId)
+		}
+		seen[workerId] = true
+		leases = append(leases, l)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := p.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Acquire on exhausted pool = %v, want deadline exceeded", err)
+	}
+
+	released := leases[5]
+	workerId := released.(*lease).s.workerId
+	if _, err := released.NextId(); err != nil {
+		t.Fatal(err)
+	}
+	if err := released.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := released.NextId(); !errors.Is(err, ErrLeaseReleased) {
+		t.Errorf("NextId after Release = %v, want ErrLeaseReleased", err)
+	}
+	if err := released.Release(); !errors.Is(err, ErrLeaseReleased) {
+		t.Errorf("second Release = %v, want ErrLeaseReleased", err)
+	}
+
+	l, err := p.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := l.(*lease).s.workerId; got != workerId {
+		t.Errorf("reacquired worker id = %d, want %d", got, workerId)
+	}
+
+	leases[5] = l
+	for _, l := range leases {
+		l.Release()
+	}
+}
@@ -0,0 +1,83 @@
+package snowflake
+
+import "fmt"
+
+const (
+	priorityBits         = 2                                 // 优先级所占位数，位于毫秒内序列的高位
+	priorityLevels       = 1 << priorityBits                 // 优先级个数
+	prioritySequenceBits = sequenceBits - priorityBits       // 每个优先级内的序列所占位数
+	prioritySequenceMask = -1 ^ (-1 << prioritySequenceBits) // 每个优先级内的序列最大值
+)
+
+// NextIdWithPriority 生成带优先级的id，priority为0（最高）到3（最低），占毫秒内序列的高2位，
+// 其余10位在每个优先级内自增。同一毫秒内高优先级的id总是小于低优先级的id，
+// 可以直接用整数列排序实现优先队列。
+// 同一毫秒内不会混用 NextId 和 NextIdWithPriority：一方用过的毫秒，另一方会等到下一毫秒。
+// 每毫秒生成的id总数同样受 WithMaxSequence 限制。不能与 WithVersionBits 同时使用。
+func (s *Snowflake) NextIdWithPriority(priority int) (int64, error) {
+	if priority < 0 || priority >= priorityLevels {
+		return 0, fmt.Errorf("priority must be between 0 and %d", priorityLevels-1)
+	}
+	if s.versionBits != 0 {
+		return 0, fmt.Errorf("priority ids can't be used with version bits")
+	}
+	if s.seqStride != 0 {
+		return 0, ErrForked
+	}
+	if s.layout != DefaultLayout {
+		return 0, fmt.Errorf("priority ids can't be used with a custom bit layout")
+	}
+	if s.shutdown.Load() {
+		return 0, ErrShutdown
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.nextIdWith(s.timeGen(), func(timestamp int64) (int64, error) {
+		return s.generatePriority(timestamp, priority)
+	})
+}
+
+func (s *Snowflake) generatePriority(timestamp int64, priority int) (int64, error) {
+	timestamp, err := s.checkRollback(timestamp)
+	if err != nil {
+		return 0, err
+	}
+
+	// 这一毫秒已被 NextId 使用过
+	if timestamp == s.lastTimestamp && timestamp != s.priorityTimestamp {
+		if timestamp, err = s.nextMillis(); err != nil {
+			return 0, err
+		}
+	}
+	if timestamp != s.priorityTimestamp {
+		s.priorityTimestamp = timestamp
+		s.prioritySequence = [priorityLevels]int64{}
+	}
+
+	seq := s.prioritySequence[priority]
+	// 该优先级的序列用尽，或者这一毫秒内生成的id总数已达到 WithMaxSequence 的限制
+	if seq > prioritySequenceMask || s.priorityCount() > s.maxSequence {
+		if timestamp, err = s.nextMillis(); err != nil {
+			return 0, err
+		}
+		s.priorityTimestamp = timestamp
+		s.prioritySequence = [priorityLevels]int64{}
+		seq = 0
+	}
+	s.prioritySequence[priority] = seq + 1
+
+	s.lastTimestamp = timestamp
+	// 让同一毫秒内的 NextId 等到下一毫秒
+	s.sequence = s.maxSequence
+	return s.compose(timestamp, int64(priority)<<prioritySequenceBits|seq), nil
+}
+
+// priorityCount 当前毫秒内按优先级生成的id总数，调用方需持有锁
+func (s *Snowflake) priorityCount() int64 {
+	n := int64(0)
+	for _, seq := range s.prioritySequence {
+		n += seq
+	}
+	return n
+}
@@ -0,0 +1,118 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextIdWithPriority(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, err := NewUnregistered(1, 1, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 同一毫秒内先生成低优先级，再生成高优先级
+	low, err := sf.NextIdWithPriority(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	high, err := sf.NextIdWithPriority(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	high2, err := sf.NextIdWithPriority(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !(high < high2 && high2 < low) {
+		t.Errorf("want high < high2 < low, got %d %d %d", high, high2, low)
+	}
+	if p := ID(low).Parse(); p.Sequence() != 3<<prioritySequenceBits {
+		t.Errorf("low sequence = %d", p.Sequence())
+	}
+	if p := ID(high2).Parse(); p.Sequence() != 1 {
+		t.Errorf("high2 sequence = %d", p.Sequence())
+	}
+
+	// 同一毫秒内的 NextId 等到下一毫秒，不会与优先级id重复
+	done := make(chan int64)
+	go func() {
+		id, _ := sf.NextId()
+		done <- id
+	}()
+	time.Sleep(10 * time.Millisecond)
+	clock.Add(time.Millisecond)
+	plain := <-done
+	if plain <= low {
+		t.Errorf("NextId = %d, want greater than %d", plain, low)
+	}
+	if p := ID(plain).Parse(); p.Timestamp() != twepoch+1001 || p.Sequence() != 0 {
+		t.Errorf("NextId timestamp %d sequence %d", p.Timestamp(), p.Sequence())
+	}
+
+	if _, err := sf.NextIdWithPriority(4); err == nil {
+		t.Error("priority 4 expected error")
+	}
+	if _, err := sf.NextIdWithPriority(-1); err == nil {
+		t.Error("priority -1 expected error")
+	}
+}
+
+func TestNextIdWithPriority_Exhausted(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, err := NewUnregistered(1, 1, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i <= prioritySequenceMask; i++ {
+		if _, err := sf.NextIdWithPriority(2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 其它优先级不受影响
+	if _, err := sf.NextIdWithPriority(1); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan int64)
+	go func() {
+		id, _ := sf.NextIdWithPriority(2)
+		done <- id
+	}()
+	time.Sleep(10 * time.Millisecond)
+	clock.Add(time.Millisecond)
+	p := ID(<-done).Parse()
+	if p.Timestamp() != twepoch+1001 || p.Sequence() != 2<<prioritySequenceBits {
+		t.Errorf("timestamp %d sequence %d", p.Timestamp(), p.Sequence())
+	}
+}
+
+func TestNextIdWithPriority_MaxSequence(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, err := NewUnregistered(1, 1, WithClock(clock), WithMaxSequence(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, priority := range []int{0, 1, 0, 3} {
+		if _, err := sf.NextIdWithPriority(priority); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 这一毫秒已生成4个id，不论哪个优先级都要等到下一毫秒
+	done := make(chan int64)
+	go func() {
+		id, _ := sf.NextIdWithPriority(2)
+		done <- id
+	}()
+	select {
+	case id := <-done:
+		t.Fatalf("NextIdWithPriority = %d, want to wait for the next millisecond", id)
+	case <-time.After(10 * time.Millisecond):
+	}
+	clock.Add(time.Millisecond)
+	if p := ID(<-done).Parse(); p.Timestamp() != twepoch+1001 || p.Sequence() != 2<<prioritySequenceBits {
+		t.Errorf("timestamp %d sequence %d", p.Timestamp(), p.Sequence())
+	}
+}
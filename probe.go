@@ -0,0 +1,44 @@
+package snowflake
+
+import "fmt"
+
+const probeCount = 10
+
+// Probe 在启动时检查生成器是否工作正常：生成10个id并解析，校验时间戳单调递增，
+// 数据id、机器id与创建时一致，毫秒内序列在有效范围内。全部通过返回nil。
+func (s *Snowflake) Probe() error {
+	var last ParsedID
+	for i := 0; i < probeCount; i++ {
+		id, err := s.NextId()
+		if err != nil {
+			return fmt.Errorf("probe: generate id: %w", err)
+		}
+		p := s.parse(id)
+		if i > 0 && (p.Timestamp() < last.Timestamp() || p.ID() <= last.ID()) {
+			return fmt.Errorf("probe: id %d is not after %d", p.ID(), last.ID())
+		}
+		if p.WorkerId() != s.workerId || p.DatacenterId() != s.datacenterId {
+			return fmt.Errorf("probe: id %d has worker id %d datacenter id %d, want %d %d",
+				id, p.WorkerId(), p.DatacenterId(), s.workerId, s.datacenterId)
+		}
+		if p.Sequence() < 0 || p.Sequence() > s.maxSequence {
+			return fmt.Errorf("probe: id %d has sequence %d out of range [0, %d]", id, p.Sequence(), s.maxSequence)
+		}
+		last = p
+	}
+	return nil
+}
+
+// parse 按生成器的配置解析id
+func (s *Snowflake) parse(id int64) ParsedID {
+	p := parseLayout(ID(id), s.epoch, s.layout)
+	switch {
+	case s.versionBits != 0:
+		p.version = p.sequence >> (s.layout.SequenceBits - s.versionBits)
+		p.sequence &= s.sequenceMask
+	case s.datacenterVersionBits != 0:
+		p.version = p.datacenterId >> (s.layout.DatacenterBits - s.datacenterVersionBits)
+		p.datacenterId &= bitMask(s.layout.DatacenterBits) >> s.datacenterVersionBits
+	}
+	return p
+}
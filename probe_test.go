@@ -0,0 +1,35 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSnowflake_Probe(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithVersionBits(4, 3)}} {
+		sf, err := New(5, 6, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		start := time.Now()
+		if err := sf.Probe(); err != nil {
+			t.Fatal(err)
+		}
+		if d := time.Since(start); d > 10*time.Millisecond {
+			t.Errorf("Probe took %v, want under 10ms", d)
+		}
+		sf.Close()
+	}
+}
+
+func TestSnowflake_Probe_ClockSkew(t *testing.T) {
+	sf, err := New(5, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+	sf.lastTimestamp = timeGen() + 60000
+	if err := sf.Probe(); err == nil {
+		t.Error("Probe with clock skew expected error")
+	}
+}
@@ -0,0 +1,58 @@
+// Package prometheus 将雪花算法生成器的 Stats 导出为Prometheus指标
+package prometheus
+
+import (
+	"strconv"
+
+	promlib "github.com/prometheus/client_golang/prometheus"
+
+	"github.com/pangush/snowflake"
+)
+
+// Collector 每次采集时读取生成器的 Stats，指标带有 worker_id、datacenter_id 标签：
+//
+//	snowflake_ids_generated_total       生成的id总数
+//	snowflake_sequence_waits_total      毫秒内序列用尽的次数
+//	snowflake_clock_rollbacks_total     检测到时钟回退的次数
+//	snowflake_sequence_utilization      最近一毫秒内序列的使用率(0-1)，见 snowflake.Snowflake.SequenceUtilization
+type Collector struct {
+	s *snowflake.Snowflake
+
+	generated   *promlib.Desc
+	waits       *promlib.Desc
+	rollbacks   *promlib.Desc
+	utilization *promlib.Desc
+}
+
+var _ promlib.Collector = (*Collector)(nil)
+
+// NewCollector 创建采集s的指标的Collector，使用 promlib.MustRegister 等注册
+func NewCollector(s *snowflake.Snowflake) *Collector {
+	cfg := s.Config()
+	labels := promlib.Labels{
+		"worker_id":     strconv.FormatInt(cfg.WorkerId, 10),
+		"datacenter_id": strconv.FormatInt(cfg.DatacenterId, 10),
+	}
+	return &Collector{
+		s:           s,
+		generated:   promlib.NewDesc("snowflake_ids_generated_total", "Number of snowflake ids generated.", nil, labels),
+		waits:       promlib.NewDesc("snowflake_sequence_waits_total", "Number of times the per-millisecond sequence was exhausted.", nil, labels),
+		rollbacks:   promlib.NewDesc("snowflake_clock_rollbacks_total", "Number of detected clock rollbacks.", nil, labels),
+		utilization: promlib.NewDesc("snowflake_sequence_utilization", "Fraction of the sequence used in the last millisecond.", nil, labels),
+	}
+}
+
+func (c *Collector) Describe(ch chan<- *promlib.Desc) {
+	ch <- c.generated
+	ch <- c.waits
+	ch <- c.rollbacks
+	ch <- c.utilization
+}
+
+func (c *Collector) Collect(ch chan<- promlib.Metric) {
+	st := c.s.Stats()
+	ch <- promlib.MustNewConstMetric(c.generated, promlib.CounterValue, float64(st.Generated))
+	ch <- promlib.MustNewConstMetric(c.waits, promlib.CounterValue, float64(st.SequenceWaits))
+	ch <- promlib.MustNewConstMetric(c.rollbacks, promlib.CounterValue, float64(st.ClockRollbacks))
+	ch <- promlib.MustNewConstMetric(c.utilization, promlib.GaugeValue, c.s.SequenceUtilization())
+}
@@ -0,0 +1,71 @@
+package prometheus
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	promlib "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/pangush/snowflake"
+	"github.com/pangush/snowflake/snowflaketest"
+)
+
+func TestCollector(t *testing.T) {
+	s, err := snowflake.NewUnregistered(3, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		s.NextId()
+	}
+	c := NewCollector(s)
+	reg := promlib.NewPedanticRegistry()
+	if err := reg.Register(c); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `
+# HELP snowflake_ids_generated_total Number of snowflake ids generated.
+# TYPE snowflake_ids_generated_total counter
+snowflake_ids_generated_total{datacenter_id="4",worker_id="3"} 5
+# HELP snowflake_clock_rollbacks_total Number of detected clock rollbacks.
+# TYPE snowflake_clock_rollbacks_total counter
+snowflake_clock_rollbacks_total{datacenter_id="4",worker_id="3"} 0
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want),
+		"snowflake_ids_generated_total", "snowflake_clock_rollbacks_total"); err != nil {
+		t.Error(err)
+	}
+	if n := testutil.CollectAndCount(c); n != 4 {
+		t.Errorf("collected %d metrics, want 4", n)
+	}
+}
+
+func TestCollector_Utilization(t *testing.T) {
+	clock := snowflaketest.NewFakeClock(time.UnixMilli(1577808000000 + 1000))
+	s, err := snowflake.NewUnregistered(3, 4, snowflake.WithClock(clock), snowflake.WithMaxSequence(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		s.NextId()
+	}
+	reg := promlib.NewPedanticRegistry()
+	reg.MustRegister(NewCollector(s))
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range families {
+		if f.GetName() != "snowflake_sequence_utilization" {
+			continue
+		}
+		if got, want := f.GetMetric()[0].GetGauge().GetValue(), s.SequenceUtilization(); got != want {
+			t.Errorf("snowflake_sequence_utilization = %v, want SequenceUtilization %v", got, want)
+		}
+		return
+	}
+	t.Error("snowflake_sequence_utilization not collected")
+}
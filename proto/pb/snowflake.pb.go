@@ -0,0 +1,153 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.33.0
+// 	protoc        v4.25.3
+// source: snowflake.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SnowflakeID struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Value int64  `protobuf:"varint,1,opt,name=value,proto3" json:"value,omitempty"`
+	Epoch string `protobuf:"bytes,2,opt,name=epoch,proto3" json:"epoch,omitempty"`
+}
+
+func (x *SnowflakeID) Reset() {
+	*x = SnowflakeID{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_snowflake_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SnowflakeID) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnowflakeID) ProtoMessage() {}
+
+func (x *SnowflakeID) ProtoReflect() protoreflect.Message {
+	mi := &file_snowflake_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnowflakeID.ProtoReflect.Descriptor instead.
+func (*SnowflakeID) Descriptor() ([]byte, []int) {
+	return file_snowflake_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SnowflakeID) GetValue() int64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *SnowflakeID) GetEpoch() string {
+	if x != nil {
+		return x.Epoch
+	}
+	return ""
+}
+
+var File_snowflake_proto protoreflect.FileDescriptor
+
+var file_snowflake_proto_rawDesc = []byte{
+	0x0a, 0x0f, 0x73, 0x6e, 0x6f, 0x77, 0x66, 0x6c, 0x61, 0x6b, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x12, 0x09, 0x73, 0x6e, 0x6f, 0x77, 0x66, 0x6c, 0x61, 0x6b, 0x65, 0x22, 0x39, 0x0a, 0x0b,
+	0x53, 0x6e, 0x6f, 0x77, 0x66, 0x6c, 0x61, 0x6b, 0x65, 0x49, 0x44, 0x12, 0x14, 0x0a, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x70, 0x6f, 0x63, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x65, 0x70, 0x6f, 0x63, 0x68, 0x42, 0x27, 0x5a, 0x25, 0x67, 0x69, 0x74, 0x68, 0x75,
+	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x70, 0x61, 0x6e, 0x67, 0x75, 0x73, 0x68, 0x2f, 0x73, 0x6e,
+	0x6f, 0x77, 0x66, 0x6c, 0x61, 0x6b, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x70, 0x62,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_snowflake_proto_rawDescOnce sync.Once
+	file_snowflake_proto_rawDescData = file_snowflake_proto_rawDesc
+)
+
+func file_snowflake_proto_rawDescGZIP() []byte {
+	file_snowflake_proto_rawDescOnce.Do(func() {
+		file_snowflake_proto_rawDescData = protoimpl.X.CompressGZIP(file_snowflake_proto_rawDescData)
+	})
+	return file_snowflake_proto_rawDescData
+}
+
+var file_snowflake_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_snowflake_proto_goTypes = []interface{}{
+	(*SnowflakeID)(nil), // 0: snowflake.SnowflakeID
+}
+var file_snowflake_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_snowflake_proto_init() }
+func file_snowflake_proto_init() {
+	if File_snowflake_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_snowflake_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SnowflakeID); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_snowflake_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_snowflake_proto_goTypes,
+		DependencyIndexes: file_snowflake_proto_depIdxs,
+		MessageInfos:      file_snowflake_proto_msgTypes,
+	}.Build()
+	File_snowflake_proto = out.File
+	file_snowflake_proto_rawDesc = nil
+	file_snowflake_proto_goTypes = nil
+	file_snowflake_proto_depIdxs = nil
+}
@@ -0,0 +1,47 @@
+// Package proto 在雪花id和 snowflake.proto 中定义的 SnowflakeID 消息之间转换，用于跨语言传递id
+package proto
+
+//go:generate protoc -I . --go_out=pb --go_opt=paths=source_relative snowflake.proto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pangush/snowflake"
+	"github.com/pangush/snowflake/proto/pb"
+)
+
+// ToProto 将id和生成它的起始时间转换为消息，起始时间以RFC3339格式保存
+func ToProto(id snowflake.ID, epoch time.Time) *pb.SnowflakeID {
+	return &pb.SnowflakeID{
+		Value: int64(id),
+		Epoch: epoch.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+// FromProto 检查消息并取出id。epoch为空时表示默认的起始时间；
+// 需要解析id中的时间时，用 EpochFromProto 取出起始时间后调用 snowflake.ParseWithEpoch。
+func FromProto(msg *pb.SnowflakeID) (snowflake.ID, error) {
+	if msg == nil {
+		return 0, fmt.Errorf("nil snowflake id message")
+	}
+	if msg.Value < 0 {
+		return 0, fmt.Errorf("invalid snowflake id %d: ids can't be negative", msg.Value)
+	}
+	if _, err := EpochFromProto(msg); err != nil {
+		return 0, err
+	}
+	return snowflake.ID(msg.Value), nil
+}
+
+// EpochFromProto 取出消息中的起始时间，epoch为空时返回默认的起始时间
+func EpochFromProto(msg *pb.SnowflakeID) (time.Time, error) {
+	if msg.GetEpoch() == "" {
+		return snowflake.ID(0).Time(), nil // 时间戳为0的id生成于默认的起始时间
+	}
+	epoch, err := time.Parse(time.RFC3339, msg.Epoch)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid snowflake epoch %q: %w", msg.Epoch, err)
+	}
+	return epoch, nil
+}
@@ -0,0 +1,51 @@
+package proto
+
+import (
+	"testing"
+	"time"
+
+	gproto "google.golang.org/protobuf/proto"
+
+	"github.com/pangush/snowflake"
+	"github.com/pangush/snowflake/proto/pb"
+)
+
+func TestRoundTrip(t *testing.T) {
+	epoch := time.Date(2022, 3, 4, 5, 6, 7, 8_000_000, time.FixedZone("CST", 8*3600))
+	id := snowflake.ID(1234567890123456789)
+
+	b, err := gproto.Marshal(ToProto(id, epoch))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg pb.SnowflakeID
+	if err := gproto.Unmarshal(b, &msg); err != nil {
+		t.Fatal(err)
+	}
+	got, err := FromProto(&msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != id {
+		t.Errorf("id = %d, want %d", got, id)
+	}
+	gotEpoch, err := EpochFromProto(&msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gotEpoch.Equal(epoch) {
+		t.Errorf("epoch = %v, want %v", gotEpoch, epoch)
+	}
+	if msg.Epoch != "2022-03-03T21:06:07.008Z" {
+		t.Errorf("epoch field = %q", msg.Epoch)
+	}
+
+	if e, _ := EpochFromProto(&pb.SnowflakeID{Value: 1}); !e.Equal(time.UnixMilli(1577808000000)) {
+		t.Errorf("default epoch = %v", e)
+	}
+	for _, bad := range []*pb.SnowflakeID{nil, {Value: -1}, {Value: 1, Epoch: "yesterday"}} {
+		if _, err := FromProto(bad); err == nil {
+			t.Errorf("FromProto(%v) expected error", bad)
+		}
+	}
+}
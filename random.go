@@ -0,0 +1,44 @@
+package snowflake
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// WithRandomSequenceStart 每一毫秒的序列从[0, maxOffset]中的随机值开始，而不是从0开始，
+// 外部无法根据序列为0的id推算出同一毫秒内的其它id。
+// 每毫秒最少只能生成 WithMaxSequence 的上限减maxOffset再加1个id，maxOffset不能超过该上限。
+func WithRandomSequenceStart(maxOffset int64) Option {
+	return func(s *Snowflake) error {
+		if maxOffset < 0 {
+			return fmt.Errorf("random sequence start can't be negative")
+		}
+		s.randomStart = maxOffset
+		return nil
+	}
+}
+
+// WithRandomSequenceStep 同一毫秒内的序列每次随机增加[1, maxStep]，相邻的id不再连续，难以被枚举。
+// id仍然严格递增，但每毫秒平均只能生成原来的 2/(maxStep+1)。
+// 只影响 NextId 等逐个生成的调用，NextIdN、NextIds 分配的同一毫秒内的id仍然是连续的。
+func WithRandomSequenceStep(maxStep int64) Option {
+	return func(s *Snowflake) error {
+		if maxStep < 1 {
+			return fmt.Errorf("random sequence step must be positive")
+		}
+		s.randomStep = maxStep
+		return nil
+	}
+}
+
+// randomSequence 在 nextSequence 的基础上加入 WithRandomSequenceStart、WithRandomSequenceStep 的随机偏移，
+// sequence为-1表示新的一毫秒
+func (s *Snowflake) randomSequence(sequence int64) int64 {
+	switch {
+	case sequence < 0 && s.randomStart > 0:
+		sequence += rand.Int63n(s.randomStart + 1)
+	case sequence >= 0 && s.randomStep > 1:
+		sequence += rand.Int63n(s.randomStep)
+	}
+	return s.nextSequence(sequence)
+}
@@ -0,0 +1,90 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithRandomSequenceStart(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, err := NewUnregistered(1, 2, WithClock(clock), WithRandomSequenceStart(1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	starts := map[int64]bool{}
+	prev := int64(0)
+	for i := 0; i < 50; i++ {
+		clock.Add(time.Millisecond)
+		id, err := sf.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id <= prev {
+			t.Fatalf("id %d isn't greater than %d", id, prev)
+		}
+		prev = id
+		seq := ID(id).Parse().Sequence()
+		if seq > 1000 {
+			t.Fatalf("sequence %d starts after 1000", seq)
+		}
+		starts[seq] = true
+		// 同一毫秒内继续加1
+		next, _ := sf.NextId()
+		if next != id+1 {
+			t.Fatalf("next id in the same millisecond = %d, want %d", next, id+1)
+		}
+		prev = next
+	}
+	if len(starts) < 10 {
+		t.Errorf("only %d distinct start sequences in 50 milliseconds", len(starts))
+	}
+
+	if _, err := NewUnregistered(1, 2, WithMaxSequence(100), WithRandomSequenceStart(101)); err == nil {
+		t.Error("random start beyond max sequence should fail")
+	}
+	if _, err := NewUnregistered(1, 2, WithRandomSequenceStart(-1)); err == nil {
+		t.Error("negative random start should fail")
+	}
+	if _, err := sf.ForkSequence(2); err == nil {
+		t.Error("forking a generator with random start should fail")
+	}
+}
+
+func TestWithRandomSequenceStep(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sleeper := func(time.Duration) { clock.Add(time.Millisecond) }
+	sf, err := NewUnregistered(1, 2, WithClock(clock), WithSleeper(sleeper), WithRandomSequenceStep(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev, _ := sf.NextId()
+	steps := map[int64]bool{}
+	for i := 0; i < 2000; i++ {
+		id, err := sf.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id <= prev {
+			t.Fatalf("id %d isn't greater than %d", id, prev)
+		}
+		if ID(id).Parse().Timestamp() == ID(prev).Parse().Timestamp() {
+			step := id - prev
+			if step > 16 {
+				t.Fatalf("step %d is greater than 16", step)
+			}
+			steps[step] = true
+		}
+		prev = id
+	}
+	if len(steps) < 8 {
+		t.Errorf("only %d distinct steps", len(steps))
+	}
+	// 序列用尽后进入下一毫秒
+	if ts := ID(prev).Parse().Timestamp(); ts == twepoch+1000 {
+		t.Error("2000 random steps should exhaust the first millisecond")
+	}
+
+	if _, err := NewUnregistered(1, 2, WithRandomSequenceStep(0)); err == nil {
+		t.Error("zero random step should fail")
+	}
+}
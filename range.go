@@ -0,0 +1,77 @@
+package snowflake
+
+import "fmt"
+
+// NextIdN 在同一毫秒内分配最多n个连续的id，返回第一个id和实际分配的个数count，
+// 分配到的id为 firstID, firstID+1, ..., firstID+count-1，只有毫秒内序列不同。
+// 当前毫秒剩余的序列不足n个时，count小于n，调用方可以再次调用分配剩余的部分。
+func (s *Snowflake) NextIdN(n int) (firstID int64, count int, err error) {
+	if n <= 0 {
+		return 0, 0, fmt.Errorf("id count must be positive")
+	}
+	if s.seqStride != 0 {
+		return 0, 0, ErrForked
+	}
+	if s.shutdown.Load() {
+		return 0, 0, ErrShutdown
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.nextIdRange(n)
+}
+
+// NextIds 在一次加锁内生成n个id，当前毫秒的序列不够时继续使用之后的毫秒，
+// 同一毫秒内的id是连续的。用于批量插入等需要大量id的场景，避免循环调用 NextId 的加锁开销。
+// 中途出错（例如被限流）时返回nil和错误，已经分配的id不会被归还。
+func (s *Snowflake) NextIds(n int) ([]int64, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("id count must be positive")
+	}
+	if s.seqStride != 0 {
+		return nil, ErrForked
+	}
+	if s.shutdown.Load() {
+		return nil, ErrShutdown
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]int64, 0, n)
+	for len(ids) < n {
+		first, count, err := s.nextIdRange(n - len(ids))
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < count; i++ {
+			ids = append(ids, first+int64(i))
+		}
+	}
+	return ids, nil
+}
+
+// nextIdRange NextIdN 的实现，调用方需持有锁
+func (s *Snowflake) nextIdRange(n int) (firstID int64, count int, err error) {
+	firstID, err = s.nextId(s.timeGen())
+	if err != nil {
+		return 0, 0, err
+	}
+	extra := int64(n - 1)
+	if left := s.maxSequence - s.sequence; extra > left {
+		extra = left
+	}
+	if s.rateWindow != 0 {
+		// 每个id都计入滑动窗口限流
+		allowed := int64(0)
+		for allowed < extra && s.rateAllow(s.lastTimestamp) {
+			s.rateRecord(s.lastTimestamp)
+			allowed++
+		}
+		extra = allowed
+	}
+	for i := int64(1); i <= extra; i++ {
+		s.sample(firstID + i)
+	}
+	s.sequence += extra
+	s.lastId = firstID + extra
+	return firstID, int(extra) + 1, nil
+}
@@ -0,0 +1,113 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextIdN(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, err := NewUnregistered(5, 6, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, count, err := sf.NextIdN(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 100 {
+		t.Fatalf("count = %d, want 100", count)
+	}
+	p0 := ID(first).Parse()
+	for i := int64(0); i < int64(count); i++ {
+		p := ID(first + i).Parse()
+		if p.Timestamp() != p0.Timestamp() || p.WorkerId() != 5 || p.DatacenterId() != 6 || p.Sequence() != i {
+			t.Fatalf("id %d parsed as %+v", first+i, p)
+		}
+	}
+
+	// 下一个id紧跟在范围之后
+	next, err := sf.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != first+100 {
+		t.Errorf("NextId = %d, want %d", next, first+100)
+	}
+
+	// 剩余序列不足时只分配到本毫秒结束
+	first, count, err = sf.NextIdN(5000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := sequenceMask - 100; count != want {
+		t.Errorf("count = %d, want %d", count, want)
+	}
+	if p := ID(first + int64(count) - 1).Parse(); p.Sequence() != sequenceMask || p.Timestamp() != p0.Timestamp() {
+		t.Errorf("last id parsed as %+v", p)
+	}
+
+	if _, _, err := sf.NextIdN(0); err == nil {
+		t.Error("NextIdN(0) expected error")
+	}
+}
+
+func TestNextIdN_MaxSequence(t *testing.T) {
+	sf, err := NewUnregistered(5, 6, WithMaxSequence(9), WithVersionBits(2, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, count, err := sf.NextIdN(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count > 10 {
+		t.Fatalf("count = %d, want at most 10", count)
+	}
+	for i := int64(0); i < int64(count); i++ {
+		if p := ParseWithVersion(first+i, 2); p.Version() != 3 || p.Sequence() > 9 {
+			t.Fatalf("id %d parsed as %+v", first+i, p)
+		}
+	}
+}
+
+func TestNextIds(t *testing.T) {
+	sf, err := NewUnregistered(5, 6, WithMaxSequence(9))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids, err := sf.NextIds(25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 25 {
+		t.Fatalf("len = %d, want 25", len(ids))
+	}
+	// 每毫秒最多10个id，至少跨越3个毫秒
+	ms := map[int64]bool{}
+	for i, id := range ids {
+		if i > 0 && id <= ids[i-1] {
+			t.Fatalf("id %d isn't greater than %d", id, ids[i-1])
+		}
+		p := ID(id).Parse()
+		if p.Sequence() > 9 || p.WorkerId() != 5 || p.DatacenterId() != 6 {
+			t.Fatalf("id %d parsed as %+v", id, p)
+		}
+		ms[p.Timestamp()] = true
+	}
+	if len(ms) < 3 {
+		t.Errorf("ids span %d milliseconds, want at least 3", len(ms))
+	}
+	if last := sf.LastGeneratedID(); last != ids[24] {
+		t.Errorf("LastGeneratedID = %d, want %d", last, ids[24])
+	}
+
+	if _, err := sf.NextIds(0); err == nil {
+		t.Error("NextIds(0) expected error")
+	}
+	sf.Close()
+	if _, err := sf.NextIds(1); err != ErrShutdown {
+		t.Errorf("NextIds after Close = %v, want ErrShutdown", err)
+	}
+}
@@ -0,0 +1,141 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrRateLimited = errors.New("snowflake id rate limit exceeded")
+
+// 限流缓冲区的上限
+const (
+	maxRateRing    = 1 << 20 // WithSlidingWindowLimit 最多记录的时间戳个数(8MB)
+	maxRateBuckets = 1 << 12 // WithBucketedWindowLimit 最多的计数桶个数
+)
+
+// WithSlidingWindowLimit 按滑动窗口限制生成速度：任意windowSize时长内最多生成
+// maxPerSecond*windowSize/1s 个id，超出时返回 ErrRateLimited，不会等待。
+// 窗口内允许短时间的突发，只限制平均速度；窗口越长，允许的突发越大。
+// 生成器用环形缓冲区记录窗口内每个id的生成时间，缓冲区的大小即为窗口内允许的id个数，
+// 由这两个参数决定，例如每秒1000个、窗口10秒时缓冲区为10000个时间戳(80KB)。
+// 缓冲区超过 maxRateRing 个时间戳时返回错误，此时使用 WithBucketedWindowLimit。
+func WithSlidingWindowLimit(maxPerSecond int64, windowSize time.Duration) Option {
+	return func(s *Snowflake) error {
+		size, err := rateLimit(maxPerSecond, windowSize)
+		if err != nil {
+			return err
+		}
+		if size > maxRateRing {
+			return fmt.Errorf("rate limit window %v at %d per second needs %d timestamps, more than %d; use WithBucketedWindowLimit",
+				windowSize, maxPerSecond, size, maxRateRing)
+		}
+		s.rateWindow = windowSize.Milliseconds()
+		s.rateRing = make([]int64, size)
+		return nil
+	}
+}
+
+// WithBucketedWindowLimit 与 WithSlidingWindowLimit 相同，但把窗口平均分成buckets个计数桶，
+// 只记录每个桶内生成的id个数，内存与限制的速度无关，适合速度很高或窗口很长的限流。
+// 窗口按桶滑动，任意windowSize时长内最多可能多生成一个桶时长的id。
+// windowSize的毫秒数需要是buckets的整数倍，buckets最多 maxRateBuckets 个。
+func WithBucketedWindowLimit(maxPerSecond int64, windowSize time.Duration, buckets int) Option {
+	return func(s *Snowflake) error {
+		limit, err := rateLimit(maxPerSecond, windowSize)
+		if err != nil {
+			return err
+		}
+		window := windowSize.Milliseconds()
+		if buckets <= 0 || buckets > maxRateBuckets {
+			return fmt.Errorf("rate limit buckets must be between 1 and %d", maxRateBuckets)
+		}
+		if window%int64(buckets) != 0 {
+			return fmt.Errorf("rate limit window %v can't be split into %d buckets of whole milliseconds", windowSize, buckets)
+		}
+		s.rateWindow = window
+		s.rateLimit = limit
+		s.rateBucket = window / int64(buckets)
+		s.rateBuckets = make([]int64, buckets)
+		return nil
+	}
+}
+
+// rateLimit 窗口内允许的id个数
+func rateLimit(maxPerSecond int64, windowSize time.Duration) (int64, error) {
+	if maxPerSecond <= 0 {
+		return 0, fmt.Errorf("rate limit must be positive")
+	}
+	if windowSize < time.Millisecond {
+		return 0, fmt.Errorf("rate limit window must be at least 1ms")
+	}
+	limit := maxPerSecond * int64(windowSize) / int64(time.Second)
+	if limit < 1 {
+		return 0, fmt.Errorf("rate limit window %v allows no id at %d per second", windowSize, maxPerSecond)
+	}
+	return limit, nil
+}
+
+// rateAllow 是否允许在timestamp生成id，调用方需持有锁
+func (s *Snowflake) rateAllow(timestamp int64) bool {
+	return s.rateAllowN(timestamp, 1)
+}
+
+// rateAllowN 是否允许在timestamp生成n个id，调用方需持有锁
+func (s *Snowflake) rateAllowN(timestamp int64, n int64) bool {
+	if s.rateWindow == 0 {
+		return true
+	}
+	if s.rateBuckets != nil {
+		s.rateRotate(timestamp)
+		return s.rateTotal+n <= s.rateLimit
+	}
+	size := int64(len(s.rateRing))
+	if n > size {
+		return false
+	}
+	if int64(s.rateCount)+n <= size {
+		return true
+	}
+	// 缓冲区写满后，会覆盖的最晚一条记录移出窗口后才能生成
+	last := s.rateRing[(int64(s.rateNext)+n-1)%size]
+	return last <= timestamp-s.rateWindow
+}
+
+// rateRecord 记录在timestamp生成了id，调用方需持有锁
+func (s *Snowflake) rateRecord(timestamp int64) {
+	if s.rateWindow == 0 {
+		return
+	}
+	if s.rateBuckets != nil {
+		s.rateRotate(timestamp)
+		s.rateBuckets[s.rateHead%int64(len(s.rateBuckets))]++
+		s.rateTotal++
+		return
+	}
+	s.rateRing[s.rateNext] = timestamp
+	s.rateNext = (s.rateNext + 1) % len(s.rateRing)
+	if s.rateCount < len(s.rateRing) {
+		s.rateCount++
+	}
+}
+
+// rateRotate 清空已经移出窗口的计数桶，使 rateHead 为timestamp所在的桶，调用方需持有锁。
+// 时钟回退时沿用当前的桶
+func (s *Snowflake) rateRotate(timestamp int64) {
+	head := timestamp / s.rateBucket
+	if head <= s.rateHead {
+		return
+	}
+	n := int64(len(s.rateBuckets))
+	if head-s.rateHead >= n {
+		clear(s.rateBuckets)
+		s.rateTotal = 0
+	} else {
+		for b := s.rateHead + 1; b <= head; b++ {
+			s.rateTotal -= s.rateBuckets[b%n]
+			s.rateBuckets[b%n] = 0
+		}
+	}
+	s.rateHead = head
+}
@@ -0,0 +1,183 @@
+package snowflake
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithSlidingWindowLimit(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	// 每秒100个，窗口1秒
+	sf, err := NewUnregistered(1, 1, WithClock(clock), WithSlidingWindowLimit(100, time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := func() error {
+		_, err := sf.NextId()
+		return err
+	}
+
+	// 突发：前60个在第0毫秒
+	for i := 0; i < 60; i++ {
+		if err := next(); err != nil {
+			t.Fatalf("id %d: %v", i, err)
+		}
+	}
+	// 其余40个在第500毫秒
+	clock.Add(500 * time.Millisecond)
+	for i := 0; i < 40; i++ {
+		if err := next(); err != nil {
+			t.Fatalf("id %d: %v", 60+i, err)
+		}
+	}
+	if err := next(); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("101st id = %v, want ErrRateLimited", err)
+	}
+
+	// 第999毫秒时第0毫秒的记录仍在窗口内
+	clock.Add(499 * time.Millisecond)
+	if err := next(); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("at 999ms = %v, want ErrRateLimited", err)
+	}
+	// 第1000毫秒时第0毫秒的60个移出窗口
+	clock.Add(time.Millisecond)
+	for i := 0; i < 60; i++ {
+		if err := next(); err != nil {
+			t.Fatalf("at 1000ms id %d: %v", i, err)
+		}
+	}
+	if err := next(); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("61st id at 1000ms = %v, want ErrRateLimited", err)
+	}
+	// 第1500毫秒时第500毫秒的40个移出窗口
+	clock.Add(500 * time.Millisecond)
+	for i := 0; i < 40; i++ {
+		if err := next(); err != nil {
+			t.Fatalf("at 1500ms id %d: %v", i, err)
+		}
+	}
+	if err := next(); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("41st id at 1500ms = %v, want ErrRateLimited", err)
+	}
+}
+
+func TestWithSlidingWindowLimit_NoBreaker(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, err := NewUnregistered(1, 1, WithClock(clock),
+		WithSlidingWindowLimit(1, time.Second), WithCircuitBreaker(1, time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sf.NextId()
+	for i := 0; i < 3; i++ {
+		if _, err := sf.NextId(); !errors.Is(err, ErrRateLimited) {
+			t.Fatalf("err = %v, want ErrRateLimited", err)
+		}
+	}
+	// 限流不计入熔断
+	clock.Add(time.Second)
+	if _, err := sf.NextId(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithSlidingWindowLimit_Invalid(t *testing.T) {
+	for _, c := range []struct {
+		max    int64
+		window time.Duration
+	}{{0, time.Second}, {10, 0}, {1, 10 * time.Millisecond}, {1e6, time.Minute}} {
+		if _, err := NewUnregistered(1, 1, WithSlidingWindowLimit(c.max, c.window)); err == nil {
+			t.Errorf("WithSlidingWindowLimit(%d, %v) expected error", c.max, c.window)
+		}
+	}
+}
+
+func TestWithSlidingWindowLimit_NextIdN(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, err := NewUnregistered(1, 1, WithClock(clock), WithSlidingWindowLimit(10, time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, count, err := sf.NextIdN(100); err != nil || count != 10 {
+		t.Fatalf("NextIdN(100) = %d, %v, want 10", count, err)
+	}
+	if _, err := sf.NextId(); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("NextId = %v, want ErrRateLimited", err)
+	}
+}
+
+func TestWithSlidingWindowLimit_Priority(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, err := NewUnregistered(1, 1, WithClock(clock), WithSlidingWindowLimit(2, time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sf.NextId()
+	clock.Add(time.Millisecond) // 同一毫秒内优先级id会等待下一毫秒
+	if _, err := sf.NextIdWithPriority(0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sf.NextIdWithPriority(0); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("NextIdWithPriority = %v, want ErrRateLimited", err)
+	}
+}
+
+func TestWithBucketedWindowLimit(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	// 窗口1秒分成10个桶，每秒最多100个
+	sf, err := NewUnregistered(1, 1, WithClock(clock), WithBucketedWindowLimit(100, time.Second, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 60; i++ {
+		if _, err := sf.NextId(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	clock.Add(500 * time.Millisecond)
+	if _, count, err := sf.NextIdN(100); err != nil || count != 40 {
+		t.Fatalf("NextIdN(100) = %d, %v, want 40", count, err)
+	}
+	if _, err := sf.NextId(); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("NextId = %v, want ErrRateLimited", err)
+	}
+
+	// 第一批所在的桶移出窗口后释放60个
+	clock.Add(500 * time.Millisecond)
+	for i := 0; i < 60; i++ {
+		if _, err := sf.NextId(); err != nil {
+			t.Fatalf("id %d after window moved: %v", i, err)
+		}
+	}
+	if _, err := sf.NextId(); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("NextId = %v, want ErrRateLimited", err)
+	}
+
+	// 超过整个窗口没有生成后全部释放
+	clock.Add(time.Hour)
+	if _, count, err := sf.NextIdN(200); err != nil || count != 100 {
+		t.Fatalf("NextIdN(200) after idle = %d, %v, want 100", count, err)
+	}
+}
+
+func TestWithBucketedWindowLimit_Invalid(t *testing.T) {
+	for _, c := range []struct {
+		max     int64
+		window  time.Duration
+		buckets int
+	}{
+		{0, time.Second, 10},
+		{10, time.Second, 0},
+		{10, time.Second, 3},
+		{10, time.Hour, maxRateBuckets + 1},
+	} {
+		if _, err := NewUnregistered(1, 1, WithBucketedWindowLimit(c.max, c.window, c.buckets)); err == nil {
+			t.Errorf("WithBucketedWindowLimit(%d, %v, %d) expected error", c.max, c.window, c.buckets)
+		}
+	}
+	// 速度很高时内存与速度无关
+	if _, err := NewUnregistered(1, 1, WithBucketedWindowLimit(1e6, time.Minute, 60)); err != nil {
+		t.Error(err)
+	}
+}
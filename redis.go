@@ -0,0 +1,24 @@
+package snowflake
+
+import "fmt"
+
+// RedisClient 分配机器id用到的 Redis 命令，可以用任意 Redis 客户端包装实现
+type RedisClient interface {
+	Incr(key string) (int64, error)
+}
+
+// NewWithRedisWorkerID 对key执行 INCR，以结果对 MaxWorkersPerDatacenter(32) 取模作为机器id创建生成器，
+// 适用于自动扩缩容等无法手工分配机器id的场景。
+// 取模意味着同一datacenterID下同时运行超过32个实例时必然有实例拿到相同的机器id，
+// 实例反复重启后计数器继续增长，也可能与仍在运行的旧实例重复；需要严格不重复时使用 consul 子包。
+func NewWithRedisWorkerID(redisClient RedisClient, key string, datacenterID int64, opts ...Option) (*Snowflake, error) {
+	n, err := redisClient.Incr(key)
+	if err != nil {
+		return nil, fmt.Errorf("incr worker id key %s: %w", key, err)
+	}
+	workerId := n % MaxWorkersPerDatacenter
+	if workerId < 0 {
+		workerId += MaxWorkersPerDatacenter
+	}
+	return New(workerId, datacenterID, opts...)
+}
@@ -0,0 +1,40 @@
+package snowflake
+
+import (
+	"errors"
+	"testing"
+)
+
+// fakeRedis 模拟 INCR 的计数器
+type fakeRedis struct {
+	counters map[string]int64
+	err      error
+}
+
+func (r *fakeRedis) Incr(key string) (int64, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	r.counters[key]++
+	return r.counters[key], nil
+}
+
+func TestNewWithRedisWorkerID(t *testing.T) {
+	r := &fakeRedis{counters: map[string]int64{"sf:workers": 30}}
+
+	for _, want := range []int64{31, 0, 1} {
+		sf, err := NewWithRedisWorkerID(r, "sf:workers", 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer sf.Close()
+		if sf.workerId != want || sf.datacenterId != 4 {
+			t.Errorf("worker %d datacenter %d, want %d 4", sf.workerId, sf.datacenterId, want)
+		}
+	}
+
+	r.err = errors.New("connection refused")
+	if _, err := NewWithRedisWorkerID(r, "sf:workers", 4); !errors.Is(err, r.err) {
+		t.Errorf("err = %v, want %v", err, r.err)
+	}
+}
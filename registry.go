@@ -0,0 +1,74 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var ErrDuplicateNode = errors.New("duplicate snowflake node in process")
+
+// node 节点，即数据id和机器id的组合
+type node struct {
+	workerId     int64
+	datacenterId int64
+}
+
+// registry 进程内所有通过 New 创建、尚未 Close 的生成器。
+// 同一进程中两个节点相同的生成器会在同一毫秒内生成重复的id，因此 New 会拒绝创建。
+var registry sync.Map // node -> *Snowflake
+
+// retired 已经 Close 的生成器最后的时间戳和序列，相同节点的新生成器从这里继续生成
+var retired sync.Map // node -> retiredState
+
+// retiredState Close 时生成器的时间戳状态
+type retiredState struct {
+	lastTimestamp int64
+	sequence      int64
+	advancedUntil int64
+}
+
+// register 登记生成器，节点已被占用时返回 ErrDuplicateNode。
+// 节点此前的生成器已经 Close，且它最后的时间戳不早于当前时间、领先不超过 maxAdvance 时，
+// 新的生成器沿用它的时间戳和序列，避免在同一毫秒内重复，系统时间追上之前不视为时钟回退；更早的时间戳不会冲突，更晚的来自其它时钟，都忽略
+func (s *Snowflake) register() error {
+	n := node{s.workerId, s.datacenterId}
+	if _, loaded := registry.LoadOrStore(n, s); loaded {
+		return fmt.Errorf("%w: worker id %d, datacenter id %d", ErrDuplicateNode, s.workerId, s.datacenterId)
+	}
+	s.registered = true
+	if v, ok := retired.LoadAndDelete(n); ok {
+		r := v.(retiredState)
+		now := s.timeGen()
+		if r.lastTimestamp >= now && r.lastTimestamp-now <= maxAdvance.Milliseconds() && r.lastTimestamp >= s.lastTimestamp {
+			s.lastTimestamp = r.lastTimestamp
+			s.sequence = min(r.sequence, s.maxSequence)
+			s.advancedUntil = max(s.advancedUntil, r.advancedUntil, r.lastTimestamp) // 系统时间追上之前不视为时钟回退
+		}
+	}
+	return nil
+}
+
+// Close 停止生成id并注销生成器，之后可以用相同的节点创建新的生成器。
+// 注销后生成id的调用都返回 ErrShutdown；新的生成器从这个生成器最后的时间戳和序列继续生成，不会与它重复。
+// 使用 WithDriftMonitor 时，Close 同时停止时钟回退事件的输出。
+func (s *Snowflake) Close() error {
+	s.shutdown.Store(true)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.registered {
+		if s.lastTimestamp > 0 {
+			retired.Store(node{s.workerId, s.datacenterId}, retiredState{s.lastTimestamp, s.sequence, s.advancedUntil})
+		}
+		registry.CompareAndDelete(node{s.workerId, s.datacenterId}, s)
+		s.registered = false
+	}
+	s.stopDriftMonitor()
+	return nil
+}
+
+// NewUnregistered 与 New 相同，但不登记到进程内的节点注册表，主要用于测试。
+// 调用方需要自己保证节点不重复。
+func NewUnregistered(workerId int64, datacenterId int64, opts ...Option) (*Snowflake, error) {
+	return newSnowflake(workerId, datacenterId, false, opts)
+}
@@ -0,0 +1,84 @@
+package snowflake
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRegistry(t *testing.T) {
+	a, err := New(11, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(11, 12); !errors.Is(err, ErrDuplicateNode) {
+		t.Fatalf("duplicate New() = %v, want ErrDuplicateNode", err)
+	}
+	if _, err := NewFromIPv4([]byte{10, 0, 12, 11}); !errors.Is(err, ErrDuplicateNode) {
+		t.Fatalf("duplicate NewFromIPv4() = %v, want ErrDuplicateNode", err)
+	}
+
+	// 不登记的生成器不受限制
+	u, err := NewUnregistered(11, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.Close()
+
+	// 其它节点不受影响
+	other, err := New(12, 11)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	a.Close() // 重复调用无影响
+	if _, err := a.NextId(); !errors.Is(err, ErrShutdown) {
+		t.Fatalf("NextId() after Close() = %v, want ErrShutdown", err)
+	}
+	b, err := New(11, 12)
+	if err != nil {
+		t.Fatalf("New() after Close() = %v", err)
+	}
+	a.Close() // 已经注销的生成器不会影响新的生成器
+	if _, err := New(11, 12); !errors.Is(err, ErrDuplicateNode) {
+		t.Fatalf("New() = %v, want ErrDuplicateNode", err)
+	}
+	b.Close()
+}
+
+// Close 后立即用相同的节点创建的生成器，在同一毫秒内不会重复旧生成器的id
+func TestRegistry_CloseThenNewSameMillisecond(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	a, err := New(13, 14, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	last, err := a.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Close()
+
+	b, err := New(13, 14, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	id, err := b.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id <= last {
+		t.Fatalf("NextId() after Close and New = %d, want greater than %d", id, last)
+	}
+	// 系统时间追上之前不视为时钟回退
+	for i := 0; i < 10; i++ {
+		if _, err := b.NextId(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
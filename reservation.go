@@ -0,0 +1,61 @@
+package snowflake
+
+import "sync"
+
+const (
+	reservationPending = iota
+	reservationCommitted
+	reservationRolledBack
+)
+
+// Reservation 预留的id，需要在下游确认后 Commit，或者 Rollback 放弃
+type Reservation struct {
+	mu        sync.Mutex
+	s         *Snowflake
+	id        int64
+	timestamp int64
+	sequence  int64
+	state     int
+}
+
+// Reserve 预留一个id。预留的id已经占用了对应的毫秒内序列，不会再次生成。
+func (s *Snowflake) Reserve() (*Reservation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, err := s.nextId(s.timeGen())
+	if err != nil {
+		return nil, err
+	}
+	return &Reservation{s: s, id: id, timestamp: s.lastTimestamp, sequence: s.sequence}, nil
+}
+
+// Commit 确认使用预留的id并返回。已经 Rollback 的预留返回0。
+func (r *Reservation) Commit() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch r.state {
+	case reservationRolledBack:
+		return 0
+	case reservationPending:
+		r.state = reservationCommitted
+	}
+	return r.id
+}
+
+// Rollback 放弃预留的id。如果它是生成器最后生成的id，序列会被归还，下一次生成会得到同一个id；
+// 否则直接丢弃，留下一个空缺。已经 Commit 的预留调用 Rollback 无效。
+func (r *Reservation) Rollback() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.state != reservationPending {
+		return
+	}
+	r.state = reservationRolledBack
+
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lastTimestamp == r.timestamp && s.sequence == r.sequence {
+		s.sequence-- // 为0时变为-1，同一毫秒内下一次生成回到0
+	}
+}
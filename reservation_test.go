@@ -0,0 +1,52 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSnowflake_Reserve(t *testing.T) {
+	sf, err := New(0, 0, WithClock(newFakeClock(time.Now())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+
+	r1, err := sf.Reserve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r2, _ := sf.Reserve()
+	r3, _ := sf.Reserve()
+
+	id1 := r1.Commit()
+	if id1 != r1.id || r1.Commit() != id1 {
+		t.Fatalf("Commit() = %d, want %d", id1, r1.id)
+	}
+	r1.Rollback() // 已经确认，无效
+
+	r2.Rollback() // 不是最后一个，直接丢弃
+	if r2.Commit() != 0 {
+		t.Error("Commit() after Rollback should return 0")
+	}
+	id3 := r3.Commit()
+
+	next, err := sf.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next == r2.id || next == id1 || next <= id3 {
+		t.Errorf("NextId() = %d reuses a reserved id (%d, %d, %d)", next, id1, r2.id, id3)
+	}
+
+	// 最后生成的id回滚后，序列被归还
+	r4, _ := sf.Reserve()
+	r4.Rollback()
+	again, err := sf.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != r4.id {
+		t.Errorf("NextId() after rolling back the last reservation = %d, want %d", again, r4.id)
+	}
+}
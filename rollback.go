@@ -0,0 +1,69 @@
+package snowflake
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// ErrClockMovedBackwards 时钟回退时拒绝生成id返回的错误，Duration为回退的时长，
+// 可以用 errors.As 取得后等待 Duration 再重试
+type ErrClockMovedBackwards struct {
+	Duration time.Duration
+}
+
+func (e *ErrClockMovedBackwards) Error() string {
+	return fmt.Sprintf("Clock moved backwards.  Refusing to generate id for %d milliseconds", e.Duration.Milliseconds())
+}
+
+// WithRollbackWait 时钟回退不超过d时，等待系统时间追上上一次生成id的时间戳后继续生成，不返回错误。
+// 用于容忍NTP校时、虚拟机迁移等造成的短暂回退。等待期间持有锁，其它调用同样会等待，
+// d不宜过大。回退超过d时返回错误，或者按 WithLogicalClock 继续生成。
+func WithRollbackWait(d time.Duration) Option {
+	return func(s *Snowflake) error {
+		if d < 0 {
+			return fmt.Errorf("rollback wait can't be negative")
+		}
+		s.rollbackWait = d.Milliseconds()
+		return nil
+	}
+}
+
+// WithLogicalClock 时钟回退超过 WithRollbackWait 的等待时间时，沿用上一次生成id的时间戳作为逻辑时钟继续生成，
+// 序列用尽时逻辑时钟直接推进一毫秒，不等待系统时间，系统时间追上之后恢复使用系统时间。
+// 生成的id仍然单调递增，但其中的时间戳可能晚于实际的生成时间。
+func WithLogicalClock() Option {
+	return func(s *Snowflake) error {
+		s.logicalClock = true
+		return nil
+	}
+}
+
+// checkRollback 检查时钟是否回退，返回用于生成id的时间戳。
+// 回退时按 WithRollbackWait、WithLogicalClock 处理，都不适用时返回错误，调用方需持有锁
+func (s *Snowflake) checkRollback(timestamp int64) (int64, error) {
+	timestamp = s.advanced(timestamp)
+	// 当前时间戳小于上一次ID生成的时间戳，说明系统时钟回退过
+	if timestamp >= s.lastTimestamp {
+		return timestamp, nil
+	}
+	s.clockRollbacks++
+	s.reportDrift(timestamp)
+	skew := s.lastTimestamp - timestamp
+	s.logDebug("clock moved backwards", slog.Int64("skew_ms", skew),
+		slog.Int64("last_ts", s.lastTimestamp), slog.Int64("current_ts", timestamp))
+	switch {
+	case skew <= s.rollbackWait:
+		return s.tilNextMillis(s.lastTimestamp - 1)
+	case s.logicalClock:
+		s.advancedUntil = s.lastTimestamp
+		s.borrowing = false
+		return s.lastTimestamp, nil
+	}
+	return 0, &ErrClockMovedBackwards{Duration: time.Duration(skew) * time.Millisecond}
+}
+
+// toleratesRollback 回退skew毫秒时是否可以继续生成
+func (s *Snowflake) toleratesRollback(skew int64) bool {
+	return skew <= s.rollbackWait || s.logicalClock
+}
@@ -0,0 +1,104 @@
+package snowflake
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithRollbackWait(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, err := NewUnregistered(1, 2, WithClock(clock), WithRollbackWait(5*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, _ := sf.NextId()
+
+	clock.Add(-3 * time.Millisecond)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 3; i++ {
+			time.Sleep(time.Millisecond)
+			clock.Add(time.Millisecond)
+		}
+	}()
+	id, err := sf.NextId()
+	<-done
+	if err != nil {
+		t.Fatalf("rollback within wait: %v", err)
+	}
+	if id <= first {
+		t.Errorf("id %d isn't greater than %d", id, first)
+	}
+
+	clock.Add(-10 * time.Millisecond)
+	if _, err := sf.NextId(); err == nil {
+		t.Error("rollback beyond wait should fail")
+	}
+}
+
+func TestWithLogicalClock(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, err := NewUnregistered(1, 2, WithClock(clock), WithLogicalClock(), WithMaxSequence(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev, _ := sf.NextId()
+	clock.Add(-time.Second)
+
+	if _, err := sf.Peek(); err != nil {
+		t.Errorf("Peek with logical clock: %v", err)
+	}
+	// 序列用尽时逻辑时钟直接推进，不等待系统时间
+	for i := 0; i < 5; i++ {
+		id, err := sf.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id <= prev {
+			t.Fatalf("id %d isn't greater than %d", id, prev)
+		}
+		prev = id
+	}
+	if ts := ID(prev).Parse().Timestamp(); ts != twepoch+1002 {
+		t.Errorf("logical timestamp = %d, want %d", ts, twepoch+1002)
+	}
+
+	// 系统时间追上之后恢复使用系统时间
+	clock.Add(2 * time.Second)
+	id, err := sf.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts := ID(id).Parse().Timestamp(); ts != twepoch+2000 {
+		t.Errorf("timestamp after catching up = %d, want %d", ts, twepoch+2000)
+	}
+}
+
+func TestWithRollbackWait_Negative(t *testing.T) {
+	if _, err := NewUnregistered(1, 2, WithRollbackWait(-time.Millisecond)); err == nil {
+		t.Error("negative rollback wait should fail")
+	}
+}
+
+func TestErrClockMovedBackwards(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, err := NewUnregistered(1, 2, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sf.NextId()
+	clock.Add(-7 * time.Millisecond)
+
+	for name, gen := range map[string]func() (int64, error){"NextId": sf.NextId, "Peek": sf.Peek} {
+		_, err := gen()
+		var rollback *ErrClockMovedBackwards
+		if !errors.As(err, &rollback) {
+			t.Fatalf("%s: %v, want *ErrClockMovedBackwards", name, err)
+		}
+		if rollback.Duration != 7*time.Millisecond {
+			t.Errorf("%s: Duration = %v, want 7ms", name, rollback.Duration)
+		}
+	}
+}
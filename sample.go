@@ -0,0 +1,31 @@
+package snowflake
+
+import "fmt"
+
+// WithSamplingCallback 每生成k个id调用一次fn，即生成的id总数是k的倍数时以该id调用，用于抽样审计。
+// fn在生成id的锁内同步调用，不能再调用同一个生成器的方法，否则会死锁；耗时的处理应交给其他goroutine。
+func WithSamplingCallback(k int64, fn func(id int64)) Option {
+	return func(s *Snowflake) error {
+		if k <= 0 {
+			return fmt.Errorf("sampling interval must be positive")
+		}
+		if fn == nil {
+			return fmt.Errorf("sampling callback can't be nil")
+		}
+		s.sampleEvery = k
+		s.sampleFn = fn
+		return nil
+	}
+}
+
+// sample 记录生成了id，计入 Stats，达到抽样间隔时调用回调，调用方需持有锁
+func (s *Snowflake) sample(id int64) {
+	s.generated++
+	if s.sampleFn == nil {
+		return
+	}
+	s.sampleCount++
+	if s.sampleCount%s.sampleEvery == 0 {
+		s.sampleFn(id)
+	}
+}
@@ -0,0 +1,50 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithSamplingCallback(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	var sampled []int64
+	sf, err := NewUnregistered(1, 1, WithClock(clock), WithSamplingCallback(3, func(id int64) {
+		sampled = append(sampled, id)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []int64
+	for i := 0; i < 10; i++ {
+		id, err := sf.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	want := []int64{ids[2], ids[5], ids[8]}
+	if len(sampled) != len(want) {
+		t.Fatalf("sampled %d ids, want %d", len(sampled), len(want))
+	}
+	for i := range want {
+		if sampled[i] != want[i] {
+			t.Errorf("sampled[%d] = %d, want %d", i, sampled[i], want[i])
+		}
+	}
+
+	// NextIdN 分配的每个id都计入总数，第12个id是范围内的第二个
+	first, count, err := sf.NextIdN(4)
+	if err != nil || count != 4 {
+		t.Fatalf("NextIdN = %d, %v", count, err)
+	}
+	if len(sampled) != 4 || sampled[3] != first+1 {
+		t.Errorf("sampled = %v, want last %d", sampled, first+1)
+	}
+
+	for _, k := range []int64{0, -1} {
+		if _, err := NewUnregistered(1, 1, WithSamplingCallback(k, func(int64) {})); err == nil {
+			t.Errorf("WithSamplingCallback(%d) expected error", k)
+		}
+	}
+}
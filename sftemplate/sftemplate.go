@@ -0,0 +1,68 @@
+// Package sftemplate 提供在 text/template 中渲染雪花id的模板函数
+package sftemplate
+
+import (
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/pangush/snowflake"
+)
+
+// FuncMap 返回模板函数，参数可以是 int64 或 snowflake.ID：
+//
+//	snowflakeTime   id的生成时间(RFC3339，毫秒精度)
+//	snowflakeAge    距生成时间过去了多久
+//	snowflakeBase62 id的base62编码
+//	snowflakeValid  id是否有效
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"snowflakeTime":   formatTime,
+		"snowflakeAge":    age,
+		"snowflakeBase62": base62,
+		"snowflakeValid":  valid,
+	}
+}
+
+func toID(v interface{}) (snowflake.ID, error) {
+	switch id := v.(type) {
+	case snowflake.ID:
+		return id, nil
+	case int64:
+		return snowflake.ID(id), nil
+	default:
+		return 0, fmt.Errorf("snowflake: unsupported id type %T", v)
+	}
+}
+
+func formatTime(v interface{}) (string, error) {
+	id, err := toID(v)
+	if err != nil {
+		return "", err
+	}
+	return id.Time().Format("2006-01-02T15:04:05.000Z07:00"), nil
+}
+
+func age(v interface{}) (time.Duration, error) {
+	id, err := toID(v)
+	if err != nil {
+		return 0, err
+	}
+	return time.Since(id.Time()).Truncate(time.Millisecond), nil
+}
+
+func base62(v interface{}) (string, error) {
+	id, err := toID(v)
+	if err != nil {
+		return "", err
+	}
+	return snowflake.EncodeBase62(int64(id)), nil
+}
+
+func valid(v interface{}) (bool, error) {
+	id, err := toID(v)
+	if err != nil {
+		return false, err
+	}
+	return id.IsValid(), nil
+}
@@ -0,0 +1,56 @@
+package sftemplate
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/pangush/snowflake"
+)
+
+func render(t *testing.T, text string, data interface{}) (string, error) {
+	t.Helper()
+	tmpl, err := template.New("t").Funcs(FuncMap()).Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	err = tmpl.Execute(&b, data)
+	return b.String(), err
+}
+
+func TestFuncMap(t *testing.T) {
+	sf, err := snowflake.New(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := sf.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := snowflake.ID(raw)
+
+	for _, data := range []interface{}{raw, id} {
+		out, err := render(t, `{{snowflakeTime .}}|{{snowflakeBase62 .}}|{{snowflakeValid .}}`, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := id.Time().Format("2006-01-02T15:04:05.000Z07:00") + "|" + snowflake.EncodeBase62(raw) + "|true"
+		if out != want {
+			t.Errorf("render(%T) = %q, want %q", data, out, want)
+		}
+
+		out, err = render(t, `{{snowflakeAge .}}`, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d, err := time.ParseDuration(out); err != nil || d < 0 {
+			t.Errorf("snowflakeAge = %q, want a non-negative duration", out)
+		}
+	}
+
+	if _, err := render(t, `{{snowflakeTime .}}`, "123"); err == nil {
+		t.Error("expected error for unsupported type")
+	}
+}
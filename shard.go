@@ -0,0 +1,63 @@
+package snowflake
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// Pool 把请求轮流分给多个机器id不同的生成器，吞吐是单个生成器的n倍，互斥锁的竞争也分散到n个生成器上。
+// 每个生成器各自递增，但不同生成器的id交错，Pool 生成的id整体上只按毫秒有序，同一毫秒内不保证递增。
+type Pool struct {
+	shards []*Snowflake
+	next   atomic.Uint64
+}
+
+var _ Generator = (*Pool)(nil)
+
+// NewPool 使用datacenterId下的机器id firstWorkerId 到 firstWorkerId+n-1 创建n个生成器，
+// 这些节点都不能再被其它生成器使用。不再使用时需要调用 Close
+func NewPool(datacenterId int64, firstWorkerId int64, n int, opts ...Option) (*Pool, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("pool size must be positive")
+	}
+	p := &Pool{shards: make([]*Snowflake, 0, n)}
+	for i := 0; i < n; i++ {
+		s, err := New(firstWorkerId+int64(i), datacenterId, opts...)
+		if err != nil {
+			p.Close()
+			return nil, err
+		}
+		p.shards = append(p.shards, s)
+	}
+	return p, nil
+}
+
+// NextId 由下一个生成器生成id
+func (p *Pool) NextId() (int64, error) {
+	return p.shard().NextId()
+}
+
+// NextIds 由下一个生成器生成n个id，见 Snowflake.NextIds
+func (p *Pool) NextIds(n int) ([]int64, error) {
+	return p.shard().NextIds(n)
+}
+
+// Size 生成器的个数
+func (p *Pool) Size() int {
+	return len(p.shards)
+}
+
+// Close 注销所有生成器，返回遇到的第一个错误
+func (p *Pool) Close() error {
+	var first error
+	for _, s := range p.shards {
+		if err := s.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func (p *Pool) shard() *Snowflake {
+	return p.shards[(p.next.Add(1)-1)%uint64(len(p.shards))]
+}
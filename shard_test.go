@@ -0,0 +1,64 @@
+package snowflake
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestPool(t *testing.T) {
+	p, err := NewPool(3, 4, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if p.Size() != 8 {
+		t.Errorf("Size = %d, want 8", p.Size())
+	}
+
+	var mu sync.Mutex
+	seen := map[int64]bool{}
+	workers := map[int64]int{}
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				id, err := p.NextId()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				mu.Lock()
+				if seen[id] {
+					t.Errorf("duplicate id %d", id)
+				}
+				seen[id] = true
+				workers[ID(id).Parse().WorkerId()]++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	// 轮流分配，每个生成器生成的个数相同
+	for w := int64(4); w < 12; w++ {
+		if workers[w] != 1000 {
+			t.Errorf("worker %d generated %d ids, want 1000", w, workers[w])
+		}
+	}
+
+	ids, err := p.NextIds(10)
+	if err != nil || len(ids) != 10 {
+		t.Fatalf("NextIds = %v, %v", ids, err)
+	}
+
+	// 机器id超出范围时创建失败，已创建的生成器被注销
+	if _, err := NewPool(3, 30, 4); err == nil {
+		t.Fatal("pool beyond the worker id range should fail")
+	}
+	s, err := New(30, 3)
+	if err != nil {
+		t.Fatalf("worker 30 wasn't released: %v", err)
+	}
+	s.Close()
+}
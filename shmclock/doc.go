@@ -0,0 +1,4 @@
+// Package shmclock 从共享内存读取时间，让同一台机器上的多个进程使用同一个协调进程写入的毫秒时间戳。
+// 共享内存为 /dev/shm 等目录下的一个文件，前8字节是原子更新的int64毫秒时间戳(unix时间戳，本机字节序)。
+// 只支持unix系统。
+package shmclock
@@ -0,0 +1,116 @@
+//go:build unix
+
+package shmclock
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/pangush/snowflake"
+)
+
+// size 共享内存的大小
+const size = 8
+
+// staleAfter 共享内存中的时间戳落后本机时间超过该值时，认为协调进程已停止更新
+const staleAfter = 10 * time.Millisecond
+
+// Clock 从共享内存读取时间的时钟
+type Clock struct {
+	data []byte
+	ts   *int64
+}
+
+var _ snowflake.Clock = (*Clock)(nil)
+
+// NewSharedMemoryClock 以只读方式映射shmPath，返回的时钟读取协调进程写入的时间戳；
+// 共享内存10毫秒内没有更新时使用 time.Now()。返回值实现了 io.Closer，不再使用时应关闭。
+func NewSharedMemoryClock(shmPath string) (snowflake.Clock, error) {
+	f, err := os.Open(shmPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, err := mmap(f, syscall.PROT_READ)
+	if err != nil {
+		return nil, err
+	}
+	return &Clock{data: data, ts: (*int64)(unsafe.Pointer(&data[0]))}, nil
+}
+
+// Now 返回共享内存中的时间，共享内存没有及时更新时返回本机时间
+func (c *Clock) Now() time.Time {
+	now := time.Now()
+	ms := atomic.LoadInt64(c.ts)
+	if now.UnixMilli()-ms > staleAfter.Milliseconds() {
+		return now
+	}
+	return time.UnixMilli(ms)
+}
+
+// Close 解除映射
+func (c *Clock) Close() error {
+	return syscall.Munmap(c.data)
+}
+
+// Writer 协调进程写入共享内存时间戳
+type Writer struct {
+	data []byte
+	ts   *int64
+}
+
+// NewWriter 创建或打开shmPath并以读写方式映射
+func NewWriter(shmPath string) (*Writer, error) {
+	f, err := os.OpenFile(shmPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if err := f.Truncate(size); err != nil {
+		return nil, err
+	}
+	data, err := mmap(f, syscall.PROT_READ|syscall.PROT_WRITE)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{data: data, ts: (*int64)(unsafe.Pointer(&data[0]))}, nil
+}
+
+// Store 原子写入时间戳
+func (w *Writer) Store(t time.Time) {
+	atomic.StoreInt64(w.ts, t.UnixMilli())
+}
+
+// Run 每毫秒写入一次本机时间，直到done被关闭
+func (w *Writer) Run(done <-chan struct{}) {
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+	for {
+		w.Store(time.Now())
+		select {
+		case <-ticker.C:
+		case <-done:
+			return
+		}
+	}
+}
+
+// Close 解除映射，共享内存文件保留
+func (w *Writer) Close() error {
+	return syscall.Munmap(w.data)
+}
+
+func mmap(f *os.File, prot int) ([]byte, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if fi.Size() < size {
+		return nil, fmt.Errorf("shared memory %s is smaller than %d bytes", f.Name(), size)
+	}
+	return syscall.Mmap(int(f.Fd()), 0, size, prot, syscall.MAP_SHARED)
+}
@@ -0,0 +1,80 @@
+//go:build unix
+
+package shmclock
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pangush/snowflake"
+)
+
+func TestSharedMemoryClock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snowflake-clock")
+	w, err := NewWriter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	clock, err := NewSharedMemoryClock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clock.(io.Closer).Close()
+
+	// 协调进程写入的时间略早于本机时间
+	at := time.Now().Add(-3 * time.Millisecond).Truncate(time.Millisecond)
+	w.Store(at)
+	if got := clock.Now(); !got.Equal(at) {
+		t.Errorf("Now() = %v, want %v", got, at)
+	}
+
+	sf, err := snowflake.NewUnregistered(1, 1, snowflake.WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := sf.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := snowflake.ID(id).Time(); !got.Equal(at) {
+		t.Errorf("id time = %v, want %v", got, at)
+	}
+
+	// 超过10毫秒没有更新，使用本机时间
+	w.Store(time.Now().Add(-time.Second))
+	if d := time.Since(clock.Now()); d < 0 || d > 5*time.Millisecond {
+		t.Errorf("stale Now() differs from time.Now() by %v", d)
+	}
+
+	// 后台持续更新
+	done, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		w.Run(done)
+		close(stopped)
+	}()
+	time.Sleep(5 * time.Millisecond)
+	if d := time.Since(clock.Now()); d < 0 || d > 5*time.Millisecond {
+		t.Errorf("Now() lags by %v", d)
+	}
+	close(done)
+	<-stopped
+}
+
+func TestNewSharedMemoryClock_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewSharedMemoryClock(filepath.Join(dir, "missing")); err == nil {
+		t.Error("missing file expected error")
+	}
+	small := filepath.Join(dir, "small")
+	if err := os.WriteFile(small, []byte{1, 2}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewSharedMemoryClock(small); err == nil {
+		t.Error("small file expected error")
+	}
+}
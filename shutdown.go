@@ -0,0 +1,19 @@
+package snowflake
+
+import "errors"
+
+var ErrShutdown = errors.New("snowflake generator is shut down")
+
+// Shutdown 停止生成id，之后所有生成id的调用都返回 ErrShutdown。
+// Shutdown 返回时，正在进行中的生成也已经结束，不会再有id被返回。
+func (s *Snowflake) Shutdown() {
+	s.shutdown.Store(true)
+	// 等待持有锁的生成结束
+	s.mu.Lock()
+	s.mu.Unlock()
+}
+
+// IsShutdown 是否已经调用过 Shutdown，不会阻塞
+func (s *Snowflake) IsShutdown() bool {
+	return s.shutdown.Load()
+}
@@ -0,0 +1,56 @@
+package snowflake
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSnowflake_Shutdown(t *testing.T) {
+	sf, err := NewUnregistered(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sf.IsShutdown() {
+		t.Fatal("new generator is shut down")
+	}
+
+	var done atomic.Bool
+	var leaked atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				after := done.Load()
+				_, err := sf.NextId()
+				if err == nil && after {
+					leaked.Add(1)
+				}
+				if errors.Is(err, ErrShutdown) {
+					return
+				}
+			}
+		}()
+	}
+	time.Sleep(5 * time.Millisecond)
+	sf.Shutdown()
+	done.Store(true)
+	wg.Wait()
+
+	if n := leaked.Load(); n > 0 {
+		t.Errorf("%d ids returned after Shutdown", n)
+	}
+	if !sf.IsShutdown() {
+		t.Error("IsShutdown() = false after Shutdown")
+	}
+	if _, err := sf.NextIdWithSnapshot(); !errors.Is(err, ErrShutdown) {
+		t.Errorf("NextIdWithSnapshot() = %v, want ErrShutdown", err)
+	}
+	if _, err := sf.Reserve(); !errors.Is(err, ErrShutdown) {
+		t.Errorf("Reserve() = %v, want ErrShutdown", err)
+	}
+}
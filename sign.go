@@ -0,0 +1,33 @@
+package snowflake
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// signatureSize 签名截断后的字节数
+const signatureSize = 4
+
+// SignedNextId 生成id并返回以key计算的HMAC-SHA256签名，签名截断为4字节。
+// 截断后只有约2^32的强度，不能作为加密意义上的防伪，只适合检测客户端篡改id等低风险场景。
+func (s *Snowflake) SignedNextId(key []byte) (id int64, sig []byte, err error) {
+	id, err = s.NextId()
+	if err != nil {
+		return 0, nil, err
+	}
+	return id, signID(id, key), nil
+}
+
+// VerifyID 校验 SignedNextId 返回的签名。更换密钥期间可以依次用新旧密钥校验。
+func VerifyID(id int64, sig []byte, key []byte) bool {
+	return len(sig) == signatureSize && hmac.Equal(sig, signID(id, key))
+}
+
+func signID(id int64, key []byte) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(id))
+	mac := hmac.New(sha256.New, key)
+	mac.Write(buf[:])
+	return mac.Sum(nil)[:signatureSize]
+}
@@ -0,0 +1,50 @@
+package snowflake
+
+import "testing"
+
+func TestSignedNextId(t *testing.T) {
+	sf, err := NewUnregistered(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldKey, newKey := []byte("old-secret"), []byte("new-secret")
+
+	id, sig, err := sf.SignedNextId(oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sig) != 4 {
+		t.Fatalf("signature length = %d, want 4", len(sig))
+	}
+	if !VerifyID(id, sig, oldKey) {
+		t.Error("valid signature rejected")
+	}
+
+	// 篡改id或签名
+	if VerifyID(id+1, sig, oldKey) {
+		t.Error("signature accepted for a different id")
+	}
+	bad := append([]byte(nil), sig...)
+	bad[0] ^= 1
+	if VerifyID(id, bad, oldKey) {
+		t.Error("tampered signature accepted")
+	}
+	if VerifyID(id, sig[:3], oldKey) || VerifyID(id, nil, oldKey) {
+		t.Error("short signature accepted")
+	}
+
+	// 更换密钥：旧签名只能用旧密钥校验，新签名只能用新密钥校验
+	id2, sig2, err := sf.SignedNextId(newKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if VerifyID(id, sig, newKey) || VerifyID(id2, sig2, oldKey) {
+		t.Error("signature accepted with the wrong key")
+	}
+	verify := func(id int64, sig []byte) bool {
+		return VerifyID(id, sig, newKey) || VerifyID(id, sig, oldKey)
+	}
+	if !verify(id, sig) || !verify(id2, sig2) {
+		t.Error("rotation window rejected a valid signature")
+	}
+}
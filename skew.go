@@ -0,0 +1,53 @@
+package snowflake
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// skewClock 模拟时钟回退：每次读取前进1毫秒，到达起点之后skewMs毫秒时回退到起点，再继续前进
+type skewClock struct {
+	mu     sync.Mutex
+	now    int64
+	end    int64 // 到达该时间戳后回退
+	skewMs int64
+	jumped bool
+}
+
+func (c *skewClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := c.now
+	if !c.jumped && t >= c.end {
+		c.jumped = true
+		t -= c.skewMs
+	}
+	c.now = t + 1
+	return time.UnixMilli(t)
+}
+
+// SimulateClockSkew 在测试中模拟时钟回退：临时把s的时钟替换为每次读取前进1毫秒的模拟时钟，
+// 前进skewMs毫秒后回退skewMs毫秒，然后继续前进，在此期间调用fn(s)，返回后恢复原来的时钟。
+// 模拟时钟从s当前的时间开始，结束时可能领先真实时间，恢复后 NextId 会按时钟回退处理，
+// 直到真实时间追上，因此s不应再用于测试之外的场景。
+func SimulateClockSkew(s *Snowflake, skewMs int64, fn func(*Snowflake) error) error {
+	if skewMs <= 0 {
+		return fmt.Errorf("clock skew must be positive")
+	}
+	s.mu.Lock()
+	original := s.clock
+	start := s.timeGen()
+	if start < s.lastTimestamp {
+		start = s.lastTimestamp
+	}
+	s.clock = &skewClock{now: start, end: start + skewMs, skewMs: skewMs}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.clock = original
+		s.mu.Unlock()
+	}()
+	return fn(s)
+}
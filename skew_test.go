@@ -0,0 +1,57 @@
+package snowflake
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSimulateClockSkew(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, err := NewUnregistered(1, 1, WithClock(clock), WithCircuitBreaker(1000, time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ok, backwards int
+	var last int64
+	err = SimulateClockSkew(sf, 5, func(s *Snowflake) error {
+		for i := 0; i < 40; i++ {
+			id, err := s.NextId()
+			if err != nil {
+				backwards++
+				continue
+			}
+			if id <= last {
+				return errors.New("ids are not increasing")
+			}
+			last = id
+			ok++
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if backwards == 0 || ok == 0 {
+		t.Errorf("ok %d, backwards %d: want both", ok, backwards)
+	}
+	// 回退5毫秒后需要约5次读取才能追上
+	if backwards < 4 || backwards > 6 {
+		t.Errorf("backwards = %d, want about 5", backwards)
+	}
+
+	// 恢复原来的时钟
+	if sf.clock != Clock(clock) {
+		t.Error("clock was not restored")
+	}
+
+	// fn的错误原样返回
+	want := errors.New("boom")
+	if err := SimulateClockSkew(sf, 1, func(*Snowflake) error { return want }); err != want {
+		t.Errorf("SimulateClockSkew = %v, want %v", err, want)
+	}
+	if err := SimulateClockSkew(sf, 0, func(*Snowflake) error { return nil }); err == nil {
+		t.Error("zero skew expected error")
+	}
+}
@@ -0,0 +1,73 @@
+package snowflake
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+// IDAttr 以字符串形式记录id，避免日志系统将其转换为浮点数丢失精度
+func IDAttr(key string, id int64) slog.Attr {
+	return slog.String(key, strconv.FormatInt(id, 10))
+}
+
+// AttrsFromID 返回id及其解析出的生成时间、机器id、数据id，用于结构化日志
+func AttrsFromID(id int64, epoch time.Time) []slog.Attr {
+	p := ParseWithEpoch(id, epoch)
+	return []slog.Attr{
+		slog.Int64("id", id),
+		slog.Time("generated_at", p.Time()),
+		slog.Int64("worker_id", p.WorkerId()),
+		slog.Int64("datacenter_id", p.DatacenterId()),
+	}
+}
+
+// WithLogger 使用logger输出日志，替代创建生成器时默认用 log.Printf 输出的启动信息，
+// 时钟回退、序列用尽等事件以Debug级别输出。使用丢弃输出的logger可以关闭日志，例如
+//
+//	snowflake.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Snowflake) error {
+		if logger == nil {
+			return fmt.Errorf("logger can't be nil")
+		}
+		s.logger = logger
+		return nil
+	}
+}
+
+// logStart 输出启动信息
+func (s *Snowflake) logStart() {
+	if s.logger == nil {
+		log.Printf("worker starting. timestamp left shift %d, datacenter id bits %d, worker id bits %d, sequence bits %d, workerid %d%s",
+			s.timestampShift, s.layout.DatacenterBits, s.layout.WorkerBits, s.layout.SequenceBits, s.workerId, s.metadataSuffix())
+		return
+	}
+	attrs := []slog.Attr{
+		slog.Int("timestamp_left_shift", int(s.timestampShift)),
+		slog.Int("datacenter_id_bits", int(s.layout.DatacenterBits)),
+		slog.Int("worker_id_bits", int(s.layout.WorkerBits)),
+		slog.Int("sequence_bits", int(s.layout.SequenceBits)),
+	}
+	s.logger.LogAttrs(context.Background(), slog.LevelInfo, "worker starting", append(attrs, s.nodeAttrs()...)...)
+}
+
+// logDebug 以Debug级别输出事件，没有使用 WithLogger 时不输出
+func (s *Snowflake) logDebug(msg string, attrs ...slog.Attr) {
+	if s.logger == nil || !s.logger.Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+	s.logger.LogAttrs(context.Background(), slog.LevelDebug, msg, append(attrs, s.nodeAttrs()...)...)
+}
+
+// nodeAttrs 日志中标识生成器的机器id、数据id和 WithMetadata 设置的元数据（按键排序）
+func (s *Snowflake) nodeAttrs() []slog.Attr {
+	attrs := []slog.Attr{slog.Int64("worker_id", s.workerId), slog.Int64("datacenter_id", s.datacenterId)}
+	for _, k := range s.metadataKeys() {
+		attrs = append(attrs, slog.String(k, s.metadata[k]))
+	}
+	return attrs
+}
@@ -0,0 +1,103 @@
+package snowflake
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestIDAttr(t *testing.T) {
+	a := IDAttr("order_id", 1234567890123456789)
+	if a.Key != "order_id" || a.Value.Kind() != slog.KindString || a.Value.String() != "1234567890123456789" {
+		t.Errorf("IDAttr = %v", a)
+	}
+}
+
+func TestAttrsFromID(t *testing.T) {
+	epoch := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	id := int64(5000)<<timestampLeftShift | 3<<datacenterIdShift | 4<<workerIdShift | 1
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	logger.LogAttrs(context.Background(), slog.LevelInfo, "generated", AttrsFromID(id, epoch)...)
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["worker_id"] != float64(4) || m["datacenter_id"] != float64(3) {
+		t.Errorf("worker_id %v datacenter_id %v, want 4 3", m["worker_id"], m["datacenter_id"])
+	}
+	if want := epoch.Add(5 * time.Second).Format(time.RFC3339); m["generated_at"] != want {
+		t.Errorf("generated_at = %v, want %v", m["generated_at"], want)
+	}
+	if _, ok := m["id"]; !ok {
+		t.Error("missing id attribute")
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, err := NewUnregistered(1, 2, WithClock(clock), WithLogger(logger), WithLogicalClock(), WithMaxSequence(1),
+		WithMetadata(map[string]string{"region": "us-east-1"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sf.NextId()
+	clock.Add(-5 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		if _, err := sf.NextId(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	msgs := map[string]map[string]any{}
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var rec map[string]any
+		if err := dec.Decode(&rec); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := msgs[rec["msg"].(string)]; !ok {
+			msgs[rec["msg"].(string)] = rec
+		}
+	}
+	start := msgs["worker starting"]
+	if start == nil || start["level"] != "INFO" || start["worker_id"] != float64(1) || start["datacenter_id"] != float64(2) || start["region"] != "us-east-1" {
+		t.Errorf("start record = %v", start)
+	}
+	rollback := msgs["clock moved backwards"]
+	if rollback == nil || rollback["level"] != "DEBUG" || rollback["skew_ms"] != float64(5) || rollback["region"] != "us-east-1" ||
+		rollback["last_ts"] != float64(twepoch+1000) || rollback["current_ts"] != float64(twepoch+995) {
+		t.Errorf("rollback record = %v", rollback)
+	}
+	if exhausted := msgs["sequence exhausted"]; exhausted == nil || exhausted["level"] != "DEBUG" || exhausted["region"] != "us-east-1" {
+		t.Errorf("exhausted record = %v", exhausted)
+	}
+}
+
+func TestWithLogger_Levels(t *testing.T) {
+	var buf bytes.Buffer
+	// Info级别不输出Debug事件
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, err := NewUnregistered(1, 2, WithClock(clock), WithLogger(logger), WithLogicalClock())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sf.NextId()
+	clock.Add(-5 * time.Millisecond)
+	sf.NextId()
+	if out := buf.String(); !bytes.Contains(buf.Bytes(), []byte("worker starting")) || bytes.Contains(buf.Bytes(), []byte("clock moved backwards")) {
+		t.Errorf("output = %q", out)
+	}
+
+	if _, err := NewUnregistered(1, 2, WithLogger(nil)); err == nil {
+		t.Error("nil logger should fail")
+	}
+}
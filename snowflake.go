@@ -1,9 +1,13 @@
 package snowflake
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
+	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -28,6 +32,20 @@ const (
 	datacenterIdShift = sequenceBits + workerIdBits // 数据id左移位数
 	timestampLeftShift = sequenceBits + workerIdBits + datacenterIdBits // 时间戳左移位数
 	sequenceMask = -1 ^ (-1 << sequenceBits) // 毫秒内序列最大值
+
+	timestampBits = 63 - timestampLeftShift // 时间戳所占位数
+	maxTimestamp = -1 ^ (-1 << timestampBits) // 时间戳最大值
+)
+
+// 供外部校验配置使用的取值范围
+const (
+	MaxWorkerID     = maxWorkerId // 机器id最大值
+	MaxDatacenterID = maxDatacenterId // 数据id最大值
+	MaxNodeID       = -1 ^ (-1 << (workerIdBits + datacenterIdBits)) // 数据id和机器id组合后的节点最大值
+
+	MaxWorkersPerDatacenter = maxWorkerId + 1 // 每个数据中心最多的生成器个数
+	MaxDatacenters          = maxDatacenterId + 1 // 数据中心个数上限
+	MaxConcurrentGenerators = (maxWorkerId + 1) * (maxDatacenterId + 1) // 可以同时生成id的生成器个数上限
 )
 
 type Snowflake struct {
@@ -36,53 +54,304 @@ type Snowflake struct {
 	workerId     	int64
 	datacenterId 	int64
 	sequence     	int64
+
+	sequenceMask	int64 // 毫秒内序列字段的最大值
+	maxSequence 	int64 // 实际使用的毫秒内序列最大值，不超过sequenceMask
+	versionBits 	uint8 // 版本号所占位数，从毫秒内序列的高位划出
+	datacenterVersionBits	uint8 // 版本号所占位数，从数据id的高位划出
+	versionValue	int64 // 版本号
+	version     	int64 // 版本号左移后的值
+
+	layout        	BitLayout // 各字段所占位数，默认为 DefaultLayout
+	workerShift   	uint8     // 机器id左移位数
+	datacenterShift	uint8     // 数据id左移位数
+	timestampShift	uint8     // 时间戳左移位数
+	timestampMax  	int64     // 时间戳最大值
+
+	epoch       	int64 // 起始时间(时间戳/毫秒)
+	epochSet    	bool  // 是否通过 WithEpoch 设置了起始时间，创建时检查不晚于 clock 的当前时间
+	clock       	Clock // 时间源
+	hasher      	func(input []byte) uint64 // 由主机名等信息计算节点时使用的哈希函数
+	registered  	bool // 是否登记在进程内的节点注册表中
+	shutdown    	atomic.Bool // 是否已停止生成
+	littleEndian	bool // 二进制编码是否使用小端序
+
+	breakerThreshold	int   // 连续失败多少次后熔断，0表示不启用
+	breakerCooldown 	int64 // 熔断持续的毫秒数
+	failures        	int   // 连续失败次数
+	openUntil       	int64 // 熔断结束的时间戳
+
+	advancedUntil	int64 // NextIdAfter 推进到的时间戳，系统时间追上之前沿用推进后的时间戳
+
+	rollbackWait	int64 // 时钟回退不超过多少毫秒时等待，0表示不等待
+	logicalClock	bool  // 时钟回退较大时是否沿用上一次的时间戳继续生成
+
+	priorityTimestamp	int64    // 按优先级生成id的毫秒
+	prioritySequence 	[4]int64 // 该毫秒内各优先级的下一个序列
+
+	driftWriter   	io.Writer       // 时钟回退事件的输出，nil表示不启用
+	driftThreshold	int64           // 回退超过多少毫秒时输出
+	driftEvents   	chan driftEvent // 待输出的时钟回退事件
+
+	rateWindow	int64   // 滑动窗口的毫秒数，0表示不启用
+	rateRing  	[]int64 // 窗口内最近生成id的时间戳
+	rateNext  	int     // rateRing中下一个写入的位置
+	rateCount 	int     // rateRing中已写入的个数
+
+	rateLimit  	int64   // WithBucketedWindowLimit 窗口内允许的id个数
+	rateBucket 	int64   // 每个计数桶的毫秒数
+	rateBuckets	[]int64 // 最近每个桶内生成的id个数，nil表示使用 rateRing
+	rateHead   	int64   // 最近一个桶的编号，即时间戳除以 rateBucket
+	rateTotal  	int64   // rateBuckets 的总和
+
+	metadata     	map[string]string         // 标识生成器的元数据，创建后不再变化
+	logger       	*slog.Logger              // 日志输出，nil表示启动信息使用 log.Printf，其它事件不输出
+	startupJitter	time.Duration             // 创建时随机等待的最长时间
+	randomStart  	int64                     // 每毫秒序列起始值的随机范围，0表示从0开始
+	randomStep   	int64                     // 毫秒内序列每次增加的随机范围，0或1表示每次加1
+	borrowLead   	int64                     // WithBorrowFuture 允许时间戳领先系统时间的毫秒数，0表示不借用
+	borrowing    	bool                      // 当前推进后的时间戳是否是借用得到的
+	sleep        	func(d time.Duration)     // 等待使用的函数，默认为 time.Sleep
+
+	sampleEvery	int64            // 每生成多少个id抽样一次
+	sampleFn   	func(id int64)   // 抽样回调，nil表示不启用
+	sampleCount	int64            // 已生成的id总数
+
+	timeBoxUntil	int64 // 停止生成id的时间戳，0表示不限制
+
+	decorators	[]IDDecorator // 生成id前后调用的装饰器
+
+	seqOffset	int64 // ForkSequence 分出的生成器使用的第一个序列
+	seqStride	int64 // ForkSequence 分出的生成器序列的步长，0表示不分叉
+
+	leaseTTL    	time.Duration // 机器id租约的有效期
+	leaseStarted	bool          // 是否已经调用过 StartLeaseRenewal
+	leaseLost   	atomic.Bool   // 租约是否已经丢失
+
+	lastId	int64 // 最近一次返回的id
+	waitCtx	context.Context // NextIdContext 的ctx，结束时停止等待下一毫秒，其它调用为nil
+
+	generated     	int64 // 生成的id总数
+	sequenceWaits 	int64 // 毫秒内序列用尽的次数
+	clockRollbacks	int64 // 检测到时钟回退的次数
+	borrowed      	int64 // WithBorrowFuture 借用下一毫秒的次数
+
+	exhaustStart	int64 // Check 统计序列用尽频率的当前分段的起始时间戳
+	exhaustCur  	int64 // 当前分段内序列用尽的次数
+	exhaustPrev 	int64 // 上一分段内序列用尽的次数
+
+	stateStore   	StateStore // 持久化已经使用到的时间戳，nil表示不启用
+	stateInterval	int64      // 每次保存的时间戳超前多少毫秒
+	stateSaved   	int64      // 最近一次保存的时间戳
 }
 
-func New(workerId int64, datacenterId int64) (*Snowflake, error) {
-	if workerId < 0 || workerId > maxWorkerId {
-		return nil, fmt.Errorf("worker Id can't be greater than %d or less than 0", maxWorkerId)
-	}
-	if datacenterId < 0 || datacenterId > maxDatacenterId {
-		return nil, fmt.Errorf("datacenter Id can't be greater than %d or less than 0", datacenterId)
-	}
+// New 创建生成器，同一进程内节点不能重复，不再使用时需要调用 Close
+func New(workerId int64, datacenterId int64, opts ...Option) (*Snowflake, error) {
+	return newSnowflake(workerId, datacenterId, true, opts)
+}
 
-	log.Printf("worker starting. timestamp left shift %d, datacenter id bits %d, worker id bits %d, sequence bits %d, workerid %d",
-		timestampLeftShift, datacenterIdBits, workerIdBits, sequenceBits, workerId)
+func newSnowflake(workerId int64, datacenterId int64, register bool, opts []Option) (*Snowflake, error) {
+	s, err := applyOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	s.workerId = workerId
+	s.datacenterId = datacenterId
+	return s.init(register)
+}
 
-	return &Snowflake{
+// applyOptions 按默认值创建生成器并应用配置，节点由调用方设置
+func applyOptions(opts []Option) (*Snowflake, error) {
+	s := &Snowflake{
 		lastTimestamp: 0,
-		workerId:      workerId,
-		datacenterId:  datacenterId,
 		sequence:      0,
-	}, nil
+		maxSequence:   -1,
+		layout:        DefaultLayout,
+		epoch:         twepoch,
+		clock:         systemClock{},
+		hasher:        FNV1aHasher,
+		sleep:         time.Sleep,
+	}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// init 检查配置并完成创建，register为true时登记到进程内的节点注册表
+func (s *Snowflake) init(register bool) (*Snowflake, error) {
+	if epoch := time.UnixMilli(s.epoch); s.epochSet && epoch.After(s.clock.Now()) {
+		return nil, fmt.Errorf("epoch %v can't be in the future", epoch)
+	}
+	if err := s.initLayout(); err != nil {
+		return nil, err
+	}
+	if s.maxSequence < 0 {
+		s.maxSequence = s.sequenceMask
+	} else if s.maxSequence > s.sequenceMask {
+		return nil, fmt.Errorf("max sequence can't be greater than %d", s.sequenceMask)
+	}
+	if s.randomStart > s.maxSequence {
+		return nil, fmt.Errorf("random sequence start can't be greater than max sequence %d", s.maxSequence)
+	}
+	if s.stateStore != nil {
+		if err := s.loadState(); err != nil {
+			return nil, err
+		}
+	}
+	if register {
+		if err := s.register(); err != nil {
+			return nil, err
+		}
+	}
+	if s.driftWriter != nil {
+		s.startDriftMonitor()
+	}
+	if s.startupJitter > 0 {
+		s.sleep(time.Duration(rand.Int63n(int64(s.startupJitter) + 1)))
+	}
+
+	s.logStart()
+
+	return s, nil
 }
 
 func (s *Snowflake) NextId() (int64, error) {
+	if s.shutdown.Load() {
+		return 0, ErrShutdown
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.nextId(s.timeGen())
+}
+
+// SnapshotID 生成的id及生成时的系统时间
+type SnapshotID struct {
+	ID          int64
+	GeneratedAt time.Time // 生成id时的系统时间，序列用尽时可能早于id中的时间戳
+}
+
+// NextIdWithSnapshot 生成id并记录生成时的系统时间，用于审计
+func (s *Snowflake) NextIdWithSnapshot() (SnapshotID, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	timestamp := timeGen()
+	now := s.clock.Now()
+	id, err := s.nextId(s.timestampAt(now))
+	if err != nil {
+		return SnapshotID{}, err
+	}
+	return SnapshotID{ID: id, GeneratedAt: now}, nil
+}
+
+// NextIdIfBefore 当前时间早于deadline时生成id并返回true，否则返回false。
+// 时间检查与生成在同一次加锁内完成。生成失败时同样返回false。
+func (s *Snowflake) NextIdIfBefore(deadline time.Time) (int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.clock.Now()
+	if !now.Before(deadline) {
+		return 0, false
+	}
+	id, err := s.nextId(s.timestampAt(now))
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
+
+// NextIdContext 与 NextId 相同，但等待下一毫秒（序列用尽或 WithRollbackWait 等待时钟追上）时，
+// ctx结束会停止等待并返回 ctx.Err()，用于对延迟敏感的请求。等待其它调用释放锁的过程不能取消。
+func (s *Snowflake) NextIdContext(ctx context.Context) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if s.shutdown.Load() {
+		return 0, ErrShutdown
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.waitCtx = ctx
+	defer func() { s.waitCtx = nil }()
+	return s.nextId(s.timeGen())
+}
+
+// nextId 以timestamp作为当前时间戳生成id，调用方需持有锁
+func (s *Snowflake) nextId(timestamp int64) (int64, error) {
+	return s.nextIdWith(timestamp, s.generate)
+}
+
+// nextIdWith 在停止、熔断、限流等检查之后调用generate生成id，调用方需持有锁
+func (s *Snowflake) nextIdWith(timestamp int64, generate func(timestamp int64) (int64, error)) (int64, error) {
+	if s.shutdown.Load() {
+		return 0, ErrShutdown
+	}
+	if s.leaseLost.Load() {
+		return 0, ErrLeaseLost
+	}
+	if s.timeBoxExpired(timestamp) {
+		return 0, ErrTimeBoxExpired
+	}
+	if err := s.checkOverflow(timestamp); err != nil {
+		return 0, err
+	}
+	if s.breakerOpen(timestamp) {
+		return 0, ErrCircuitOpen
+	}
+	if !s.rateAllow(timestamp) {
+		return 0, ErrRateLimited
+	}
+	id, err := s.decorate(func() (int64, error) {
+		id, err := generate(timestamp)
+		if err == nil {
+			err = s.saveState()
+		}
+		s.breakerRecord(timestamp, err)
+		if err == nil {
+			s.rateRecord(timestamp)
+		}
+		return id, err
+	})
+	if err == nil {
+		s.lastId = id
+		s.sample(id)
+	}
+	return id, err
+}
 
-	// 当前时间戳小于上一次ID生成的时间戳，说明系统时钟回退过，这个时候应当抛出异常
-	if timestamp < s.lastTimestamp {
-		//log.Printf("clock is moving backwards.  Rejecting requests until %d.", s.lastTimestamp)
-		return 0, fmt.Errorf("Clock moved backwards.  Refusing to generate id for %d milliseconds", s.lastTimestamp - timestamp)
+func (s *Snowflake) generate(timestamp int64) (int64, error) {
+	timestamp, err := s.checkRollback(timestamp)
+	if err != nil {
+		return 0, err
 	}
 
 	// 如果是同一时间生成的，则进行毫秒内序列
 	if timestamp == s.lastTimestamp {
-		s.sequence = (s.sequence + 1) & sequenceMask
-		if s.sequence == 0 { // 序列用尽
-			timestamp = tilNextMillis(s.lastTimestamp)
+		sequence := s.randomSequence(s.sequence)
+		if sequence > s.maxSequence { // 序列用尽
+			// 等待被取消时不修改序列，避免之后在同一毫秒内重复使用序列
+			if timestamp, err = s.nextMillis(); err != nil {
+				return 0, err
+			}
+			sequence = s.randomSequence(-1)
 		}
+		s.sequence = sequence
 	} else {
-		s.sequence = 0
+		s.sequence = s.randomSequence(-1)
 	}
 
 	s.lastTimestamp = timestamp
-	return ((timestamp - twepoch) << timestampLeftShift) |
-		(s.datacenterId << datacenterIdShift) |
-		(s.workerId << workerIdShift) |
-		s.sequence, nil
+	return s.compose(timestamp, s.sequence), nil
+}
+
+// compose 按生成器的布局组合出timestamp毫秒、sequence序列的id
+func (s *Snowflake) compose(timestamp int64, sequence int64) int64 {
+	return ((timestamp - s.epoch) / s.layout.unit() << s.timestampShift) |
+		(s.datacenterId << s.datacenterShift) |
+		(s.workerId << s.workerShift) |
+		s.version |
+		sequence
 }
 
 // 获取当前时间戳(毫秒级)
@@ -90,11 +359,69 @@ func timeGen() int64 {
 	return time.Now().UnixNano() / 1e6
 }
 
-// 阻塞到下一个毫秒，直到获得新的时间戳
-func tilNextMillis(lastTimestamp int64) int64 {
-	timestamp := timeGen()
-	for timestamp <= lastTimestamp {
-		timestamp = timeGen()
+// 从生成器的时间源获取当前时间戳(毫秒级)，按 WithTimeUnit 的单位向下取整
+func (s *Snowflake) timeGen() int64 {
+	return s.timestampAt(s.clock.Now())
+}
+
+// timestampAt t对应的时间戳(毫秒级)，按 WithTimeUnit 的单位向下取整
+func (s *Snowflake) timestampAt(t time.Time) int64 {
+	timestamp := t.UnixNano() / 1e6
+	if unit := s.layout.unit(); unit > 1 {
+		timestamp -= (timestamp - s.epoch) % unit
 	}
 	return timestamp
 }
+
+// spinThreshold 距离下一毫秒不足这个时长时不再睡眠，改为自旋，避免睡眠唤醒的延迟
+const spinThreshold = 100 * time.Microsecond
+
+// 阻塞到下一个毫秒，直到获得新的时间戳。先睡眠到离下一毫秒 spinThreshold 之内，再自旋，
+// 避免序列用尽时占满一个CPU核
+func (s *Snowflake) tilNextMillis(lastTimestamp int64) (int64, error) {
+	for {
+		now := s.clock.Now()
+		if timestamp := s.timestampAt(now); timestamp > lastTimestamp {
+			return timestamp, nil
+		}
+		if s.waitCtx != nil {
+			if err := s.waitCtx.Err(); err != nil {
+				return 0, err
+			}
+		}
+		if d := time.UnixMilli(s.nextTick(lastTimestamp)).Sub(now); d > spinThreshold {
+			if err := s.wait(d - spinThreshold); err != nil {
+				return 0, err
+			}
+		} else {
+			s.sleep(0) // 自旋，time.Sleep(0) 立即返回；WithSleeper 替换的测试时钟可以借此推进
+		}
+	}
+}
+
+// nextTick 大于timestamp的第一个时间戳（按 WithTimeUnit 的单位对齐）
+func (s *Snowflake) nextTick(timestamp int64) int64 {
+	next := timestamp + 1
+	if unit := s.layout.unit(); unit > 1 {
+		if r := (next - s.epoch) % unit; r != 0 {
+			next += unit - r
+		}
+	}
+	return next
+}
+
+// wait 等待d，NextIdContext 的ctx结束时提前返回 ctx.Err()
+func (s *Snowflake) wait(d time.Duration) error {
+	if s.waitCtx == nil {
+		s.sleep(d)
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-s.waitCtx.Done():
+		return s.waitCtx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
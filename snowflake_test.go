@@ -1,7 +1,10 @@
 package snowflake
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 )
@@ -9,16 +12,347 @@ import (
 func TestSnowflake_NextId(t *testing.T) {
 	sf, err := New(int64(0), int64(0))
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
+	}
+	defer sf.Close()
+
+	prev := int64(0)
+	for i := 0; i < 100000; i++ {
+		id, err := sf.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id <= prev {
+			t.Fatalf("id %d isn't greater than %d", id, prev)
+		}
+		prev = id
+	}
+}
+
+func BenchmarkNextId(b *testing.B) {
+	sf, err := NewUnregistered(0, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := sf.NextId(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkNextId_Contention n个goroutine共用一个生成器，衡量锁竞争的开销
+func BenchmarkNextId_Contention(b *testing.B) {
+	for _, n := range []int{1, 4, 16, 64} {
+		b.Run(fmt.Sprintf("goroutines=%d", n), func(b *testing.B) {
+			sf, err := NewUnregistered(0, 0)
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			var wg sync.WaitGroup
+			for g := 0; g < n; g++ {
+				count := b.N / n
+				if g < b.N%n {
+					count++
+				}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := 0; i < count; i++ {
+						if _, err := sf.NextId(); err != nil {
+							b.Error(err)
+							return
+						}
+					}
+				}()
+			}
+			wg.Wait()
+		})
+	}
+}
+
+// BenchmarkNextIds 批量生成，ns/id 为平均每个id的耗时
+func BenchmarkNextIds(b *testing.B) {
+	for _, n := range []int{16, 256, 4096} {
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			sf, err := NewUnregistered(0, 0)
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := sf.NextIds(n); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*n), "ns/id")
+		})
+	}
+}
+
+// BenchmarkNextId_SequenceExhausted 每次生成都用尽毫秒内序列，衡量切换到下一毫秒的开销。
+// 睡眠时直接推进时钟，不包含真实的等待时间
+func BenchmarkNextId_SequenceExhausted(b *testing.B) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sleeper := func(d time.Duration) { clock.Add(time.Millisecond) }
+	sf, err := NewUnregistered(0, 0, WithClock(clock), WithMaxSequence(0), WithSleeper(sleeper))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := sf.NextId(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNextId_Allocs(b *testing.B) {
+	sf, err := New(int64(0), int64(0))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer sf.Close()
+	if allocs := testing.AllocsPerRun(1000, func() {
+		if _, err := sf.NextId(); err != nil {
+			b.Fatal(err)
+		}
+	}); allocs > 0 {
+		b.Errorf("NextId allocates %v times per call, want 0", allocs)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := sf.NextId(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNextId_WithError(b *testing.B) {
+	sf, err := New(int64(0), int64(0))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer sf.Close()
+	// 上一次生成时间设置在未来，模拟时钟回退
+	sf.lastTimestamp = timeGen() + int64(time.Hour/time.Millisecond)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := sf.NextId(); err == nil {
+			b.Fatal("expected clock moved backwards error")
+		}
+	}
+}
+
+func TestSnowflake_NextIdWithSnapshot(t *testing.T) {
+	sf, err := NewUnregistered(int64(0), int64(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+	snap, err := sf.NextIdWithSnapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := snap.GeneratedAt.Sub(ID(snap.ID).Time()); d < 0 || d >= time.Millisecond {
+		t.Errorf("GeneratedAt %v should be within the id millisecond %v", snap.GeneratedAt, ID(snap.ID).Time())
+	}
+
+	// 序列用尽时id的时间戳会推进到下一毫秒，此时与GeneratedAt不同
+	for i := 0; i < 100; i++ {
+		sf.mu.Lock()
+		sf.lastTimestamp = timeGen()
+		sf.sequence = sf.maxSequence
+		last := sf.lastTimestamp
+		sf.mu.Unlock()
+
+		snap, err := sf.NextIdWithSnapshot()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if snap.GeneratedAt.UnixMilli() != last {
+			continue // 已经跨过了一毫秒，重试
+		}
+		if !ID(snap.ID).Time().After(snap.GeneratedAt) {
+			t.Errorf("id time %v should be after GeneratedAt %v", ID(snap.ID).Time(), snap.GeneratedAt)
+		}
+		return
 	}
+	t.Fatal("could not exhaust the sequence within a single millisecond")
+}
 
-	startTime := time.Now().UnixNano()
-	maxCount := 10000000
-	for i:=0; i<maxCount; i++ {
-		_, err := sf.NextId()
+func TestSnowflake_NextIdIfBefore(t *testing.T) {
+	deadline := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock := newFakeClock(deadline.Add(-time.Second))
+	sf, err := NewUnregistered(int64(0), int64(0), WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := sf.NextIdIfBefore(deadline); !ok {
+		t.Error("one second before deadline: want ok")
+	}
+	clock.Add(time.Second - time.Millisecond)
+	id, ok := sf.NextIdIfBefore(deadline)
+	if !ok {
+		t.Fatal("one millisecond before deadline: want ok")
+	}
+	if got := ID(id).Time(); !got.Before(deadline) {
+		t.Errorf("id time %v should be before deadline %v", got, deadline)
+	}
+	clock.Add(time.Millisecond)
+	if _, ok := sf.NextIdIfBefore(deadline); ok {
+		t.Error("at deadline: want not ok")
+	}
+	clock.Add(time.Second)
+	if _, ok := sf.NextIdIfBefore(deadline); ok {
+		t.Error("after deadline: want not ok")
+	}
+}
+
+// 时间单位大于1毫秒时，同一单位内不同毫秒调用各个生成方法不能重复
+func TestSnowflake_TimeUnitMixedMethods(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, err := NewUnregistered(1, 1, WithClock(clock), WithTimeUnit(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[int64]bool)
+	for i := 0; i < 30; i++ {
+		var id int64
+		switch i % 3 {
+		case 0:
+			id, err = sf.NextId()
+		case 1:
+			var snap SnapshotID
+			snap, err = sf.NextIdWithSnapshot()
+			id = snap.ID
+		case 2:
+			var ok bool
+			if id, ok = sf.NextIdIfBefore(clock.Now().Add(time.Hour)); !ok {
+				t.Fatal("NextIdIfBefore: want ok")
+			}
+		}
 		if err != nil {
-			t.Error(err)
+			t.Fatal(err)
+		}
+		if seen[id] {
+			t.Fatalf("duplicate id %d at step %d", id, i)
+		}
+		seen[id] = true
+		clock.Add(time.Millisecond)
+	}
+}
+
+func TestExportedLimits(t *testing.T) {
+	if MaxWorkerID != 31 || MaxDatacenterID != 31 || MaxNodeID != 1023 {
+		t.Errorf("limits = %d %d %d", MaxWorkerID, MaxDatacenterID, MaxNodeID)
+	}
+	if _, err := NewUnregistered(MaxWorkerID, MaxDatacenterID); err != nil {
+		t.Error(err)
+	}
+	if _, err := NewUnregistered(MaxWorkerID+1, 0); err == nil {
+		t.Error("worker id above MaxWorkerID expected error")
+	}
+	if MaxNodeID != MaxDatacenterID<<workerIdBits|MaxWorkerID {
+		t.Error("MaxNodeID doesn't match the combined node")
+	}
+}
+
+func TestCapacityConstants(t *testing.T) {
+	if MaxWorkersPerDatacenter != 32 || MaxDatacenters != 32 || MaxConcurrentGenerators != 1024 {
+		t.Errorf("capacity = %d %d %d", MaxWorkersPerDatacenter, MaxDatacenters, MaxConcurrentGenerators)
+	}
+	if MaxConcurrentGenerators != MaxNodeID+1 {
+		t.Error("MaxConcurrentGenerators doesn't match MaxNodeID")
+	}
+}
+
+func TestNextIdContext(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, err := NewUnregistered(1, 2, WithClock(clock), WithMaxSequence(1), WithCircuitBreaker(1, time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	first, err := sf.NextIdContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sf.NextIdContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// 序列用尽，时钟不前进时等待到超时
+	timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := sf.NextIdContext(timeout); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want DeadlineExceeded", err)
+	}
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := sf.NextIdContext(canceled); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want Canceled", err)
+	}
+
+	// 超时不改变序列，也不触发熔断
+	clock.Add(time.Millisecond)
+	id, err := sf.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := ID(id).Parse(); p.Sequence() != 0 || p.Timestamp() != ID(first).Parse().Timestamp()+1 {
+		t.Errorf("id after timeout parsed as %+v", p)
+	}
+}
+
+func TestTilNextMillis_Sleeps(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	var slept []time.Duration
+	sleeper := func(d time.Duration) {
+		slept = append(slept, d)
+		clock.Add(time.Millisecond)
+	}
+	sf, err := NewUnregistered(1, 2, WithClock(clock), WithMaxSequence(0), WithSleeper(sleeper))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sf.NextId()
+	id, err := sf.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts := ID(id).Parse().Timestamp(); ts != twepoch+1001 {
+		t.Errorf("timestamp = %d, want %d", ts, twepoch+1001)
+	}
+	// 睡眠到下一毫秒前 spinThreshold，而不是自旋
+	if len(slept) != 1 || slept[0] != time.Millisecond-spinThreshold {
+		t.Errorf("slept %v, want [%v]", slept, time.Millisecond-spinThreshold)
+	}
+}
+
+func TestNextTick(t *testing.T) {
+	sf, err := NewUnregistered(1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sf.nextTick(twepoch + 5); got != twepoch+6 {
+		t.Errorf("nextTick = %d, want %d", got, twepoch+6)
+	}
+	sf.layout.TimeUnit = 10 * time.Millisecond
+	for ts, want := range map[int64]int64{twepoch: twepoch + 10, twepoch + 9: twepoch + 10, twepoch + 10: twepoch + 20} {
+		if got := sf.nextTick(ts); got != want {
+			t.Errorf("nextTick(%d) = %d, want %d", ts, got, want)
 		}
 	}
-	fmt.Printf("generate id count = %v cost  %v s", maxCount, (time.Now().UnixNano() - startTime) / 1e9)
-}
\ No newline at end of file
+}
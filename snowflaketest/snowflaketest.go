@@ -0,0 +1,138 @@
+// Package snowflaketest 提供在测试中检查雪花id的断言函数，失败时调用 t.Fatalf；
+// 以及可以手动控制的 FakeClock，用于确定性地模拟毫秒切换、序列用尽和时钟回退
+package snowflaketest
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pangush/snowflake"
+)
+
+// AssertIDsMonotone 检查ids严格递增
+func AssertIDsMonotone(t testing.TB, ids []int64) {
+	t.Helper()
+	for i := 1; i < len(ids); i++ {
+		if ids[i] <= ids[i-1] {
+			t.Fatalf("snowflake ids are not monotone: ids[%d] = %d, ids[%d] = %d", i-1, ids[i-1], i, ids[i])
+		}
+	}
+}
+
+// AssertIDsUnique 检查ids中没有重复
+func AssertIDsUnique(t testing.TB, ids []int64) {
+	t.Helper()
+	seen := make(map[int64]int, len(ids))
+	for i, id := range ids {
+		if j, ok := seen[id]; ok {
+			t.Fatalf("duplicate snowflake id %d at index %d and %d", id, j, i)
+		}
+		seen[id] = i
+	}
+}
+
+// AssertIDInTimeRange 按epoch解析id，检查生成时间在[start, end]内
+func AssertIDInTimeRange(t testing.TB, id int64, start, end time.Time, epoch time.Time) {
+	t.Helper()
+	at := snowflake.ParseWithEpoch(id, epoch).Time()
+	if at.Before(start.Truncate(time.Millisecond)) || at.After(end) {
+		t.Fatalf("snowflake id %d was generated at %v, want between %v and %v", id, at, start, end)
+	}
+}
+
+// AssertIDWorker 按epoch解析id，检查机器id
+func AssertIDWorker(t testing.TB, id int64, expectedWorker int64, epoch time.Time) {
+	t.Helper()
+	if w := snowflake.ParseWithEpoch(id, epoch).WorkerId(); w != expectedWorker {
+		t.Fatalf("snowflake id %d has worker id %d, want %d", id, w, expectedWorker)
+	}
+}
+
+// FakeClock 手动控制的时钟，实现 snowflake.Clock。
+// 并发安全，可以在其它goroutine中推进
+type FakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+// NewFakeClock 创建停在t的时钟
+func NewFakeClock(t time.Time) *FakeClock {
+	return &FakeClock{t: t}
+}
+
+// Now 实现 snowflake.Clock
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+// Set 把时钟设置为t，t可以早于当前时间
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = t
+}
+
+// Add 推进时钟d，d为负数时回退
+func (c *FakeClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+// Rollback 回退时钟d，模拟NTP校时等造成的时钟回退
+func (c *FakeClock) Rollback(d time.Duration) {
+	c.Add(-d)
+}
+
+// Sleep 代替 time.Sleep，不真正等待，把时钟推进d之后对齐到下一个毫秒边界。
+// 生成器序列用尽或等待时钟追上时会睡眠到下一毫秒附近，对齐后正好跨过毫秒边界
+func (c *FakeClock) Sleep(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := c.t.Add(d)
+	if r := t.Sub(t.Truncate(time.Millisecond)); r != 0 {
+		t = t.Add(time.Millisecond - r)
+	}
+	c.t = t
+}
+
+// New 创建使用clock计时、通过 FakeClock.Sleep 等待的生成器，不登记到进程内的节点注册表。
+// 生成器的等待都会变为推进clock，测试不依赖真实时间
+func New(t testing.TB, clock *FakeClock, workerId, datacenterId int64, opts ...snowflake.Option) *snowflake.Snowflake {
+	t.Helper()
+	opts = append([]snowflake.Option{snowflake.WithClock(clock), snowflake.WithSleeper(clock.Sleep)}, opts...)
+	s, err := snowflake.NewUnregistered(workerId, datacenterId, opts...)
+	if err != nil {
+		t.Fatalf("snowflake: %v", err)
+	}
+	return s
+}
+
+// FillMillisecond 在当前毫秒（时间单位）内生成id，直到序列用尽，返回生成的id。
+// 之后再生成id会触发序列用尽，等待下一毫秒
+func FillMillisecond(t testing.TB, s *snowflake.Snowflake) []int64 {
+	t.Helper()
+	first, err := s.NextId()
+	if err != nil {
+		t.Fatalf("snowflake: %v", err)
+	}
+	ts := s.Decompose(first).Timestamp()
+	ids := []int64{first}
+	for {
+		next, err := s.Peek()
+		if err != nil {
+			t.Fatalf("snowflake: %v", err)
+		}
+		if s.Decompose(next).Timestamp() != ts {
+			return ids
+		}
+		id, err := s.NextId()
+		if err != nil {
+			t.Fatalf("snowflake: %v", err)
+		}
+		ids = append(ids, id)
+	}
+}
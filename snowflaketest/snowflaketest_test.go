@@ -0,0 +1,169 @@
+package snowflaketest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pangush/snowflake"
+)
+
+// recorder 记录 Fatalf 的调用，不终止测试
+type recorder struct {
+	testing.TB
+	failed string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.failed = fmt.Sprintf(format, args...)
+}
+
+func TestAssertions(t *testing.T) {
+	epoch, _ := time.Parse("2006-01-02 15:04:05 -0700", "2020-01-01 00:00:00 +0800")
+	start := time.Now()
+	sf, err := snowflake.NewUnregistered(7, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]int64, 1000)
+	for i := range ids {
+		if ids[i], err = sf.NextId(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	end := time.Now()
+
+	// 通过的情况
+	AssertIDsMonotone(t, ids)
+	AssertIDsUnique(t, ids)
+	AssertIDInTimeRange(t, ids[0], start, end, epoch)
+	AssertIDWorker(t, ids[0], 7, epoch)
+
+	cases := []struct {
+		name string
+		fn   func(testing.TB)
+		want string
+	}{
+		{"monotone", func(tb testing.TB) { AssertIDsMonotone(tb, []int64{1, 3, 2}) }, "not monotone"},
+		{"unique", func(tb testing.TB) { AssertIDsUnique(tb, []int64{1, 2, 1}) }, "duplicate snowflake id 1 at index 0 and 2"},
+		{"time range", func(tb testing.TB) { AssertIDInTimeRange(tb, ids[0], end.Add(time.Hour), end.Add(2*time.Hour), epoch) }, "was generated at"},
+		{"worker", func(tb testing.TB) { AssertIDWorker(tb, ids[0], 8, epoch) }, "has worker id 7, want 8"},
+	}
+	for _, c := range cases {
+		r := &recorder{TB: t}
+		c.fn(r)
+		if !strings.Contains(r.failed, c.want) {
+			t.Errorf("%s: Fatalf(%q), want message containing %q", c.name, r.failed, c.want)
+		}
+	}
+}
+
+func TestFakeClock_Sleep(t *testing.T) {
+	at := time.UnixMilli(1700000000000)
+	c := NewFakeClock(at)
+	c.Sleep(900 * time.Microsecond)
+	if got := c.Now(); !got.Equal(at.Add(time.Millisecond)) {
+		t.Errorf("Sleep(900µs) moved clock to %v, want %v", got, at.Add(time.Millisecond))
+	}
+	c.Sleep(0)
+	if got := c.Now(); !got.Equal(at.Add(time.Millisecond)) {
+		t.Errorf("Sleep(0) on a boundary moved clock to %v", got)
+	}
+	c.Rollback(5 * time.Millisecond)
+	if got := c.Now(); !got.Equal(at.Add(-4 * time.Millisecond)) {
+		t.Errorf("Rollback moved clock to %v", got)
+	}
+}
+
+func TestSequenceExhaustion(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	sf := New(t, clock, 1, 2)
+	ids := FillMillisecond(t, sf)
+	if len(ids) != 4096 {
+		t.Fatalf("filled %d ids in one millisecond, want 4096", len(ids))
+	}
+	ts := sf.Decompose(ids[0]).Timestamp()
+
+	// 序列用尽后推进到下一毫秒，序列从0开始
+	id, err := sf.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := sf.Decompose(id); p.Timestamp() != ts+1 || p.Sequence() != 0 {
+		t.Errorf("after exhaustion: timestamp %d sequence %d, want %d 0", p.Timestamp(), p.Sequence(), ts+1)
+	}
+	if got := clock.Now().UnixMilli(); got != ts+1 {
+		t.Errorf("clock at %d after exhaustion, want %d", got, ts+1)
+	}
+	AssertIDsMonotone(t, append(ids, id))
+}
+
+func TestClockRollback(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	sf := New(t, clock, 1, 2)
+	prev, _ := sf.NextId()
+	clock.Rollback(3 * time.Millisecond)
+	_, err := sf.NextId()
+	var rollback *snowflake.ErrClockMovedBackwards
+	if !errors.As(err, &rollback) || rollback.Duration != 3*time.Millisecond {
+		t.Fatalf("NextId after rollback = %v", err)
+	}
+
+	// WithRollbackWait 等待时钟追上，FakeClock 直接推进
+	waiting := New(t, clock, 1, 2, snowflake.WithRollbackWait(10*time.Millisecond))
+	prev, _ = waiting.NextId()
+	clock.Rollback(5 * time.Millisecond)
+	id, err := waiting.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id <= prev {
+		t.Errorf("id %d isn't greater than %d", id, prev)
+	}
+}
+
+// FuzzUniqueness 按输入的字节序列交替生成id、推进和回退时钟，检查生成的id不重复且严格递增。
+// 每个字节的低2位为操作，其余位为参数
+func FuzzUniqueness(f *testing.F) {
+	f.Add([]byte{0, 0, 0, 6, 0, 0, 3, 0, 0, 0})
+	f.Add([]byte{0, 1, 0, 1, 0, 255, 0, 0, 0, 2, 0, 0, 1})
+	f.Add([]byte{1, 0, 7, 0, 0, 0, 0, 0, 0, 0, 0, 0, 11, 0, 0})
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		if len(ops) == 0 {
+			return
+		}
+		// 第一个字节选择回退时的处理方式
+		opts := []snowflake.Option{snowflake.WithMaxSequence(3), snowflake.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))}
+		switch ops[0] % 3 {
+		case 1:
+			opts = append(opts, snowflake.WithLogicalClock())
+		case 2:
+			opts = append(opts, snowflake.WithRollbackWait(20*time.Millisecond))
+		}
+		clock := NewFakeClock(time.UnixMilli(1700000000000))
+		sf := New(t, clock, 1, 2, opts...)
+
+		var ids []int64
+		for _, op := range ops[1:] {
+			arg := time.Duration(op >> 2)
+			switch op & 3 {
+			case 0, 1:
+				if id, err := sf.NextId(); err == nil {
+					ids = append(ids, id)
+				}
+			case 2:
+				clock.Add(arg * 100 * time.Microsecond)
+			case 3:
+				clock.Rollback(arg * time.Millisecond)
+			}
+		}
+		AssertIDsUnique(t, ids)
+		AssertIDsMonotone(t, ids)
+	})
+}
@@ -0,0 +1,36 @@
+package snowflake
+
+import (
+	"fmt"
+	"time"
+)
+
+// Sonyflake的位分布：39位时间(10毫秒为单位) | 8位序列 | 16位机器id
+const (
+	sonyTimeUnit     = 10 // 毫秒
+	sonySequenceBits = 8
+	sonyMachineBits  = 16
+	sonyTimeBits     = 39
+)
+
+// ConvertSonyflakeToSnowflake 将Sonyflake的id转换为sf的格式，时间戳保持不变（精度为10毫秒），
+// 节点使用sf的数据id和机器id，Sonyflake的序列作为毫秒内序列。
+// Sonyflake的机器id不会保留，只有来自同一台Sonyflake机器的id转换后才能保证不重复，
+// 多台机器的id应分别转换到不同节点的生成器。
+func ConvertSonyflakeToSnowflake(sonyID uint64, sonyEpoch time.Time, sf *Snowflake) (int64, error) {
+	if sonyID>>(sonyTimeBits+sonySequenceBits+sonyMachineBits) != 0 {
+		return 0, fmt.Errorf("invalid sonyflake id %d", sonyID)
+	}
+	elapsed := int64(sonyID >> (sonySequenceBits + sonyMachineBits))
+	sequence := int64(sonyID>>sonyMachineBits) & (1<<sonySequenceBits - 1)
+
+	timestamp := sonyEpoch.UnixMilli() + elapsed*sonyTimeUnit - sf.epoch
+	if timestamp < 0 || timestamp/sf.layout.unit() > sf.timestampMax {
+		return 0, fmt.Errorf("sonyflake id %d time %v is out of range for epoch %v",
+			sonyID, time.UnixMilli(timestamp+sf.epoch), time.UnixMilli(sf.epoch))
+	}
+	if sequence > sf.sequenceMask {
+		return 0, fmt.Errorf("sonyflake sequence %d doesn't fit in %d sequence bits", sequence, int(sf.layout.SequenceBits-sf.versionBits))
+	}
+	return sf.compose(timestamp+sf.epoch, sequence), nil
+}
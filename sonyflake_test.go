@@ -0,0 +1,53 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConvertSonyflakeToSnowflake(t *testing.T) {
+	sonyEpoch := time.Date(2014, 9, 1, 0, 0, 0, 0, time.UTC)
+	sony := func(at time.Time, seq, machine uint64) uint64 {
+		elapsed := uint64(at.Sub(sonyEpoch) / (10 * time.Millisecond))
+		return elapsed<<24 | seq<<16 | machine
+	}
+	sf, err := NewUnregistered(7, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	at := time.Date(2023, 5, 6, 7, 8, 9, 120e6, time.UTC)
+	var last int64
+	for seq := uint64(0); seq < 256; seq++ {
+		id, err := ConvertSonyflakeToSnowflake(sony(at, seq, 0xbeef), sonyEpoch, sf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ID(id).Time(); !got.Equal(at) {
+			t.Fatalf("converted time = %v, want %v", got, at)
+		}
+		p := ID(id).Parse()
+		if p.WorkerId() != 7 || p.DatacenterId() != 8 || p.Sequence() != int64(seq) {
+			t.Fatalf("converted id parsed as %+v", p)
+		}
+		if id <= last {
+			t.Fatal("converted ids are not increasing")
+		}
+		last = id
+	}
+
+	// 时间早于目标起始时间
+	if _, err := ConvertSonyflakeToSnowflake(sony(time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), 0, 1), sonyEpoch, sf); err == nil {
+		t.Error("id before epoch expected error")
+	}
+	if _, err := ConvertSonyflakeToSnowflake(1<<63, sonyEpoch, sf); err == nil {
+		t.Error("64-bit id expected error")
+	}
+	small, err := NewUnregistered(7, 8, WithVersionBits(6, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ConvertSonyflakeToSnowflake(sony(at, 200, 1), sonyEpoch, small); err == nil {
+		t.Error("sequence overflow expected error")
+	}
+}
@@ -0,0 +1,114 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StateStore 持久化生成器已经使用到的时间戳，用于在重启后避免时钟回退导致的重复id
+type StateStore interface {
+	// Load 读取保存的时间戳(unix时间戳/毫秒)，没有保存过时返回0
+	Load() (int64, error)
+	// Save 保存时间戳，返回前需要确保已经落盘
+	Save(timestamp int64) error
+}
+
+// WithStateStore 持久化已经使用到的时间戳，重启后不会生成时间戳早于重启前的id，
+// 即使停机期间系统时钟回退。生成器每次保存当前时间戳之后interval的时间戳，
+// 在生成的id追上保存的时间戳之前不需要再次写入，因此interval越大写入越少，
+// 但重启得越快，重启后的id中的时间戳越可能超前于当前时间（最多interval），
+// 系统时间追上之前生成器沿用保存的时间戳，与 NextIdAfter 推进后的行为相同。
+// 保存失败时 NextId 返回错误，不会生成未持久化的id。使用后不支持 ForkSequence。
+func WithStateStore(store StateStore, interval time.Duration) Option {
+	return func(s *Snowflake) error {
+		if store == nil {
+			return fmt.Errorf("state store can't be nil")
+		}
+		if interval <= 0 {
+			return fmt.Errorf("state save interval must be positive")
+		}
+		s.stateStore = store
+		s.stateInterval = interval.Milliseconds()
+		return nil
+	}
+}
+
+// loadState 创建生成器时从 StateStore 恢复时间戳
+func (s *Snowflake) loadState() error {
+	saved, err := s.stateStore.Load()
+	if err != nil {
+		return fmt.Errorf("load snowflake state: %w", err)
+	}
+	if saved > 0 {
+		// 重启前生成的id的时间戳都小于saved，从saved所在或之后的第一个时间单位开始生成
+		s.lastTimestamp = s.nextTick(saved - 1)
+		s.advancedUntil = s.lastTimestamp
+		s.sequence = -1
+	}
+	s.stateSaved = saved
+	return nil
+}
+
+// saveState 生成的id追上保存的时间戳时，保存之后interval的时间戳，调用方需持有锁
+func (s *Snowflake) saveState() error {
+	if s.stateStore == nil || s.lastTimestamp < s.stateSaved {
+		return nil
+	}
+	next := s.lastTimestamp + s.stateInterval
+	if err := s.stateStore.Save(next); err != nil {
+		return fmt.Errorf("save snowflake state: %w", err)
+	}
+	s.stateSaved = next
+	return nil
+}
+
+// fileStateStore 把时间戳以十进制保存在文件中
+type fileStateStore struct {
+	path string
+}
+
+// NewFileStateStore 把时间戳保存在path文件中，写入临时文件并同步后再重命名，进程崩溃时不会留下不完整的文件
+func NewFileStateStore(path string) StateStore {
+	return fileStateStore{path: path}
+}
+
+func (f fileStateStore) Load() (int64, error) {
+	b, err := os.ReadFile(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid state file %s: %w", f.path, err)
+	}
+	return n, nil
+}
+
+func (f fileStateStore) Save(timestamp int64) error {
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(strconv.FormatInt(timestamp, 10) + "\n"); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
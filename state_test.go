@@ -0,0 +1,104 @@
+package snowflake
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// memStateStore 内存中的 StateStore
+type memStateStore struct {
+	saved []int64
+	err   error
+}
+
+func (m *memStateStore) Load() (int64, error) {
+	if len(m.saved) == 0 {
+		return 0, nil
+	}
+	return m.saved[len(m.saved)-1], nil
+}
+
+func (m *memStateStore) Save(timestamp int64) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.saved = append(m.saved, timestamp)
+	return nil
+}
+
+func TestWithStateStore(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	store := &memStateStore{}
+	sf, err := NewUnregistered(1, 2, WithClock(clock), WithStateStore(store, time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last int64
+	for i := 0; i < 3; i++ {
+		if last, err = sf.NextId(); err != nil {
+			t.Fatal(err)
+		}
+		clock.Add(400 * time.Millisecond)
+	}
+	// 第3个id在1800毫秒，还没有追上保存的2000毫秒
+	if len(store.saved) != 1 || store.saved[0] != twepoch+2000 {
+		t.Errorf("saved = %v, want [%d]", store.saved, twepoch+2000)
+	}
+	clock.Add(time.Second)
+	if _, err := sf.NextId(); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.saved) != 2 || store.saved[1] != twepoch+4200 {
+		t.Errorf("saved = %v", store.saved)
+	}
+
+	// 重启时时钟回退了，从保存的时间戳开始生成
+	clock.Add(-time.Minute)
+	restarted, err := NewUnregistered(1, 2, WithClock(clock), WithStateStore(store, time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := restarted.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id <= last || ID(id).Parse().Timestamp() != twepoch+4200 {
+		t.Errorf("id after restart %d parsed as %+v", id, ID(id).Parse())
+	}
+
+	// 保存失败时不返回id
+	store.err = errors.New("disk full")
+	clock.Add(2 * time.Minute)
+	if _, err := restarted.NextId(); !errors.Is(err, store.err) {
+		t.Errorf("err = %v, want disk full", err)
+	}
+	if _, err := restarted.ForkSequence(2); err == nil {
+		t.Error("ForkSequence with a state store should fail")
+	}
+}
+
+func TestFileStateStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snowflake.state")
+	store := NewFileStateStore(path)
+	if n, err := store.Load(); err != nil || n != 0 {
+		t.Fatalf("Load of missing file = %d, %v", n, err)
+	}
+	if err := store.Save(twepoch + 1234); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := store.Load(); err != nil || n != twepoch+1234 {
+		t.Errorf("Load = %d, %v", n, err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("temporary files left: %v", entries)
+	}
+
+	os.WriteFile(path, []byte("garbage"), 0o644)
+	if _, err := NewUnregistered(1, 2, WithStateStore(store, time.Second)); err == nil {
+		t.Error("invalid state file should fail")
+	}
+}
@@ -0,0 +1,29 @@
+package snowflake
+
+import "time"
+
+// Stats 生成器的运行统计
+type Stats struct {
+	Generated      int64         // 生成的id总数
+	SequenceWaits  int64         // 毫秒内序列用尽、需要使用下一毫秒的次数，持续增长说明吞吐已接近上限
+	ClockRollbacks int64         // 检测到时钟回退的次数，包括被 WithRollbackWait、WithLogicalClock 容忍的回退
+	SequenceCount  int64         // 最近一次生成id的毫秒内已经使用的序列个数，见 SequenceCount
+	MaxSequence    int64         // 毫秒内序列的最大值
+	Borrowed       int64         // WithBorrowFuture 借用下一毫秒时间戳的次数
+	Lead           time.Duration // 上一次生成id的时间戳领先系统时间多久，借用或时钟回退时大于0
+}
+
+// Stats 返回生成器的运行统计，可以并发调用。prometheus 子包将其导出为Prometheus指标
+func (s *Snowflake) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return Stats{
+		Generated:      s.generated,
+		SequenceWaits:  s.sequenceWaits,
+		ClockRollbacks: s.clockRollbacks,
+		SequenceCount:  s.sequenceCount(),
+		MaxSequence:    s.maxSequence,
+		Borrowed:       s.borrowed,
+		Lead:           s.lead(),
+	}
+}
@@ -0,0 +1,41 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSnowflake_Stats(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, err := NewUnregistered(1, 2, WithClock(clock), WithMaxSequence(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st := sf.Stats(); st != (Stats{MaxSequence: 3}) {
+		t.Errorf("initial stats = %+v", st)
+	}
+
+	// 4个序列用尽后的第5个id需要等待下一毫秒
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		clock.Add(time.Millisecond)
+	}()
+	for i := 0; i < 5; i++ {
+		if _, err := sf.NextId(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, err := sf.NextIdN(2); err != nil {
+		t.Fatal(err)
+	}
+	clock.Add(-5 * time.Millisecond)
+	if _, err := sf.NextId(); err == nil {
+		t.Fatal("expected clock rollback error")
+	}
+
+	// 回退后上一次的时间戳领先系统时间5毫秒
+	want := Stats{Generated: 7, SequenceWaits: 1, ClockRollbacks: 1, SequenceCount: 3, MaxSequence: 3, Lead: 5 * time.Millisecond}
+	if st := sf.Stats(); st != want {
+		t.Errorf("stats = %+v, want %+v", st, want)
+	}
+}
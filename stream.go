@@ -0,0 +1,51 @@
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// streamRetry Stream 生成失败后重试的间隔
+const streamRetry = time.Millisecond
+
+// Stream 在后台goroutine中预先生成id写入容量为buffer的channel，消费方直接从channel读取，
+// 热路径上不需要获取生成器的锁，适合Kafka生产者、批量写入等流水线。
+// 时钟回退、熔断、限流等暂时性的错误会在等待后重试；ctx结束，或者生成器已经无法再生成id
+// （Close、租约丢失、时间盒过期、时间戳用尽）时关闭channel。
+// 缓冲中的id在生成时就已确定，读取时id中的时间戳可能早于当前时间。
+func (s *Snowflake) Stream(ctx context.Context, buffer int) <-chan int64 {
+	if buffer < 0 {
+		buffer = 0
+	}
+	ch := make(chan int64, buffer)
+	go func() {
+		defer close(ch)
+		for {
+			id, err := s.NextIdContext(ctx)
+			if err != nil {
+				if ctx.Err() != nil || streamStopped(err) {
+					return
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(streamRetry):
+				}
+				continue
+			}
+			select {
+			case ch <- id:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// streamStopped err表示生成器不能再生成id
+func streamStopped(err error) bool {
+	return errors.Is(err, ErrShutdown) || errors.Is(err, ErrLeaseLost) ||
+		errors.Is(err, ErrTimeBoxExpired) || errors.Is(err, ErrTimestampOverflow)
+}
@@ -0,0 +1,73 @@
+package snowflake
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStream(t *testing.T) {
+	sf, _ := NewUnregistered(1, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := sf.Stream(ctx, 16)
+
+	prev := int64(0)
+	for i := 0; i < 1000; i++ {
+		id := <-ch
+		if id <= prev {
+			t.Fatalf("id %d isn't greater than %d", id, prev)
+		}
+		prev = id
+	}
+	cancel()
+	expectClosed(t, ch)
+}
+
+func TestStream_Close(t *testing.T) {
+	sf, _ := NewUnregistered(1, 2)
+	ch := sf.Stream(context.Background(), 0)
+	<-ch
+	sf.Close()
+	expectClosed(t, ch)
+}
+
+func TestStream_Rollback(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, _ := NewUnregistered(1, 2, WithClock(clock))
+	first, _ := sf.NextId()
+	clock.Add(-5 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := sf.Stream(ctx, 0)
+	select {
+	case id := <-ch:
+		t.Fatalf("got id %d while the clock is behind", id)
+	case <-time.After(10 * time.Millisecond):
+	}
+	// 时钟追上之后继续生成
+	clock.Add(10 * time.Millisecond)
+	select {
+	case id := <-ch:
+		if id <= first {
+			t.Errorf("id %d isn't greater than %d", id, first)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("stream didn't recover after the clock caught up")
+	}
+}
+
+func expectClosed(t *testing.T, ch <-chan int64) {
+	t.Helper()
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("stream wasn't closed")
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package snowflake
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewFromTag 按标签字符串创建生成器，格式如 "worker:3,datacenter:2,epoch:2021-01-01"，
+// 便于把生成器配置写在结构体标签中：
+//
+//	gen *snowflake.Snowflake `snowflake:"worker:3,datacenter:2"`
+//
+// 调用方自行通过 reflect.StructTag.Get 取出标签值后传入，本函数只做解析。
+// worker和datacenter默认为0，epoch格式为2006-01-02，未指定时使用默认起始时间。
+func NewFromTag(tag string) (*Snowflake, error) {
+	var workerId, datacenterId int64
+	var opts []Option
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(tag, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(field, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid snowflake tag field %q", field)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate snowflake tag key %q", key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "worker":
+			workerId, err = strconv.ParseInt(value, 10, 64)
+		case "datacenter":
+			datacenterId, err = strconv.ParseInt(value, 10, 64)
+		case "epoch":
+			var epoch time.Time
+			epoch, err = time.Parse("2006-01-02", value)
+			opts = append(opts, WithEpoch(epoch))
+		default:
+			return nil, fmt.Errorf("unknown snowflake tag key %q", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid snowflake tag %s: %w", key, err)
+		}
+	}
+	return New(workerId, datacenterId, opts...)
+}
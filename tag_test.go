@@ -0,0 +1,48 @@
+package snowflake
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewFromTag(t *testing.T) {
+	type service struct {
+		gen *Snowflake `snowflake:"worker:3,datacenter:2,epoch:2021-01-01"`
+	}
+	field, _ := reflect.TypeOf(service{}).FieldByName("gen")
+	sf, err := NewFromTag(field.Tag.Get("snowflake"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+	if sf.workerId != 3 || sf.datacenterId != 2 {
+		t.Errorf("worker %d datacenter %d, want 3 2", sf.workerId, sf.datacenterId)
+	}
+	if want := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli(); sf.epoch != want {
+		t.Errorf("epoch = %d, want %d", sf.epoch, want)
+	}
+
+	def, err := NewFromTag(" worker: 4 ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer def.Close()
+	if def.workerId != 4 || def.datacenterId != 0 || def.epoch != twepoch {
+		t.Errorf("defaults = %d %d %d", def.workerId, def.datacenterId, def.epoch)
+	}
+
+	for _, tag := range []string{
+		"worker",
+		"worker:x",
+		"worker:1,worker:2",
+		"node:1",
+		"epoch:2021/01/01",
+		"worker:32",
+	} {
+		if sf, err := NewFromTag(tag); err == nil {
+			sf.Close()
+			t.Errorf("NewFromTag(%q) expected error", tag)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var ErrTimeBoxExpired = errors.New("snowflake generator time box expired")
+
+// NewTimeBoxed 与 New 相同，但创建duration之后生成id都返回 ErrTimeBoxExpired，
+// 用于只在固定时间段内生成id的批处理任务。时间按生成器的时间源计算，精确到毫秒。
+func NewTimeBoxed(workerID, datacenterID int64, duration time.Duration, opts ...Option) (*Snowflake, error) {
+	if duration <= 0 {
+		return nil, fmt.Errorf("time box duration must be positive")
+	}
+	s, err := New(workerID, datacenterID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	s.timeBoxUntil = s.clock.Now().Add(duration).UnixMilli()
+	return s, nil
+}
+
+// TimeRemaining NewTimeBoxed 创建的生成器还能生成id的时间，到期后为0。
+// 其它生成器没有时间限制，返回最大的 time.Duration。
+func (s *Snowflake) TimeRemaining() time.Duration {
+	if s.timeBoxUntil == 0 {
+		return math.MaxInt64
+	}
+	if d := time.UnixMilli(s.timeBoxUntil).Sub(s.clock.Now()); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// timeBoxExpired 在timestamp时是否已超出时间限制
+func (s *Snowflake) timeBoxExpired(timestamp int64) bool {
+	return s.timeBoxUntil != 0 && timestamp >= s.timeBoxUntil
+}
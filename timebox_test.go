@@ -0,0 +1,39 @@
+package snowflake
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewTimeBoxed(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, err := NewTimeBoxed(1, 1, time.Minute, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+
+	if _, err := sf.NextId(); err != nil {
+		t.Fatal(err)
+	}
+	clock.Add(59 * time.Second)
+	if d := sf.TimeRemaining(); d != time.Second {
+		t.Errorf("TimeRemaining = %v, want 1s", d)
+	}
+	clock.Add(999 * time.Millisecond)
+	if _, err := sf.NextId(); err != nil {
+		t.Fatalf("NextId just before cutoff: %v", err)
+	}
+	clock.Add(time.Millisecond)
+	if _, err := sf.NextId(); !errors.Is(err, ErrTimeBoxExpired) {
+		t.Errorf("NextId after cutoff = %v, want ErrTimeBoxExpired", err)
+	}
+	if d := sf.TimeRemaining(); d != 0 {
+		t.Errorf("TimeRemaining after cutoff = %v, want 0", d)
+	}
+
+	if _, err := NewTimeBoxed(2, 1, 0); err == nil {
+		t.Error("zero duration expected error")
+	}
+}
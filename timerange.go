@@ -0,0 +1,53 @@
+package snowflake
+
+import "time"
+
+// FirstIdForTime 按默认的位分布和起始时间，t所在毫秒内可能生成的最小id，
+// 与 LastIdForTime 一起用于按时间范围扫描以id为主键的表，例如
+//
+//	WHERE id BETWEEN FirstIdForTime(t1) AND LastIdForTime(t2)
+//
+// t早于起始时间时返回0，晚于时间戳用尽的时间时按最后一毫秒计算。
+func FirstIdForTime(t time.Time) int64 {
+	return firstIdForTime(t.UnixMilli()-twepoch, timestampLeftShift, maxTimestamp)
+}
+
+// LastIdForTime 按默认的位分布和起始时间，t所在毫秒内可能生成的最大id，参见 FirstIdForTime。
+// t早于起始时间时返回-1，即没有id不大于它。
+func LastIdForTime(t time.Time) int64 {
+	return lastIdForTime(t.UnixMilli()-twepoch, timestampLeftShift, maxTimestamp)
+}
+
+// FirstIdForTime 按生成器的起始时间和位分布计算的 FirstIdForTime
+func (s *Snowflake) FirstIdForTime(t time.Time) int64 {
+	return firstIdForTime((t.UnixMilli()-s.epoch)/s.layout.unit(), s.timestampShift, s.timestampMax)
+}
+
+// LastIdForTime 按生成器的起始时间和位分布计算的 LastIdForTime
+func (s *Snowflake) LastIdForTime(t time.Time) int64 {
+	elapsed := t.UnixMilli() - s.epoch
+	if elapsed < 0 {
+		return -1
+	}
+	return lastIdForTime(elapsed/s.layout.unit(), s.timestampShift, s.timestampMax)
+}
+
+func firstIdForTime(timestamp int64, shift uint8, max int64) int64 {
+	if timestamp < 0 {
+		return 0
+	}
+	if timestamp > max {
+		timestamp = max
+	}
+	return timestamp << shift
+}
+
+func lastIdForTime(timestamp int64, shift uint8, max int64) int64 {
+	if timestamp < 0 {
+		return -1
+	}
+	if timestamp > max {
+		timestamp = max
+	}
+	return timestamp<<shift | (1<<shift - 1)
+}
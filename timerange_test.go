@@ -0,0 +1,60 @@
+package snowflake
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestIdForTime(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 5000))
+	sf, err := NewUnregistered(31, 31, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := sf.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.UnixMilli(twepoch + 5000)
+	first, last := FirstIdForTime(at), LastIdForTime(at)
+	if id < first || id > last {
+		t.Errorf("id %d isn't in [%d, %d]", id, first, last)
+	}
+	if first != 5000<<timestampLeftShift || last != 5001<<timestampLeftShift-1 {
+		t.Errorf("range = [%d, %d]", first, last)
+	}
+	if FirstIdForTime(at.Add(time.Millisecond)) != last+1 {
+		t.Error("ranges of adjacent milliseconds aren't contiguous")
+	}
+	if sf.FirstIdForTime(at) != first || sf.LastIdForTime(at) != last {
+		t.Error("Snowflake methods don't match the default layout")
+	}
+
+	before := time.UnixMilli(twepoch - 1)
+	if FirstIdForTime(before) != 0 || LastIdForTime(before) != -1 {
+		t.Errorf("before epoch: [%d, %d]", FirstIdForTime(before), LastIdForTime(before))
+	}
+	if got := LastIdForTime(time.UnixMilli(twepoch).Add(100 * 365 * 24 * time.Hour)); got != math.MaxInt64 {
+		t.Errorf("after expiry LastIdForTime = %d, want MaxInt64", got)
+	}
+
+	// 自定义的位分布和时间单位
+	sony, err := NewSonyflakeLayout(7, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sony.Close()
+	id, err = sony.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ts := range []time.Time{at, at.Add(9 * time.Millisecond)} {
+		if id < sony.FirstIdForTime(ts) || id > sony.LastIdForTime(ts) {
+			t.Errorf("sonyflake layout id %d isn't in the range for %v", id, ts)
+		}
+	}
+	if sony.FirstIdForTime(at.Add(10*time.Millisecond)) <= id {
+		t.Error("next 10ms range should start after the id")
+	}
+}
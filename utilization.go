@@ -0,0 +1,30 @@
+package snowflake
+
+// SequenceCount 最近一次生成id的毫秒内已经使用的序列个数，还没有生成过id时为0
+func (s *Snowflake) SequenceCount() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sequenceCount()
+}
+
+// SequenceUtilization 最近一次生成id的毫秒内序列的使用率，即当前序列/序列最大值，取值为0到1，
+// 接近1时说明该节点的吞吐接近每毫秒的上限
+func (s *Snowflake) SequenceUtilization() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sequenceCount() == 0 {
+		return 0
+	}
+	if s.maxSequence == 0 {
+		return 1
+	}
+	return float64(s.sequence) / float64(s.maxSequence)
+}
+
+// sequenceCount 调用方需持有锁
+func (s *Snowflake) sequenceCount() int64 {
+	if s.lastTimestamp == 0 || s.sequence < 0 {
+		return 0
+	}
+	return s.sequence + 1
+}
@@ -0,0 +1,53 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSequenceUtilization(t *testing.T) {
+	clock := newFakeClock(time.UnixMilli(twepoch + 1000))
+	sf, err := NewUnregistered(1, 1, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sf.SequenceCount() != 0 || sf.SequenceUtilization() != 0 {
+		t.Errorf("before generation: %d %f", sf.SequenceCount(), sf.SequenceUtilization())
+	}
+
+	sf.NextId()
+	if sf.SequenceCount() != 1 || sf.SequenceUtilization() != 0 {
+		t.Errorf("after 1 id: %d %f", sf.SequenceCount(), sf.SequenceUtilization())
+	}
+	for i := 1; i <= sequenceMask/2; i++ {
+		sf.NextId()
+	}
+	if got := sf.SequenceCount(); got != sequenceMask/2+1 {
+		t.Errorf("SequenceCount() = %d, want %d", got, sequenceMask/2+1)
+	}
+	if got := sf.SequenceUtilization(); got < 0.49 || got > 0.51 {
+		t.Errorf("SequenceUtilization() = %f, want 0.5", got)
+	}
+	for sf.SequenceCount() < sequenceMask+1 {
+		sf.NextId()
+	}
+	if got := sf.SequenceUtilization(); got != 1 {
+		t.Errorf("SequenceUtilization() = %f, want 1", got)
+	}
+
+	// 下一毫秒重新开始
+	clock.Add(time.Millisecond)
+	sf.NextId()
+	if sf.SequenceCount() != 1 || sf.SequenceUtilization() != 0 {
+		t.Errorf("next millisecond: %d %f", sf.SequenceCount(), sf.SequenceUtilization())
+	}
+
+	limited, err := NewUnregistered(1, 1, WithClock(clock), WithMaxSequence(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	limited.NextId()
+	if limited.SequenceUtilization() != 1 {
+		t.Errorf("WithMaxSequence(0) utilization = %f, want 1", limited.SequenceUtilization())
+	}
+}
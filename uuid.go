@@ -0,0 +1,105 @@
+package snowflake
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+var ErrNotSnowflakeUUID = errors.New("uuid is not a snowflake uuid v8")
+
+// ToUUIDv8 将id嵌入RFC 9562的UUID版本8（自定义格式）。
+// 按RFC的规定，版本号位于第6字节的高4位，变体0b10位于第8字节的高2位；
+// id的63位按大端序依次放在其余的位中：第0-5字节、第6字节低4位、第7字节、第8字节的第5-3位，剩余的位为0。
+// 按字节比较UUID的大小顺序与id一致。
+func (id ID) ToUUIDv8() [16]byte {
+	var u [16]byte
+	v := uint64(id) << 1 // 去掉符号位，id的最高位对齐到第63位
+	binary.BigEndian.PutUint16(u[0:2], uint16(v>>48))
+	binary.BigEndian.PutUint32(u[2:6], uint32(v>>16))
+	u[6] = 0x80 | byte(v>>12)&0x0f
+	u[7] = byte(v >> 4)
+	u[8] = 0x80 | byte(v>>1)&0x07<<3
+	return u
+}
+
+// ParseUUIDv8 从 ToUUIDv8 生成的UUID中取出id，版本号、变体或填充位不符合时返回 ErrNotSnowflakeUUID
+func ParseUUIDv8(uuid [16]byte) (ID, error) {
+	if uuid[6]&0xf0 != 0x80 || uuid[8]&0xc0 != 0x80 || uuid[8]&0x07 != 0 {
+		return 0, ErrNotSnowflakeUUID
+	}
+	for _, b := range uuid[9:] {
+		if b != 0 {
+			return 0, ErrNotSnowflakeUUID
+		}
+	}
+	v := uint64(binary.BigEndian.Uint16(uuid[0:2]))<<48 |
+		uint64(binary.BigEndian.Uint32(uuid[2:6]))<<16 |
+		uint64(uuid[6]&0x0f)<<12 |
+		uint64(uuid[7])<<4 |
+		uint64(uuid[8]>>3&0x07)<<1
+	return ID(v >> 1), nil
+}
+
+// FormatUUID 按标准的8-4-4-4-12格式输出UUID
+func FormatUUID(uuid [16]byte) string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], uuid[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], uuid[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], uuid[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], uuid[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], uuid[10:])
+	return string(buf[:])
+}
+
+// FormatGUID 按不带连字符的32位十六进制格式输出UUID，用于按GUID存储的字段
+func FormatGUID(uuid [16]byte) string {
+	return hex.EncodeToString(uuid[:])
+}
+
+// ParseUUIDString 解析 FormatUUID 或 FormatGUID 格式的UUID，不区分大小写，也接受外层的花括号，
+// 例如 {ffffffff-ffff-8fff-b800-000000000000}
+func ParseUUIDString(s string) ([16]byte, error) {
+	var u [16]byte
+	if len(s) == 38 && s[0] == '{' && s[37] == '}' {
+		s = s[1:37]
+	}
+	switch len(s) {
+	case 32:
+	case 36:
+		if s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+			return u, fmt.Errorf("invalid uuid %q", s)
+		}
+		s = s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	default:
+		return u, fmt.Errorf("invalid uuid %q: wrong length", s)
+	}
+	if _, err := hex.Decode(u[:], []byte(s)); err != nil {
+		return u, fmt.Errorf("invalid uuid %q: %w", s, err)
+	}
+	return u, nil
+}
+
+// GUID 将id按 ToUUIDv8 嵌入UUID后以 FormatGUID 格式输出，字符串的字典序与id的大小顺序一致
+func (id ID) GUID() string {
+	return FormatGUID(id.ToUUIDv8())
+}
+
+// UUIDString 将id按 ToUUIDv8 嵌入UUID后以 FormatUUID 格式输出，字符串的字典序与id的大小顺序一致
+func (id ID) UUIDString() string {
+	return FormatUUID(id.ToUUIDv8())
+}
+
+// ParseGUID 从 GUID 或 UUIDString 的输出中取出id
+func ParseGUID(s string) (ID, error) {
+	u, err := ParseUUIDString(s)
+	if err != nil {
+		return 0, err
+	}
+	return ParseUUIDv8(u)
+}
@@ -0,0 +1,104 @@
+package snowflake
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestUUIDv8(t *testing.T) {
+	sf, err := NewUnregistered(3, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	format := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-8[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	ids := []ID{0, 1, 7, 8, 0x7ff, math.MaxInt64, 0x5555555555555555}
+	for i := 0; i < 1000; i++ {
+		id, err := sf.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, ID(id))
+	}
+	for _, id := range ids {
+		u := id.ToUUIDv8()
+		got, err := ParseUUIDv8(u)
+		if err != nil {
+			t.Fatalf("ParseUUIDv8(%s): %v", FormatUUID(u), err)
+		}
+		if got != id {
+			t.Fatalf("round trip of %d = %d", id, got)
+		}
+		if s := FormatUUID(u); !format.MatchString(s) {
+			t.Fatalf("FormatUUID = %q is not a version 8 uuid", s)
+		}
+	}
+
+	// 保持大小顺序
+	for i := 1; i < len(ids); i++ {
+		a, b := ids[i-1].ToUUIDv8(), ids[i].ToUUIDv8()
+		if (ids[i-1] < ids[i]) != (bytes.Compare(a[:], b[:]) < 0) {
+			t.Fatalf("order of %d and %d is not preserved", ids[i-1], ids[i])
+		}
+	}
+
+	if s := FormatUUID(ID(math.MaxInt64).ToUUIDv8()); s != "ffffffff-ffff-8fff-b800-000000000000" {
+		t.Errorf("FormatUUID(max) = %q", s)
+	}
+
+	u := ID(12345).ToUUIDv8()
+	for _, mutate := range []func(*[16]byte){
+		func(u *[16]byte) { u[6] = u[6]&0x0f | 0x40 }, // 版本4
+		func(u *[16]byte) { u[8] &^= 0x80 },           // 变体错误
+		func(u *[16]byte) { u[15] = 1 },               // 填充位不为0
+	} {
+		bad := u
+		mutate(&bad)
+		if _, err := ParseUUIDv8(bad); !errors.Is(err, ErrNotSnowflakeUUID) {
+			t.Errorf("ParseUUIDv8(%s) = %v, want ErrNotSnowflakeUUID", FormatUUID(bad), err)
+		}
+	}
+}
+
+func TestGUID(t *testing.T) {
+	sf, err := NewUnregistered(3, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var prev string
+	for i := 0; i < 100; i++ {
+		id, err := sf.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		g := ID(id).GUID()
+		if len(g) != 32 {
+			t.Fatalf("GUID = %q, want 32 characters", g)
+		}
+		// 字符串的字典序与id一致
+		if g <= prev {
+			t.Fatalf("GUID %q isn't after %q", g, prev)
+		}
+		prev = g
+		for _, s := range []string{g, ID(id).UUIDString(), strings.ToUpper(g), "{" + ID(id).UUIDString() + "}"} {
+			got, err := ParseGUID(s)
+			if err != nil || got != ID(id) {
+				t.Fatalf("ParseGUID(%q) = %d, %v, want %d", s, got, err, id)
+			}
+		}
+	}
+
+	for _, s := range []string{"", "ffffffff", "ffffffff_ffff-8fff-b800-000000000000", strings.Repeat("g", 32)} {
+		if _, err := ParseUUIDString(s); err == nil {
+			t.Errorf("ParseUUIDString(%q) expected error", s)
+		}
+	}
+	// 合法的UUID但不是雪花id
+	if _, err := ParseGUID("6ba7b810-9dad-41d1-80b4-00c04fd430c8"); !errors.Is(err, ErrNotSnowflakeUUID) {
+		t.Errorf("ParseGUID(v4) = %v, want ErrNotSnowflakeUUID", err)
+	}
+}
@@ -0,0 +1,118 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidID = errors.New("invalid snowflake id")
+
+// 校验失败的字段
+const (
+	FieldSign         = "sign"          // 符号位为1，即生成时间早于起始时间
+	FieldTimestamp    = "timestamp"     // 生成时间在未来
+	FieldDatacenterId = "datacenter_id" // 数据id超出范围
+	FieldWorkerId     = "worker_id"     // 机器id超出范围
+)
+
+// ValidationError id校验失败的原因，可以用 errors.Is(err, ErrInvalidID) 判断，
+// 机器id、数据id超出范围时同时匹配 ErrInvalidWorkerID、ErrInvalidDatacenterID
+type ValidationError struct {
+	ID     int64
+	Field  string // 校验失败的字段，如 FieldTimestamp
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid snowflake id %d: %s", e.ID, e.Reason)
+}
+
+func (e *ValidationError) Unwrap() []error {
+	switch e.Field {
+	case FieldWorkerId:
+		return []error{ErrInvalidID, ErrInvalidWorkerID}
+	case FieldDatacenterId:
+		return []error{ErrInvalidID, ErrInvalidDatacenterID}
+	}
+	return []error{ErrInvalidID}
+}
+
+// Validator 校验客户端传入的id，零值按默认的位分布和起始时间校验，不允许生成时间在未来
+type Validator struct {
+	Epoch           time.Time     // 起始时间，零值使用默认起始时间
+	Layout          BitLayout     // 位分布，零值使用 DefaultLayout
+	MaxFutureDrift  time.Duration // 允许生成时间晚于当前时间多久，用于容忍节点间的时钟误差
+	DatacenterCount int64         // 部署的数据中心数，数据id必须小于该值，0表示不限制
+	WorkerCount     int64         // 每个数据中心的机器数，机器id必须小于该值，0表示不限制
+}
+
+// Validate 按默认配置校验id，失败时返回 *ValidationError
+func Validate(id int64) error {
+	return Validator{}.Validate(id)
+}
+
+// Validate 依次校验符号位、生成时间、数据id和机器id，返回第一个不合法字段的 *ValidationError。
+// 位分布不合法时返回普通错误
+func (v Validator) Validate(id int64) error {
+	layout, epoch := v.Layout, int64(twepoch)
+	if layout == (BitLayout{}) {
+		layout = DefaultLayout
+	} else if err := layout.validate(); err != nil {
+		return err
+	}
+	if !v.Epoch.IsZero() {
+		epoch = v.Epoch.UnixMilli()
+	}
+	if id < 0 {
+		return &ValidationError{ID: id, Field: FieldSign, Reason: "sign bit is set"}
+	}
+	p := parseLayout(ID(id), epoch, layout)
+	if limit := timeGen() + v.MaxFutureDrift.Milliseconds(); p.timestamp > limit {
+		return &ValidationError{ID: id, Field: FieldTimestamp,
+			Reason: fmt.Sprintf("timestamp %v is in the future", p.Time())}
+	}
+	if v.DatacenterCount > 0 && p.datacenterId >= v.DatacenterCount {
+		return &ValidationError{ID: id, Field: FieldDatacenterId,
+			Reason: fmt.Sprintf("datacenter id %d is out of range [0, %d)", p.datacenterId, v.DatacenterCount)}
+	}
+	if v.WorkerCount > 0 && p.workerId >= v.WorkerCount {
+		return &ValidationError{ID: id, Field: FieldWorkerId,
+			Reason: fmt.Sprintf("worker id %d is out of range [0, %d)", p.workerId, v.WorkerCount)}
+	}
+	return nil
+}
+
+// BulkValidate 将ids按是否有效分成两组，各组保持输入的顺序。
+// 有效的id不能为负数，按epoch解析出的生成时间不能晚于当前时间maxFutureDrift以上。
+// 两组共用一个新分配的数组，不会修改ids，可以并发调用；valid的容量等于长度，append不会覆盖invalid。
+// ids为空时两组都为nil。
+func BulkValidate(ids []int64, epoch time.Time, maxFutureDrift time.Duration) (valid, invalid []int64) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	limit := (timeGen() + maxFutureDrift.Milliseconds() - epoch.UnixMilli()) << timestampLeftShift
+	buf := make([]int64, len(ids))
+	nv, ni := 0, len(buf)
+	for _, id := range ids {
+		if id >= 0 && (id>>timestampLeftShift)<<timestampLeftShift <= limit {
+			buf[nv] = id
+			nv++
+		} else {
+			ni--
+			buf[ni] = id
+		}
+	}
+	// 无效的id是从后往前写入的，翻转恢复输入的顺序
+	invalid = buf[nv:]
+	for i, j := 0, len(invalid)-1; i < j; i, j = i+1, j-1 {
+		invalid[i], invalid[j] = invalid[j], invalid[i]
+	}
+	if len(invalid) == 0 {
+		invalid = nil
+	}
+	if nv == 0 {
+		return nil, invalid
+	}
+	return buf[:nv:nv], invalid
+}
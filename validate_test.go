@@ -0,0 +1,116 @@
+package snowflake
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBulkValidate(t *testing.T) {
+	epoch := time.UnixMilli(twepoch)
+	now := timeGen() - twepoch
+	at := func(ms int64) int64 { return ms<<timestampLeftShift | 7 }
+
+	ids := []int64{
+		at(0),
+		-1,
+		at(now - 1000),
+		at(now + 60000), // 超出允许的时间偏差
+		at(now + 500),   // 在允许的时间偏差内
+		at(now + 120000),
+	}
+	input := append([]int64(nil), ids...)
+	valid, invalid := BulkValidate(ids, epoch, time.Second)
+	if want := []int64{at(0), at(now - 1000), at(now + 500)}; !reflect.DeepEqual(valid, want) {
+		t.Errorf("valid = %v, want %v", valid, want)
+	}
+	if want := []int64{-1, at(now + 60000), at(now + 120000)}; !reflect.DeepEqual(invalid, want) {
+		t.Errorf("invalid = %v, want %v", invalid, want)
+	}
+	if !reflect.DeepEqual(ids, input) {
+		t.Error("input was modified")
+	}
+
+	// 向valid追加不会影响invalid
+	_ = append(valid, 1)
+	if invalid[0] != -1 {
+		t.Error("append to valid overwrote invalid")
+	}
+
+	for _, in := range [][]int64{nil, {}} {
+		if v, i := BulkValidate(in, epoch, 0); v != nil || i != nil {
+			t.Errorf("BulkValidate(%v) = %v, %v", in, v, i)
+		}
+	}
+	if v, i := BulkValidate([]int64{-5}, epoch, 0); v != nil || len(i) != 1 {
+		t.Errorf("all invalid = %v, %v", v, i)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	sf, _ := NewUnregistered(7, 3)
+	id, _ := sf.NextId()
+	if err := Validate(id); err != nil {
+		t.Errorf("Validate(%d) = %v", id, err)
+	}
+	future := (timeGen() + time.Hour.Milliseconds() - twepoch) << timestampLeftShift
+
+	tests := []struct {
+		name  string
+		v     Validator
+		id    int64
+		field string
+	}{
+		{"negative", Validator{}, -id, FieldSign},
+		{"future", Validator{}, future, FieldTimestamp},
+		{"datacenter", Validator{DatacenterCount: 3}, id, FieldDatacenterId},
+		{"worker", Validator{DatacenterCount: 4, WorkerCount: 7}, id, FieldWorkerId},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.v.Validate(tt.id)
+			var ve *ValidationError
+			if !errors.As(err, &ve) || ve.Field != tt.field || ve.ID != tt.id {
+				t.Fatalf("Validate(%d) = %v, want %s error", tt.id, err, tt.field)
+			}
+			if !errors.Is(err, ErrInvalidID) {
+				t.Errorf("%v isn't ErrInvalidID", err)
+			}
+		})
+	}
+
+	if err := (Validator{WorkerCount: 7}).Validate(id); !errors.Is(err, ErrInvalidWorkerID) {
+		t.Errorf("%v isn't ErrInvalidWorkerID", err)
+	}
+	if err := (Validator{DatacenterCount: 3}).Validate(id); !errors.Is(err, ErrInvalidDatacenterID) || errors.Is(err, ErrInvalidWorkerID) {
+		t.Errorf("%v should only be ErrInvalidDatacenterID", err)
+	}
+	if err := (Validator{MaxFutureDrift: 2 * time.Hour}).Validate(future); err != nil {
+		t.Errorf("drift within MaxFutureDrift: %v", err)
+	}
+	if err := (Validator{DatacenterCount: 4, WorkerCount: 8}).Validate(id); err != nil {
+		t.Errorf("node within range: %v", err)
+	}
+}
+
+func TestValidator_Layout(t *testing.T) {
+	epoch := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	sf, err := NewSonyflakeLayout(300, WithEpoch(epoch))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _ := sf.NextId()
+	v := Validator{Epoch: epoch, Layout: SonyflakeLayout, WorkerCount: 256}
+	var ve *ValidationError
+	if err := v.Validate(id); !errors.As(err, &ve) || ve.Field != FieldWorkerId {
+		t.Errorf("Validate = %v, want worker id error", err)
+	}
+	v.WorkerCount = 301
+	if err := v.Validate(id); err != nil {
+		t.Errorf("Validate = %v", err)
+	}
+	if err := (Validator{Layout: BitLayout{TimestampBits: 1}}).Validate(id); err == nil || errors.Is(err, ErrInvalidID) {
+		t.Errorf("invalid layout: %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package snowflake
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// walRecordSize 每条记录的字节数，即8字节大端序的id
+const walRecordSize = 8
+
+// WALLogger 生成id的同时把id追加写入预写日志，日志格式与 WriteTo 相同
+type WALLogger struct {
+	mu    sync.Mutex
+	s     *Snowflake
+	w     io.Writer
+	level int           // zstd压缩级别，0表示不压缩
+	enc   *zstd.Encoder // 压缩时写入w的编码器
+}
+
+// WALOption 创建 WALLogger 时的可选配置
+type WALOption func(*WALLogger) error
+
+// WithWALCompression 以zstd压缩日志，level为1（最快）到11（压缩率最高）。
+// 压缩后的数据在编码器中缓冲，NextId 返回时id不一定已经写入w，需要定期调用 Flush，
+// 不再使用时调用 Close。读取时用 zstd.NewReader 解压后交给 NewDecoder 或 ReadWAL。
+func WithWALCompression(level int) WALOption {
+	return func(l *WALLogger) error {
+		if level < 1 || level > 11 {
+			return fmt.Errorf("wal compression level must be between 1 and 11")
+		}
+		l.level = level
+		return nil
+	}
+}
+
+// NewWALLogger 创建把s生成的id写入w的日志
+func NewWALLogger(s *Snowflake, w io.Writer, opts ...WALOption) (*WALLogger, error) {
+	l := &WALLogger{s: s, w: w}
+	for _, opt := range opts {
+		if err := opt(l); err != nil {
+			return nil, err
+		}
+	}
+	if l.level != 0 {
+		enc, err := zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(l.level)))
+		if err != nil {
+			return nil, err
+		}
+		l.enc = enc
+		l.w = enc
+	}
+	return l, nil
+}
+
+// NextId 生成id并写入日志，写入失败时返回错误，id视为未生成。日志中id的顺序与生成顺序一致。
+func (l *WALLogger) NextId() (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	id, err := l.s.NextId()
+	if err != nil {
+		return 0, err
+	}
+	var buf [walRecordSize]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(id))
+	if _, err := l.w.Write(buf[:]); err != nil {
+		return 0, fmt.Errorf("write wal: %w", err)
+	}
+	return id, nil
+}
+
+// Flush 压缩时把已生成的id写入底层的w，不压缩时什么也不做
+func (l *WALLogger) Flush() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.enc == nil {
+		return nil
+	}
+	return l.enc.Flush()
+}
+
+// Close 压缩时写出剩余的数据并结束zstd帧，不会关闭底层的w
+func (l *WALLogger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.enc == nil {
+		return nil
+	}
+	return l.enc.Close()
+}
+
+// ErrTruncatedWAL 日志末尾有不完整的记录，通常是写入时进程退出导致的
+type ErrTruncatedWAL struct {
+	Count int // 成功读取的id个数
+}
+
+func (e *ErrTruncatedWAL) Error() string {
+	return fmt.Sprintf("truncated wal after %d ids", e.Count)
+}
+
+// ReadWAL 读取日志中的所有id并按epoch解析。
+// 末尾有不完整的记录时，返回已读取的id和 *ErrTruncatedWAL。
+func ReadWAL(r io.Reader, epoch time.Time) ([]ParsedID, error) {
+	var ids []ParsedID
+	err := WalkWAL(r, epoch, func(p ParsedID) bool {
+		ids = append(ids, p)
+		return true
+	})
+	return ids, err
+}
+
+// WalkWAL 依次读取日志中的id并按epoch解析后调用fn，fn返回false时停止。
+// 末尾有不完整的记录时，返回 *ErrTruncatedWAL。
+func WalkWAL(r io.Reader, epoch time.Time, fn func(ParsedID) bool) error {
+	br := bufio.NewReader(r)
+	var buf [walRecordSize]byte
+	for count := 0; ; count++ {
+		_, err := io.ReadFull(br, buf[:])
+		if err == io.EOF {
+			return nil
+		}
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return &ErrTruncatedWAL{Count: count}
+		}
+		if err != nil {
+			return err
+		}
+		if !fn(parse(ID(binary.BigEndian.Uint64(buf[:])), epoch.UnixMilli())) {
+			return nil
+		}
+	}
+}
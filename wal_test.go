@@ -0,0 +1,150 @@
+package snowflake
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestWAL(t *testing.T) {
+	sf, err := NewUnregistered(2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	logger, err := NewWALLogger(sf, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []int64
+	for i := 0; i < 100; i++ {
+		id, err := logger.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, id)
+	}
+
+	epoch := time.UnixMilli(twepoch)
+	ids, err := ReadWAL(bytes.NewReader(buf.Bytes()), epoch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != len(want) {
+		t.Fatalf("read %d ids, want %d", len(ids), len(want))
+	}
+	for i, p := range ids {
+		if p != ID(want[i]).Parse() {
+			t.Fatalf("id %d = %+v, want %+v", i, p, ID(want[i]).Parse())
+		}
+	}
+
+	// 提前停止
+	n := 0
+	err = WalkWAL(bytes.NewReader(buf.Bytes()), epoch, func(ParsedID) bool {
+		n++
+		return n < 10
+	})
+	if err != nil || n != 10 {
+		t.Errorf("WalkWAL stopped after %d ids, %v", n, err)
+	}
+
+	// 末尾不完整的记录
+	truncated := buf.Bytes()[:buf.Len()-3]
+	ids, err = ReadWAL(bytes.NewReader(truncated), epoch)
+	var te *ErrTruncatedWAL
+	if !errors.As(err, &te) || te.Count != 99 {
+		t.Fatalf("ReadWAL(truncated) = %v, want ErrTruncatedWAL after 99 ids", err)
+	}
+	if len(ids) != 99 {
+		t.Errorf("ReadWAL(truncated) returned %d ids, want 99", len(ids))
+	}
+
+	if ids, err := ReadWAL(bytes.NewReader(nil), epoch); err != nil || len(ids) != 0 {
+		t.Errorf("ReadWAL(empty) = %d ids, %v", len(ids), err)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestWALLogger_WriteError(t *testing.T) {
+	sf, err := NewUnregistered(2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := NewWALLogger(sf, failingWriter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := logger.NextId(); err == nil {
+		t.Error("expected write error")
+	}
+}
+
+func TestWithWALCompression(t *testing.T) {
+	sf, err := NewUnregistered(2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const n = 100000
+	var plain, compressed bytes.Buffer
+	plainLogger, err := NewWALLogger(sf, &plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := NewWALLogger(sf, &compressed, WithWALCompression(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []int64
+	for i := 0; i < n; i++ {
+		id, err := logger.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, id)
+		if _, err := plainLogger.NextId(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("%d ids: %d bytes uncompressed, %d bytes compressed", n, plain.Len(), compressed.Len())
+	if compressed.Len() >= plain.Len() {
+		t.Errorf("compressed wal is %d bytes, uncompressed %d", compressed.Len(), plain.Len())
+	}
+
+	zr, err := zstd.NewReader(&compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec := NewDecoder(zr.IOReadCloser(), time.UnixMilli(twepoch))
+	defer dec.Close()
+	for i, id := range want {
+		p, err := dec.Decode()
+		if err != nil {
+			t.Fatalf("id %d: %v", i, err)
+		}
+		if int64(p.ID()) != id {
+			t.Fatalf("id %d = %d, want %d", i, p.ID(), id)
+		}
+	}
+	if _, err := dec.Decode(); err != io.EOF {
+		t.Errorf("Decode after last id = %v, want io.EOF", err)
+	}
+
+	for _, level := range []int{0, 12} {
+		if _, err := NewWALLogger(sf, &plain, WithWALCompression(level)); err == nil {
+			t.Errorf("WithWALCompression(%d) expected error", level)
+		}
+	}
+}
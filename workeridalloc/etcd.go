@@ -0,0 +1,83 @@
+package workeridalloc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// EtcdClient 租用机器id用到的 etcd 操作，可以用 clientv3 包装实现
+type EtcdClient interface {
+	// Grant 创建有效期为ttl秒的lease
+	Grant(ctx context.Context, ttl int64) (leaseID int64, err error)
+	// PutIfAbsent key不存在时写入value并绑定lease，即
+	// Txn(If(CreateRevision(key) = 0), Then(OpPut(key, value, WithLease(leaseID))))，返回是否写入
+	PutIfAbsent(ctx context.Context, key, value string, leaseID int64) (bool, error)
+	// KeepAliveOnce 续期lease一次
+	KeepAliveOnce(ctx context.Context, leaseID int64) error
+	// Revoke 撤销lease，绑定的key随之删除
+	Revoke(ctx context.Context, leaseID int64) error
+}
+
+// EtcdStore 基于 etcd 的租约存储：每个机器id绑定一个lease，续期即续期lease，释放即撤销lease
+type EtcdStore struct {
+	client EtcdClient
+
+	mu     sync.Mutex
+	leases map[string]int64 // key -> leaseID
+}
+
+// NewEtcdStore 创建基于 etcd 的租约存储
+func NewEtcdStore(client EtcdClient) *EtcdStore {
+	return &EtcdStore{client: client, leases: make(map[string]int64)}
+}
+
+func (e *EtcdStore) Acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	seconds := int64((ttl + time.Second - 1) / time.Second) // etcd的lease以秒为单位，向上取整
+	leaseID, err := e.client.Grant(ctx, seconds)
+	if err != nil {
+		return false, fmt.Errorf("grant lease: %w", err)
+	}
+	ok, err := e.client.PutIfAbsent(ctx, key, owner, leaseID)
+	if err != nil || !ok {
+		e.client.Revoke(ctx, leaseID)
+		return false, err
+	}
+	e.mu.Lock()
+	e.leases[key] = leaseID
+	e.mu.Unlock()
+	return true, nil
+}
+
+func (e *EtcdStore) Renew(ctx context.Context, key, owner string, ttl time.Duration) error {
+	leaseID, ok := e.lease(key)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotOwner, key)
+	}
+	if err := e.client.KeepAliveOnce(ctx, leaseID); err != nil {
+		return fmt.Errorf("renew %s: %w", key, err)
+	}
+	return nil
+}
+
+func (e *EtcdStore) Release(ctx context.Context, key, owner string) error {
+	leaseID, ok := e.lease(key)
+	if !ok {
+		return nil
+	}
+	e.mu.Lock()
+	delete(e.leases, key)
+	e.mu.Unlock()
+	if err := e.client.Revoke(ctx, leaseID); err != nil {
+		return fmt.Errorf("release %s: %w", key, err)
+	}
+	return nil
+}
+
+func (e *EtcdStore) lease(key string) (int64, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	leaseID, ok := e.leases[key]
+	return leaseID, ok
+}
@@ -0,0 +1,52 @@
+package workeridalloc
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RedisClient 租用机器id用到的 Redis 命令，可以用任意 Redis 客户端包装实现
+type RedisClient interface {
+	// SetNX 即 SET key value NX PX ttl
+	SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error)
+	// Eval 执行Lua脚本，返回脚本的整数结果
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (int64, error)
+}
+
+const (
+	redisRenewScript   = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("PEXPIRE", KEYS[1], ARGV[2]) else return 0 end`
+	redisReleaseScript = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) else return 0 end`
+)
+
+// RedisStore 基于 Redis 的租约存储：SET NX PX 占用，Lua脚本检查持有者后续期和释放
+type RedisStore struct {
+	client RedisClient
+}
+
+// NewRedisStore 创建基于 Redis 的租约存储
+func NewRedisStore(client RedisClient) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func (r *RedisStore) Acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	return r.client.SetNX(ctx, key, owner, ttl)
+}
+
+func (r *RedisStore) Renew(ctx context.Context, key, owner string, ttl time.Duration) error {
+	n, err := r.client.Eval(ctx, redisRenewScript, []string{key}, owner, ttl.Milliseconds())
+	if err != nil {
+		return fmt.Errorf("renew %s: %w", key, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: %s", ErrNotOwner, key)
+	}
+	return nil
+}
+
+func (r *RedisStore) Release(ctx context.Context, key, owner string) error {
+	if _, err := r.client.Eval(ctx, redisReleaseScript, []string{key}, owner); err != nil {
+		return fmt.Errorf("release %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+// Package workeridalloc 从 etcd 或 Redis 租用唯一的机器id，适用于Kubernetes等实例频繁扩缩容的部署
+package workeridalloc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pangush/snowflake"
+)
+
+var (
+	ErrNoFreeWorker = errors.New("no free snowflake worker id to lease")
+	ErrNotOwner     = errors.New("snowflake worker id lease is held by another owner")
+)
+
+// Store 保存机器id租约的存储，RedisStore、EtcdStore 分别基于 Redis 和 etcd 实现
+type Store interface {
+	// Acquire key不存在时写入owner并设置有效期ttl，返回是否成功
+	Acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+	// Renew key仍属于owner时把有效期延长为ttl，否则返回 ErrNotOwner
+	Renew(ctx context.Context, key, owner string, ttl time.Duration) error
+	// Release key属于owner时将其删除
+	Release(ctx context.Context, key, owner string) error
+}
+
+// Generator 租用到机器id的生成器
+type Generator struct {
+	*snowflake.Snowflake
+
+	store  Store
+	key    string
+	owner  string
+	ttl    time.Duration
+	cancel context.CancelFunc
+	once   sync.Once
+}
+
+// New 在store中为datacenterID租用一个未被占用的机器id(0-31)并创建生成器，
+// 租约的key为 <prefix>/<datacenterID>/<workerID>，有效期为ttl。
+// 租约在后台每隔ttl的四分之一续期一次，连续3次续期失败时租约还没有过期，生成器即进入降级状态，
+// 之后生成id都返回 snowflake.ErrLeaseLost，可以用 IsDegraded 检查；进程异常退出后租约在ttl后自动释放。
+// 不再使用时调用 Close 停止续期并释放机器id。
+func New(ctx context.Context, store Store, prefix string, datacenterID int64, ttl time.Duration, opts ...snowflake.Option) (*Generator, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("lease ttl must be positive")
+	}
+	owner, err := newOwner()
+	if err != nil {
+		return nil, err
+	}
+
+	for workerID := int64(0); workerID < snowflake.MaxWorkersPerDatacenter; workerID++ {
+		key := fmt.Sprintf("%s/%d/%d", prefix, datacenterID, workerID)
+		ok, err := store.Acquire(ctx, key, owner, ttl)
+		if err != nil {
+			return nil, fmt.Errorf("acquire worker id %d: %w", workerID, err)
+		}
+		if !ok {
+			continue
+		}
+
+		// StartLeaseRenewal 每隔 WithLeaseTTL 的一半续期
+		opts = append(opts[:len(opts):len(opts)], snowflake.WithLeaseTTL(ttl/2))
+		s, err := snowflake.New(workerID, datacenterID, opts...)
+		if err != nil {
+			store.Release(ctx, key, owner)
+			return nil, err
+		}
+		g := &Generator{Snowflake: s, store: store, key: key, owner: owner, ttl: ttl}
+		renewCtx, cancel := context.WithCancel(context.Background())
+		g.cancel = cancel
+		s.StartLeaseRenewal(renewCtx, g)
+		return g, nil
+	}
+	return nil, ErrNoFreeWorker
+}
+
+// Renew 实现 snowflake.LeaseRenewer
+func (g *Generator) Renew(ctx context.Context) error {
+	return g.store.Renew(ctx, g.key, g.owner, g.ttl)
+}
+
+// Close 停止生成id和续期，然后释放机器id。可以重复调用。
+func (g *Generator) Close() error {
+	var err error
+	g.once.Do(func() {
+		g.Snowflake.Shutdown()
+		g.cancel()
+		err = g.store.Release(context.Background(), g.key, g.owner)
+		g.Snowflake.Close()
+	})
+	return err
+}
+
+// newOwner 标识租约持有者的字符串：主机名加随机数
+func newOwner() (string, error) {
+	host, _ := os.Hostname()
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return host + "-" + hex.EncodeToString(b[:]), nil
+}
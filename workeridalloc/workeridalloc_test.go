@@ -0,0 +1,205 @@
+package workeridalloc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pangush/snowflake"
+)
+
+// fakeRedis 模拟 SET NX PX 和两个Lua脚本，不处理过期
+type fakeRedis struct {
+	mu   sync.Mutex
+	keys map[string]string
+	ttls map[string]int64
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{keys: map[string]string{}, ttls: map[string]int64{}}
+}
+
+func (f *fakeRedis) SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.keys[key]; ok {
+		return false, nil
+	}
+	f.keys[key] = value
+	f.ttls[key] = ttl.Milliseconds()
+	return true, nil
+}
+
+func (f *fakeRedis) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.keys[keys[0]] != args[0] {
+		return 0, nil
+	}
+	switch script {
+	case redisRenewScript:
+		f.ttls[keys[0]] = args[1].(int64)
+	case redisReleaseScript:
+		delete(f.keys, keys[0])
+	}
+	return 1, nil
+}
+
+// steal 模拟租约过期后被其它进程占用
+func (f *fakeRedis) steal(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.keys[key] = "someone-else"
+}
+
+func TestNew_Redis(t *testing.T) {
+	r := newFakeRedis()
+	store := NewRedisStore(r)
+	ctx := context.Background()
+
+	g1, err := New(ctx, store, "snowflake", 2, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g2, err := New(ctx, store, "snowflake", 2, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w1, w2 := g1.Config().WorkerId, g2.Config().WorkerId; w1 != 0 || w2 != 1 {
+		t.Errorf("worker ids = %d %d, want 0 1", w1, w2)
+	}
+	if r.ttls["snowflake/2/0"] != time.Minute.Milliseconds() {
+		t.Errorf("ttl = %d", r.ttls["snowflake/2/0"])
+	}
+	if _, err := g1.NextId(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 释放后机器id可以再次租用
+	if err := g1.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r.keys["snowflake/2/0"]; ok {
+		t.Error("Close didn't release the lease")
+	}
+	g3, err := New(ctx, store, "snowflake", 2, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := g3.Config().WorkerId; w != 0 {
+		t.Errorf("worker id after release = %d, want 0", w)
+	}
+	g2.Close()
+	g3.Close()
+}
+
+func TestNew_NoFreeWorker(t *testing.T) {
+	r := newFakeRedis()
+	for w := 0; w < snowflake.MaxWorkersPerDatacenter; w++ {
+		r.keys[fmt.Sprintf("snowflake/1/%d", w)] = "other"
+	}
+	if _, err := New(context.Background(), NewRedisStore(r), "snowflake", 1, time.Minute); !errors.Is(err, ErrNoFreeWorker) {
+		t.Errorf("err = %v, want ErrNoFreeWorker", err)
+	}
+}
+
+func TestNew_LeaseLost(t *testing.T) {
+	r := newFakeRedis()
+	g, err := New(context.Background(), NewRedisStore(r), "snowflake", 3, 40*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+	r.steal("snowflake/3/0")
+
+	deadline := time.Now().Add(time.Second)
+	for !g.IsDegraded() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !g.IsDegraded() {
+		t.Fatal("generator isn't degraded after losing the lease")
+	}
+	if _, err := g.NextId(); !errors.Is(err, snowflake.ErrLeaseLost) {
+		t.Errorf("NextId err = %v, want ErrLeaseLost", err)
+	}
+}
+
+// fakeEtcd 模拟 lease 和 PutIfAbsent
+type fakeEtcd struct {
+	mu      sync.Mutex
+	next    int64
+	leases  map[int64]bool
+	keys    map[string]int64 // key -> leaseID
+	renewed int
+}
+
+func (f *fakeEtcd) Grant(ctx context.Context, ttl int64) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.next++
+	f.leases[f.next] = true
+	return f.next, nil
+}
+
+func (f *fakeEtcd) PutIfAbsent(ctx context.Context, key, value string, leaseID int64) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.keys[key]; ok {
+		return false, nil
+	}
+	f.keys[key] = leaseID
+	return true, nil
+}
+
+func (f *fakeEtcd) KeepAliveOnce(ctx context.Context, leaseID int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.leases[leaseID] {
+		return fmt.Errorf("lease %d not found", leaseID)
+	}
+	f.renewed++
+	return nil
+}
+
+func (f *fakeEtcd) Revoke(ctx context.Context, leaseID int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.leases, leaseID)
+	for k, id := range f.keys {
+		if id == leaseID {
+			delete(f.keys, k)
+		}
+	}
+	return nil
+}
+
+func TestNew_Etcd(t *testing.T) {
+	e := &fakeEtcd{leases: map[int64]bool{}, keys: map[string]int64{"snowflake/0/0": 99}}
+	g, err := New(context.Background(), NewEtcdStore(e), "snowflake", 0, 40*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := g.Config().WorkerId; w != 1 {
+		t.Errorf("worker id = %d, want 1", w)
+	}
+	// 占用失败时撤销了多余的lease
+	if len(e.leases) != 1 {
+		t.Errorf("%d leases left, want 1", len(e.leases))
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	e.mu.Lock()
+	renewed := e.renewed
+	e.mu.Unlock()
+	if renewed == 0 {
+		t.Error("lease wasn't renewed")
+	}
+
+	g.Close()
+	if _, ok := e.keys["snowflake/0/1"]; ok || len(e.leases) != 0 {
+		t.Error("Close didn't revoke the lease")
+	}
+}
@@ -0,0 +1,26 @@
+package snowflake
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// WriteTo 生成count个id，按8字节大端序依次写入w，用于批量预生成id。
+// 返回写入的字节数，生成或写入出错时立即停止并返回已写入的字节数和错误。
+func (s *Snowflake) WriteTo(w io.Writer, count int64) (int64, error) {
+	var buf [8]byte
+	var written int64
+	for i := int64(0); i < count; i++ {
+		id, err := s.NextId()
+		if err != nil {
+			return written, err
+		}
+		binary.BigEndian.PutUint64(buf[:], uint64(id))
+		n, err := w.Write(buf[:])
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
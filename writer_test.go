@@ -0,0 +1,71 @@
+package snowflake
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func TestSnowflake_WriteTo(t *testing.T) {
+	sf, err := NewUnregistered(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+	var buf bytes.Buffer
+	n, err := sf.WriteTo(&buf, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 800 || buf.Len() != 800 {
+		t.Fatalf("WriteTo wrote %d bytes (buffer %d), want 800", n, buf.Len())
+	}
+	last := int64(0)
+	for i := 0; i < 100; i++ {
+		id := int64(binary.BigEndian.Uint64(buf.Next(8)))
+		if id <= last {
+			t.Fatalf("id %d at %d is not increasing", id, i)
+		}
+		last = id
+	}
+}
+
+var errWriterFull = errors.New("writer full")
+
+// limitedWriter 写入limit字节后返回错误
+type limitedWriter struct {
+	limit int
+	buf   bytes.Buffer
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if room := w.limit - w.buf.Len(); len(p) > room {
+		w.buf.Write(p[:room])
+		return room, errWriterFull
+	}
+	return w.buf.Write(p)
+}
+
+func TestSnowflake_WriteTo_PartialWrite(t *testing.T) {
+	sf, err := NewUnregistered(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+	w := &limitedWriter{limit: 8*3 + 5}
+	n, err := sf.WriteTo(w, 10)
+	if !errors.Is(err, errWriterFull) {
+		t.Fatalf("WriteTo error = %v, want errWriterFull", err)
+	}
+	if n != 29 || w.buf.Len() != 29 {
+		t.Errorf("WriteTo wrote %d bytes (buffer %d), want 29", n, w.buf.Len())
+	}
+
+	// 时钟回退时停止生成
+	sf.lastTimestamp = timeGen() + 60000
+	n, err = sf.WriteTo(&bytes.Buffer{}, 10)
+	if err == nil || n != 0 {
+		t.Errorf("WriteTo with clock skew = %d, %v, want 0 and error", n, err)
+	}
+}